@@ -0,0 +1,107 @@
+package m68kdasm
+
+import (
+	"fmt"
+
+	"github.com/jenska/m68kdasm/internal/decoders"
+)
+
+// CPU selects the processor model whose instruction set is decoded. Opcodes
+// the model does not implement decode as DC.W with DecodeMetadata.UnavailableReason
+// set, or as LINEA/LINEF when the model traps them.
+type CPU int
+
+const (
+	// CPUAny accepts every 680x0 instruction the decoder knows, including the
+	// FPU, PMMU and 68040/68060 additions. CPU32 and ColdFire additions
+	// need their own model. It is the zero value.
+	CPUAny CPU = iota
+	CPU68000
+	CPU68008
+	CPU68010
+	// CPU68020 includes the 68881/68882 FPU and 68851 PMMU coprocessors.
+	CPU68020
+	// CPU68030 includes the 68881/68882 FPU and its on-chip PMMU.
+	CPU68030
+	CPU68040
+	CPU68060
+	// CPU32 is the 68020 subset used by the 68330, 68332 and 68340.
+	CPU32
+	// CPUColdFireISAA, CPUColdFireISAB and CPUColdFireISAC select a ColdFire
+	// instruction set revision. All three decode the MAC/EMAC instructions.
+	CPUColdFireISAA
+	CPUColdFireISAB
+	CPUColdFireISAC
+)
+
+var cpuNames = map[CPU]string{
+	CPUAny:          "680x0",
+	CPU68000:        "68000",
+	CPU68008:        "68008",
+	CPU68010:        "68010",
+	CPU68020:        "68020",
+	CPU68030:        "68030",
+	CPU68040:        "68040",
+	CPU68060:        "68060",
+	CPU32:           "CPU32",
+	CPUColdFireISAA: "ColdFire ISA_A",
+	CPUColdFireISAB: "ColdFire ISA_B",
+	CPUColdFireISAC: "ColdFire ISA_C",
+}
+
+func (c CPU) String() string {
+	if name, ok := cpuNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("CPU(%d)", int(c))
+}
+
+const (
+	features68020Core = decoders.Feature68010 | decoders.Feature68020 | decoders.Feature68020Full | decoders.Feature680x0
+	// featuresColdFire is the ISA_A base; ColdFireRequirement filters out the
+	// 68010/68020 instructions ColdFire lacks.
+	featuresColdFire = decoders.Feature68010 | decoders.Feature68020 | decoders.FeatureColdFire | decoders.FeatureMAC
+	// featuresNon680x0 are decoded only when their model is selected.
+	featuresNon680x0 = decoders.FeatureCPU32 | decoders.FeatureColdFire | decoders.FeatureColdFireISAB |
+		decoders.FeatureColdFireISAC | decoders.FeatureMAC
+)
+
+// features returns the instruction-set features the model implements.
+func (c CPU) features() decoders.Feature {
+	switch c {
+	case CPUAny:
+		// CPU32 and ColdFire reuse opcodes that trap or are illegal on the
+		// 680x0 family.
+		return decoders.FeatureAll &^ featuresNon680x0
+	case CPU68000, CPU68008:
+		return decoders.Feature680x0
+	case CPU68010:
+		return decoders.Feature680x0 | decoders.Feature68010
+	case CPU68020:
+		return features68020Core | decoders.FeatureCALLM | decoders.FeatureFPU | decoders.FeaturePMMU |
+			decoders.Feature68851 | decoders.FeatureCAAR | decoders.FeatureMSP
+	case CPU68030:
+		return features68020Core | decoders.FeatureFPU | decoders.FeaturePMMU | decoders.Feature68030MMU |
+			decoders.FeatureCAAR | decoders.FeatureMSP
+	case CPU68040:
+		return features68020Core | decoders.FeatureFPU | decoders.Feature68040 | decoders.FeaturePTEST040 |
+			decoders.FeatureMSP
+	case CPU68060:
+		return features68020Core | decoders.FeatureFPU | decoders.Feature68040 | decoders.Feature68060
+	case CPU32:
+		return decoders.Feature680x0 | decoders.Feature68010 | decoders.Feature68020 | decoders.FeatureCPU32
+	case CPUColdFireISAA:
+		return featuresColdFire
+	case CPUColdFireISAB:
+		return featuresColdFire | decoders.FeatureColdFireISAB
+	case CPUColdFireISAC:
+		return featuresColdFire | decoders.FeatureColdFireISAB | decoders.FeatureColdFireISAC
+	}
+	return 0
+}
+
+// unavailableReason explains why an instruction needing missing features is
+// rejected for the model.
+func (c CPU) unavailableReason(missing decoders.Feature) string {
+	return fmt.Sprintf("not available on %s (requires %s)", c, missing)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/jenska/m68kdasm/internal/decoders"
 )
@@ -19,6 +20,10 @@ type Instruction struct {
 	Bytes          []byte // Die Rohdaten der Instruktion
 	ExtensionWords []uint16
 	Metadata       DecodeMetadata
+	// Comment ist ein Zeilenkommentar ohne "; ", z.B. das Ziel eines
+	// PC-relativen Operanden bei PCRelativeComment. Assembly enthält ihn
+	// nicht.
+	Comment string
 }
 
 // Assembly liefert den reinen Assembler-Code (Mnemonic + Operanden).
@@ -31,6 +36,9 @@ func (i Instruction) Assembly() string {
 
 // String liefert eine lesbare Repräsentation der Instruktion (z.B. für CLI-Output).
 func (i Instruction) String() string {
+	if i.Comment != "" {
+		return fmt.Sprintf("%08X: %s ; %s", i.Address, i.Assembly(), i.Comment)
+	}
 	return fmt.Sprintf("%08X: %s", i.Address, i.Assembly())
 }
 
@@ -117,29 +125,14 @@ func decodeInstruction(initial []byte, address uint32, reader addressReader, opt
 	}
 
 	opcode := binary.BigEndian.Uint16(data[:2])
-	decoder := decoders.FindDecoder(opcode)
+	features := opts.CPU.features()
+	decoder, missing := decoders.LookupDecoder(opcode, features)
 	if decoder == nil {
-		return finalizeInstruction(&decoders.Instruction{
-			Address:  address,
-			Opcode:   opcode,
-			Mnemonic: "DC.W",
-			Operands: fmt.Sprintf("$%04X", opcode),
-			Size:     2,
-			Bytes:    data[:2],
-			Metadata: decoders.Metadata{
-				Mnemonic:     "DC.W",
-				MnemonicBase: "DC",
-				SizeSuffix:   "W",
-				Operands: []decoders.Operand{
-					{
-						Text:      fmt.Sprintf("$%04X", opcode),
-						Kind:      decoders.OperandKindImmediate,
-						Immediate: &decoders.ImmediateValue{Value: uint32(opcode), Signed: int32(int16(opcode)), Size: 2},
-					},
-				},
-				ImmediateValues: []decoders.ImmediateValue{{Value: uint32(opcode), Signed: int32(int16(opcode)), Size: 2}},
-			},
-		}, opts), nil
+		inst := dataWord(address, opcode, data[:2], opts)
+		if missing != 0 {
+			inst.Metadata.UnavailableReason = opts.CPU.unavailableReason(missing)
+		}
+		return inst, nil
 	}
 
 	for {
@@ -152,9 +145,33 @@ func decodeInstruction(initial []byte, address uint32, reader addressReader, opt
 
 		err := decoder(data, opcode, decoderInst)
 		if err == nil {
+			// Das Muster ist verfügbar, aber diese Kodierung (z.B. skalierter
+			// Index oder Bcc.L) braucht eventuell eine neuere CPU.
+			missing := decoderInst.Requires &^ features
+			if missing == 0 && features&decoders.FeatureColdFire != 0 {
+				missing = decoders.ColdFireRequirement(decoderInst) &^ features
+			}
+			if missing != 0 {
+				inst := dataWord(address, opcode, data[:2], opts)
+				inst.Metadata.UnavailableReason = opts.CPU.unavailableReason(missing)
+				return inst, nil
+			}
 			return finalizeInstruction(decoderInst, opts), nil
 		}
 
+		var illegal *decoders.IllegalEncodingError
+		if errors.As(err, &illegal) {
+			return nil, &IllegalEncodingError{
+				Address:  address,
+				Opcode:   opcode,
+				Mnemonic: illegal.Mnemonic,
+				Operand:  illegal.Role,
+				Mode:     illegal.Mode,
+				Register: illegal.Register,
+				Kind:     EffectiveAddressKind(illegal.Kind),
+			}
+		}
+
 		var needMore *decoders.NeedMoreError
 		if !errors.As(err, &needMore) {
 			return nil, err
@@ -171,6 +188,31 @@ func decodeInstruction(initial []byte, address uint32, reader addressReader, opt
 	}
 }
 
+// dataWord liefert das DC.W für ein Opcode-Wort, das nicht dekodiert werden kann.
+func dataWord(address uint32, opcode uint16, raw []byte, opts DecodeOptions) *Instruction {
+	return finalizeInstruction(&decoders.Instruction{
+		Address:  address,
+		Opcode:   opcode,
+		Mnemonic: "DC.W",
+		Operands: fmt.Sprintf("$%04X", opcode),
+		Size:     2,
+		Bytes:    raw,
+		Metadata: decoders.Metadata{
+			Mnemonic:     "DC.W",
+			MnemonicBase: "DC",
+			SizeSuffix:   "W",
+			Operands: []decoders.Operand{
+				{
+					Text:      fmt.Sprintf("$%04X", opcode),
+					Kind:      decoders.OperandKindImmediate,
+					Immediate: &decoders.ImmediateValue{Value: uint32(opcode), Signed: int32(int16(opcode)), Size: 2},
+				},
+			},
+			ImmediateValues: []decoders.ImmediateValue{{Value: uint32(opcode), Signed: int32(int16(opcode)), Size: 2}},
+		},
+	}, opts)
+}
+
 func readUntil(data *[]byte, address uint32, reader addressReader, need int) error {
 	for len(*data) < need {
 		chunk := make([]byte, need-len(*data))
@@ -213,24 +255,49 @@ func finalizeInstruction(decoderInst *decoders.Instruction, opts DecodeOptions)
 		Metadata:       convertMetadata(decoderInst.Metadata),
 	}
 
-	if opts.Symbolizer != nil && len(inst.Metadata.Operands) > 0 {
-		inst.Operands = formatOperands(inst.Metadata.Operands, opts.Symbolizer)
+	if opts.Syntax != SyntaxMotorola {
+		inst.Mnemonic = opts.Syntax.renderMnemonic(inst.Metadata)
+	}
+	formatter := opts.Formatter
+	if formatter == nil && opts.Syntax != SyntaxMotorola {
+		formatter = SyntaxFormatter{Syntax: opts.Syntax}
+	}
+	if (opts.Symbolizer != nil || formatter != nil) && len(inst.Metadata.Operands) > 0 {
+		inst.Operands = formatOperands(inst, opts, formatter)
+	}
+	if opts.PCRelative == PCRelativeComment {
+		inst.Comment = pcRelativeComment(inst.Metadata.Operands, opts.Syntax)
+	}
+	if opts.TrapNamer != nil && isLineTrap(inst.Metadata.MnemonicBase) {
+		if name, ok := opts.TrapNamer.NameTrap(inst.Opcode); ok {
+			inst.Mnemonic = name
+			inst.Operands = ""
+		}
 	}
 
 	return inst
 }
 
+// isLineTrap meldet, ob die Instruktion ein Line-A- oder Line-F-Trap ist.
+func isLineTrap(mnemonicBase string) bool {
+	return mnemonicBase == "LINEA" || mnemonicBase == "LINEF"
+}
+
 func convertMetadata(meta decoders.Metadata) DecodeMetadata {
 	converted := DecodeMetadata{
 		Mnemonic:        meta.Mnemonic,
 		MnemonicBase:    meta.MnemonicBase,
 		SizeSuffix:      meta.SizeSuffix,
 		BranchTarget:    cloneUint32Ptr(meta.BranchTarget),
+		NonCanonical:    meta.NonCanonical,
 		ImmediateValues: make([]ImmediateValue, len(meta.ImmediateValues)),
 		Operands:        make([]Operand, len(meta.Operands)),
+		Reads:           convertRegisters(meta.Reads),
+		Writes:          convertRegisters(meta.Writes),
+		ConditionCodes:  convertConditionCodes(meta.ConditionCodes),
 	}
 	for i, imm := range meta.ImmediateValues {
-		converted.ImmediateValues[i] = ImmediateValue{Value: imm.Value, Signed: imm.Signed, Size: imm.Size}
+		converted.ImmediateValues[i] = *convertImmediate(&imm)
 	}
 	for i, operand := range meta.Operands {
 		converted.Operands[i] = convertOperand(operand)
@@ -244,53 +311,103 @@ func convertOperand(operand decoders.Operand) Operand {
 		Kind:         OperandKind(operand.Kind),
 		RegisterList: append([]string(nil), operand.RegisterList...),
 		BranchTarget: cloneUint32Ptr(operand.BranchTarget),
+		Suffix:       operand.Suffix,
 	}
 	if operand.Register != nil {
-		converted.Register = &Register{
-			Kind:   RegisterKind(operand.Register.Kind),
-			Number: operand.Register.Number,
-		}
+		reg := convertRegister(*operand.Register)
+		converted.Register = &reg
 	}
 	if operand.Immediate != nil {
-		converted.Immediate = &ImmediateValue{
-			Value:  operand.Immediate.Value,
-			Signed: operand.Immediate.Signed,
-			Size:   operand.Immediate.Size,
-		}
+		converted.Immediate = convertImmediate(operand.Immediate)
 	}
 	if operand.EffectiveAddress != nil {
 		ea := &EffectiveAddress{
-			Kind:            EffectiveAddressKind(operand.EffectiveAddress.Kind),
-			Mode:            operand.EffectiveAddress.Mode,
-			Register:        operand.EffectiveAddress.Register,
-			Displacement:    cloneInt32Ptr(operand.EffectiveAddress.Displacement),
-			AbsoluteAddress: cloneUint32Ptr(operand.EffectiveAddress.AbsoluteAddress),
-			ResolvedAddress: cloneUint32Ptr(operand.EffectiveAddress.ResolvedAddress),
+			Kind:              EffectiveAddressKind(operand.EffectiveAddress.Kind),
+			Mode:              operand.EffectiveAddress.Mode,
+			Register:          operand.EffectiveAddress.Register,
+			Displacement:      cloneInt32Ptr(operand.EffectiveAddress.Displacement),
+			AbsoluteAddress:   cloneUint32Ptr(operand.EffectiveAddress.AbsoluteAddress),
+			ResolvedAddress:   cloneUint32Ptr(operand.EffectiveAddress.ResolvedAddress),
+			Scale:             operand.EffectiveAddress.Scale,
+			OuterDisplacement: cloneInt32Ptr(operand.EffectiveAddress.OuterDisplacement),
+			Indirection:       MemoryIndirection(operand.EffectiveAddress.Indirection),
 		}
 		if operand.EffectiveAddress.Base != nil {
-			ea.Base = &Register{
-				Kind:   RegisterKind(operand.EffectiveAddress.Base.Kind),
-				Number: operand.EffectiveAddress.Base.Number,
-			}
+			base := convertRegister(*operand.EffectiveAddress.Base)
+			ea.Base = &base
 		}
 		if operand.EffectiveAddress.Immediate != nil {
-			ea.Immediate = &ImmediateValue{
-				Value:  operand.EffectiveAddress.Immediate.Value,
-				Signed: operand.EffectiveAddress.Immediate.Signed,
-				Size:   operand.EffectiveAddress.Immediate.Size,
-			}
+			ea.Immediate = convertImmediate(operand.EffectiveAddress.Immediate)
 		}
 		if operand.EffectiveAddress.Index != nil {
 			ea.Index = &IndexRegister{
-				Register: Register{
-					Kind:   RegisterKind(operand.EffectiveAddress.Index.Register.Kind),
-					Number: operand.EffectiveAddress.Index.Register.Number,
-				},
-				Size: operand.EffectiveAddress.Index.Size,
+				Register: convertRegister(operand.EffectiveAddress.Index.Register),
+				Size:     operand.EffectiveAddress.Index.Size,
 			}
 		}
 		converted.EffectiveAddress = ea
 	}
+	if operand.Bitfield != nil {
+		bf := &Bitfield{
+			Offset: operand.Bitfield.Offset,
+			Width:  operand.Bitfield.Width,
+		}
+		if operand.Bitfield.OffsetRegister != nil {
+			reg := convertRegister(*operand.Bitfield.OffsetRegister)
+			bf.OffsetRegister = &reg
+		}
+		if operand.Bitfield.WidthRegister != nil {
+			reg := convertRegister(*operand.Bitfield.WidthRegister)
+			bf.WidthRegister = &reg
+		}
+		converted.Bitfield = bf
+	}
+	if operand.RegisterPair != nil {
+		converted.RegisterPair = &RegisterPair{
+			First:    convertRegister(operand.RegisterPair.First),
+			Second:   convertRegister(operand.RegisterPair.Second),
+			Indirect: operand.RegisterPair.Indirect,
+		}
+	}
+	return converted
+}
+
+func convertImmediate(imm *decoders.ImmediateValue) *ImmediateValue {
+	converted := &ImmediateValue{Value: imm.Value, Signed: imm.Signed, Size: imm.Size, SignExtended: imm.SignExtended}
+	if imm.Float != nil {
+		value := *imm.Float
+		converted.Float = &value
+	}
+	return converted
+}
+
+func convertRegister(reg decoders.Register) Register {
+	return Register{
+		Kind:   RegisterKind(reg.Kind),
+		Number: reg.Number,
+		Name:   reg.Name,
+	}
+}
+
+func convertConditionCodes(cc decoders.ConditionCodes) ConditionCodes {
+	return ConditionCodes{
+		X:    FlagEffect(cc.X),
+		N:    FlagEffect(cc.N),
+		Z:    FlagEffect(cc.Z),
+		V:    FlagEffect(cc.V),
+		C:    FlagEffect(cc.C),
+		Uses: Flags(cc.Uses),
+	}
+}
+
+func convertRegisters(regs []decoders.Register) []Register {
+	if len(regs) == 0 {
+		return nil
+	}
+	converted := make([]Register, len(regs))
+	for i, reg := range regs {
+		converted[i] = convertRegister(reg)
+	}
 	return converted
 }
 
@@ -310,45 +427,164 @@ func cloneInt32Ptr(v *int32) *int32 {
 	return &cloned
 }
 
-func formatOperands(operands []Operand, symbolizer Symbolizer) string {
-	rendered := make([]string, 0, len(operands))
-	for _, operand := range operands {
-		rendered = append(rendered, formatOperand(operand, symbolizer))
+// formatOperands rendert die Operanden; ohne Formatter bleibt der Text des
+// Decoders (Motorola-Syntax) erhalten.
+func formatOperands(inst *Instruction, opts DecodeOptions, formatter Formatter) string {
+	rendered := make([]string, 0, len(inst.Metadata.Operands))
+	for index, operand := range inst.Metadata.Operands {
+		rendered = append(rendered, formatOperand(inst, index, operand, opts, formatter))
 	}
-	if len(rendered) == 0 {
-		return ""
+	if formatter == nil {
+		return strings.Join(rendered, ", ")
 	}
-	return joinOperands(rendered)
+	return strings.Join(rendered, formatter.Separator())
 }
 
-func formatOperand(operand Operand, symbolizer Symbolizer) string {
-	if operand.BranchTarget != nil {
-		if symbol, ok := symbolizer.Symbolize(*operand.BranchTarget); ok {
+func formatOperand(inst *Instruction, index int, operand Operand, opts DecodeOptions, formatter Formatter) string {
+	if opts.Symbolizer != nil {
+		lookup := symbolLookup{inst: inst, operand: index, opts: opts}
+		if symbol, ok := lookup.symbolizeOperand(operand); ok {
 			return symbol
 		}
-	}
-	if operand.EffectiveAddress != nil {
-		if operand.EffectiveAddress.ResolvedAddress != nil {
-			if symbol, ok := symbolizer.Symbolize(*operand.EffectiveAddress.ResolvedAddress); ok {
-				return symbol
+		if opts.PCRelative == PCRelativeLabel {
+			if text, ok := lookup.pcRelativeLabel(operand); ok {
+				return text
 			}
 		}
-		if operand.EffectiveAddress.AbsoluteAddress != nil {
-			if symbol, ok := symbolizer.Symbolize(*operand.EffectiveAddress.AbsoluteAddress); ok {
-				return symbol
-			}
+	}
+	if formatter == nil {
+		return operand.Text
+	}
+	return formatWith(formatter, operand)
+}
+
+// symbolLookup fragt den Symbolizer für einen Operanden ab. Ein
+// ContextSymbolizer erhält den Kontext und darf Symbol+Offset liefern; ein
+// einfacher Symbolizer nur exakte Adressen, und Immediates werden ihm nicht
+// vorgelegt.
+type symbolLookup struct {
+	inst    *Instruction
+	operand int
+	opts    DecodeOptions
+}
+
+func (l symbolLookup) symbolize(value uint32, role SymbolRole, size uint8) (string, bool) {
+	contextual, ok := l.opts.Symbolizer.(ContextSymbolizer)
+	if !ok {
+		if role == SymbolRoleImmediate {
+			return "", false
 		}
+		return l.opts.Symbolizer.Symbolize(value)
+	}
+	name, offset, ok := contextual.SymbolizeContext(value, SymbolContext{
+		Instruction: l.inst,
+		Operand:     l.operand,
+		Role:        role,
+		Size:        size,
+	})
+	if !ok {
+		return "", false
 	}
-	return operand.Text
+	switch {
+	case offset > 0:
+		return name + "+" + l.opts.Syntax.hex(uint32(offset), 0), true
+	case offset < 0:
+		return name + "-" + l.opts.Syntax.hex(uint32(-int64(offset)), 0), true
+	}
+	return name, true
 }
 
-func joinOperands(parts []string) string {
-	if len(parts) == 0 {
+// symbolizeOperand liefert den Symbolnamen für Sprungziele, absolute
+// Adressen und Immediates. PC-relative Operanden bleiben hier numerisch, da
+// ein nackter Symbolname absolut assembliert würde; siehe pcRelativeLabel.
+func (l symbolLookup) symbolizeOperand(operand Operand) (string, bool) {
+	if operand.BranchTarget != nil {
+		if symbol, ok := l.symbolize(*operand.BranchTarget, SymbolRoleBranchTarget, 0); ok {
+			return symbol, true
+		}
+	}
+	size := l.accessSize()
+	if operand.Immediate != nil {
+		return l.symbolizeImmediate(operand, operand.Immediate)
+	}
+	ea := operand.EffectiveAddress
+	if ea == nil || isPCRelative(ea.Kind) {
+		return "", false
+	}
+	if ea.Immediate != nil {
+		return l.symbolizeImmediate(operand, ea.Immediate)
+	}
+	if ea.AbsoluteAddress == nil {
+		return "", false
+	}
+	// Absolute short addresses are sign-extended: $8240 is $FFFF8240.
+	address := *ea.AbsoluteAddress
+	if ea.Kind == EAKindAbsoluteShort {
+		address = uint32(int32(int16(address)))
+	}
+	return l.symbolize(address, SymbolRoleAbsolute, size)
+}
+
+// symbolizeImmediate ersetzt ganzzahlige Immediates durch #Symbol. DC-Daten
+// und Gleitkommawerte bleiben unverändert.
+func (l symbolLookup) symbolizeImmediate(operand Operand, imm *ImmediateValue) (string, bool) {
+	if imm.Float != nil || !strings.HasPrefix(operand.Text, "#") {
+		return "", false
+	}
+	if symbol, ok := l.symbolize(imm.Value, SymbolRoleImmediate, imm.Size); ok {
+		return "#" + symbol, true
+	}
+	return "", false
+}
+
+// accessSize liefert die Operandengröße der Instruktion in Bytes, oder 0
+// ohne Größenangabe (LEA, JSR, ...).
+func (l symbolLookup) accessSize() uint8 {
+	return accessSizes[l.inst.Metadata.SizeSuffix]
+}
+
+var accessSizes = map[string]uint8{"B": 1, "W": 2, "L": 4, "S": 4, "D": 8, "X": 12, "P": 12}
+
+// pcRelativeLabel rendert einen PC-relativen Operanden mit benanntem Ziel als
+// label(PC) bzw. label(PC,Xn), in MIT-Syntax als %pc@(label).
+func (l symbolLookup) pcRelativeLabel(operand Operand) (string, bool) {
+	ea := operand.EffectiveAddress
+	if ea == nil || !isPCRelative(ea.Kind) || ea.ResolvedAddress == nil || ea.Base == nil {
+		return "", false
+	}
+	symbol, ok := l.symbolize(*ea.ResolvedAddress, SymbolRolePCRelative, l.accessSize())
+	if !ok {
+		return "", false
+	}
+	syntax := l.opts.Syntax
+	pc := syntax.register(*ea.Base)
+	if syntax == SyntaxMIT {
+		return pc + "@(" + joinParts(symbol, indexPart(ea, syntax)) + ")", true
+	}
+	return symbol + "(" + joinParts(pc, indexPart(ea, syntax)) + ")", true
+}
+
+func indexPart(ea *EffectiveAddress, syntax Syntax) string {
+	if ea.Index == nil {
 		return ""
 	}
-	out := parts[0]
-	for _, part := range parts[1:] {
-		out += ", " + part
+	return syntax.indexRegister(*ea.Index, ea.Scale)
+}
+
+// pcRelativeComment liefert die Ziele der PC-relativen Operanden als
+// Kommentar, z.B. "=$1010".
+func pcRelativeComment(operands []Operand, syntax Syntax) string {
+	var targets []string
+	for _, operand := range operands {
+		ea := operand.EffectiveAddress
+		if ea != nil && isPCRelative(ea.Kind) && ea.ResolvedAddress != nil {
+			targets = append(targets, "="+syntax.address(*ea.ResolvedAddress))
+		}
 	}
-	return out
+	return strings.Join(targets, ", ")
+}
+
+// isPCRelative meldet, ob die Adressierungsart relativ zum PC ist.
+func isPCRelative(kind EffectiveAddressKind) bool {
+	return kind == EAKindPCDisplacement || kind == EAKindPCIndex
 }
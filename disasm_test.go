@@ -2,8 +2,12 @@ package m68kdasm
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jenska/m68kasm"
@@ -214,6 +218,590 @@ func TestDecodeRegressionRawOpcodes(t *testing.T) {
 			data: []byte{0x60, 0x3C},
 			want: "BRA.S $003E",
 		},
+		{
+			name: "ADDQ.W quick one",
+			data: []byte{0x52, 0x40},
+			want: "ADDQ.W #1, D0",
+		},
+		{
+			name: "SUBQ.L quick eight to address register",
+			data: []byte{0x51, 0x89},
+			want: "SUBQ.L #8, A1",
+		},
+		{
+			name: "ADDQ.B absolute long",
+			data: []byte{0x5C, 0x39, 0x00, 0x00, 0x12, 0x34},
+			want: "ADDQ.B #6, $00001234",
+		},
+		{
+			name: "SEQ data register",
+			data: []byte{0x57, 0xC0},
+			want: "SEQ D0",
+		},
+		{
+			name: "ST address indirect",
+			data: []byte{0x50, 0xD0},
+			want: "ST (A0)",
+		},
+		{
+			name: "ORI to SR",
+			data: []byte{0x00, 0x7C, 0x07, 0x00},
+			want: "ORI #$0700, SR",
+		},
+		{
+			name: "ANDI to CCR",
+			data: []byte{0x02, 0x3C, 0x00, 0xFE},
+			want: "ANDI #$FE, CCR",
+		},
+		{
+			name: "MOVE from SR predecrement",
+			data: []byte{0x40, 0xE7},
+			want: "MOVE.W SR, -(A7)",
+		},
+		{
+			name: "MOVE to SR immediate",
+			data: []byte{0x46, 0xFC, 0x27, 0x00},
+			want: "MOVE.W #$2700, SR",
+		},
+		{
+			name: "MOVE to CCR",
+			data: []byte{0x44, 0xC0},
+			want: "MOVE.W D0, CCR",
+		},
+		{
+			name: "MOVE USP to address register",
+			data: []byte{0x4E, 0x68},
+			want: "MOVE USP, A0",
+		},
+		{
+			name: "MOVE address register to USP",
+			data: []byte{0x4E, 0x61},
+			want: "MOVE A1, USP",
+		},
+		{
+			name: "RTE",
+			data: []byte{0x4E, 0x73},
+			want: "RTE",
+		},
+		{
+			name: "RTR",
+			data: []byte{0x4E, 0x77},
+			want: "RTR",
+		},
+		{
+			name: "RESET",
+			data: []byte{0x4E, 0x70},
+			want: "RESET",
+		},
+		{
+			name: "ILLEGAL",
+			data: []byte{0x4A, 0xFC},
+			want: "ILLEGAL",
+		},
+		{
+			name: "EXG data registers",
+			data: []byte{0xC1, 0x41},
+			want: "EXG D0, D1",
+		},
+		{
+			name: "EXG address registers",
+			data: []byte{0xC3, 0x4F},
+			want: "EXG A1, A7",
+		},
+		{
+			name: "EXG data and address register",
+			data: []byte{0xC5, 0x89},
+			want: "EXG D2, A1",
+		},
+		{
+			name: "EXT.W not shadowed by MOVEM",
+			data: []byte{0x48, 0x80},
+			want: "EXT.W D0",
+		},
+		{
+			name: "EXT.L",
+			data: []byte{0x48, 0xC3},
+			want: "EXT.L D3",
+		},
+		{
+			name: "CHK absolute long",
+			data: []byte{0x41, 0xB9, 0x00, 0x00, 0x10, 0x00},
+			want: "CHK $00001000, D0",
+		},
+		{
+			name: "LINK negative displacement",
+			data: []byte{0x4E, 0x56, 0xFF, 0xF8},
+			want: "LINK A6, #-$8",
+		},
+		{
+			name: "UNLK",
+			data: []byte{0x4E, 0x5E},
+			want: "UNLK A6",
+		},
+		{
+			name: "TAS not shadowed by TST",
+			data: []byte{0x4A, 0xD0},
+			want: "TAS (A0)",
+		},
+		{
+			name: "NBCD",
+			data: []byte{0x48, 0x00},
+			want: "NBCD D0",
+		},
+		{
+			name: "MOVEP.W register to memory",
+			data: []byte{0x01, 0x88, 0xFF, 0xFE},
+			want: "MOVEP.W D0, (-2,A0)",
+		},
+		{
+			name: "MOVEP.L memory to register",
+			data: []byte{0x05, 0x48, 0x00, 0x0A},
+			want: "MOVEP.L (10,A0), D2",
+		},
+		{
+			name: "ADDX.L predecrement",
+			data: []byte{0xD3, 0x88},
+			want: "ADDX.L -(A0), -(A1)",
+		},
+		{
+			name: "ADDX.B data registers",
+			data: []byte{0xD1, 0x01},
+			want: "ADDX.B D1, D0",
+		},
+		{
+			name: "SUBX.W predecrement",
+			data: []byte{0x9F, 0x48},
+			want: "SUBX.W -(A0), -(A7)",
+		},
+		{
+			name: "ADDA.L not shadowed by ADDX",
+			data: []byte{0xD3, 0xC8},
+			want: "ADDA.L A0, A1",
+		},
+		{
+			name: "CMPM.W",
+			data: []byte{0xB3, 0x4F},
+			want: "CMPM.W (A7)+, (A1)+",
+		},
+		{
+			name: "CMPA.L address register source not CMPM",
+			data: []byte{0xB1, 0xC8},
+			want: "CMPA.L A0, A0",
+		},
+		{
+			name: "EOR.L data register",
+			data: []byte{0xB3, 0x80},
+			want: "EOR.L D1, D0",
+		},
+		{
+			name: "MOVEC to VBR",
+			data: []byte{0x4E, 0x7B, 0x08, 0x01},
+			want: "MOVEC D0, VBR",
+		},
+		{
+			name: "MOVEC from VBR to address register",
+			data: []byte{0x4E, 0x7A, 0x88, 0x01},
+			want: "MOVEC VBR, A0",
+		},
+		{
+			name: "MOVES memory to register",
+			data: []byte{0x0E, 0x50, 0x00, 0x00},
+			want: "MOVES.W (A0), D0",
+		},
+		{
+			name: "MOVES register to memory",
+			data: []byte{0x0E, 0x28, 0x28, 0x00, 0xFF, 0xFE},
+			want: "MOVES.B D2, (-2,A0)",
+		},
+		{
+			name: "BFEXTU immediate offset and width",
+			data: []byte{0xE9, 0xD0, 0x11, 0x08},
+			want: "BFEXTU (A0){4:8}, D1",
+		},
+		{
+			name: "BFINS register offset",
+			data: []byte{0xEF, 0xC0, 0x1A, 0x88},
+			want: "BFINS D1, D0{D2:8}",
+		},
+		{
+			name: "BFTST width 32",
+			data: []byte{0xE8, 0xC0, 0x00, 0x00},
+			want: "BFTST D0{0:32}",
+		},
+		{
+			name: "CAS.L",
+			data: []byte{0x0E, 0xD0, 0x00, 0x40},
+			want: "CAS.L D0, D1, (A0)",
+		},
+		{
+			name: "CAS2.W",
+			data: []byte{0x0C, 0xFC, 0x80, 0x80, 0x90, 0xC1},
+			want: "CAS2.W D0:D1, D2:D3, (A0):(A1)",
+		},
+		{
+			name: "CHK2.W",
+			data: []byte{0x02, 0xD0, 0x18, 0x00},
+			want: "CHK2.W (A0), D1",
+		},
+		{
+			name: "CMP2.L address register",
+			data: []byte{0x04, 0xD0, 0x90, 0x00},
+			want: "CMP2.L (A0), A1",
+		},
+		{
+			name: "PACK predecrement",
+			data: []byte{0x81, 0x49, 0x00, 0x10},
+			want: "PACK -(A1), -(A0), #16",
+		},
+		{
+			name: "UNPK data registers",
+			data: []byte{0x83, 0x80, 0x30, 0x30},
+			want: "UNPK D0, D1, #$3030",
+		},
+		{
+			name: "MULU.L 32-bit product",
+			data: []byte{0x4C, 0x01, 0x20, 0x00},
+			want: "MULU.L D1, D2",
+		},
+		{
+			name: "MULS.L 64-bit product",
+			data: []byte{0x4C, 0x01, 0x2C, 0x03},
+			want: "MULS.L D1, D3:D2",
+		},
+		{
+			name: "DIVUL.L remainder",
+			data: []byte{0x4C, 0x41, 0x20, 0x03},
+			want: "DIVUL.L D1, D3:D2",
+		},
+		{
+			name: "DIVS.L 64-bit dividend",
+			data: []byte{0x4C, 0x41, 0x2C, 0x03},
+			want: "DIVS.L D1, D3:D2",
+		},
+		{
+			name: "TRAPNE.W",
+			data: []byte{0x56, 0xFA, 0x12, 0x34},
+			want: "TRAPNE.W #$1234",
+		},
+		{
+			name: "TRAPF without operand",
+			data: []byte{0x51, 0xFC},
+			want: "TRAPF",
+		},
+		{
+			name: "EXTB.L",
+			data: []byte{0x49, 0xC1},
+			want: "EXTB.L D1",
+		},
+		{
+			name: "LINK.L",
+			data: []byte{0x48, 0x0E, 0xFF, 0xFF, 0xFF, 0xF8},
+			want: "LINK.L A6, #-$8",
+		},
+		{
+			name: "CHK.L",
+			data: []byte{0x43, 0x00},
+			want: "CHK.L D0, D1",
+		},
+		{
+			name: "CALLM",
+			data: []byte{0x06, 0xD0, 0x00, 0x03},
+			want: "CALLM #3, (A0)",
+		},
+		{
+			name: "RTM address register",
+			data: []byte{0x06, 0xC8},
+			want: "RTM A0",
+		},
+		{
+			name: "FADD register to register",
+			data: []byte{0xF2, 0x00, 0x05, 0x22},
+			want: "FADD.X FP1, FP2",
+		},
+		{
+			name: "FSQRT monadic same register",
+			data: []byte{0xF2, 0x00, 0x0D, 0x84},
+			want: "FSQRT.X FP3",
+		},
+		{
+			name: "FMOVE.D memory source",
+			data: []byte{0xF2, 0x10, 0x54, 0x00},
+			want: "FMOVE.D (A0), FP0",
+		},
+		{
+			name: "FMOVE.S single immediate",
+			data: []byte{0xF2, 0x3C, 0x44, 0x80, 0x3F, 0xC0, 0x00, 0x00},
+			want: "FMOVE.S #1.5, FP1",
+		},
+		{
+			name: "FMUL.X extended immediate",
+			data: []byte{0xF2, 0x3C, 0x48, 0x23, 0x40, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			want: "FMUL.X #2.0, FP0",
+		},
+		{
+			name: "FMOVE.P packed immediate",
+			data: []byte{0xF2, 0x3C, 0x4C, 0x00, 0xC0, 0x12, 0x00, 0x01, 0x25, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			want: "FMOVE.P #-1.25E-12, FP0",
+		},
+		{
+			name: "FMOVE.L to data register",
+			data: []byte{0xF2, 0x00, 0x61, 0x00},
+			want: "FMOVE.L FP2, D0",
+		},
+		{
+			name: "FMOVE.L to FPCR",
+			data: []byte{0xF2, 0x10, 0x90, 0x00},
+			want: "FMOVE.L (A0), FPCR",
+		},
+		{
+			name: "FMOVEM.L control registers",
+			data: []byte{0xF2, 0x27, 0xB8, 0x00},
+			want: "FMOVEM.L FPCR/FPSR, -(A7)",
+		},
+		{
+			name: "FMOVEM.X predecrement",
+			data: []byte{0xF2, 0x27, 0xE0, 0x0F},
+			want: "FMOVEM.X FP0-FP3, -(A7)",
+		},
+		{
+			name: "FMOVEM.X postincrement",
+			data: []byte{0xF2, 0x1F, 0xD0, 0xF0},
+			want: "FMOVEM.X (A7)+, FP0-FP3",
+		},
+		{
+			name: "FMOVECR",
+			data: []byte{0xF2, 0x00, 0x5C, 0x32},
+			want: "FMOVECR.X #$32, FP0",
+		},
+		{
+			name: "FSINCOS",
+			data: []byte{0xF2, 0x00, 0x01, 0x31},
+			want: "FSINCOS.X FP0, FP1:FP2",
+		},
+		{
+			name: "FTST",
+			data: []byte{0xF2, 0x00, 0x00, 0x3A},
+			want: "FTST.X FP0",
+		},
+		{
+			name:    "FBEQ.W",
+			address: 0x1000,
+			data:    []byte{0xF2, 0x81, 0x00, 0x10},
+			want:    "FBEQ.W $1012",
+		},
+		{
+			name: "FNOP",
+			data: []byte{0xF2, 0x80, 0x00, 0x00},
+			want: "FNOP",
+		},
+		{
+			name: "FSNE",
+			data: []byte{0xF2, 0x40, 0x00, 0x0E},
+			want: "FSNE D0",
+		},
+		{
+			name:    "FDBNE",
+			address: 0x1000,
+			data:    []byte{0xF2, 0x49, 0x00, 0x0E, 0xFF, 0xFC},
+			want:    "FDBNE D1, $1000",
+		},
+		{
+			name: "FTRAPEQ",
+			data: []byte{0xF2, 0x7C, 0x00, 0x01},
+			want: "FTRAPEQ",
+		},
+		{
+			name: "FSAVE",
+			data: []byte{0xF3, 0x27},
+			want: "FSAVE -(A7)",
+		},
+		{
+			name: "FRESTORE",
+			data: []byte{0xF3, 0x5F},
+			want: "FRESTORE (A7)+",
+		},
+		{
+			name: "MOVEM does not merge data and address ranges",
+			data: []byte{0x48, 0xD0, 0x10, 0x08},
+			want: "MOVEM.L D3/A4, (A0)",
+		},
+		{
+			name: "PMOVE to TC",
+			data: []byte{0xF0, 0x10, 0x40, 0x00},
+			want: "PMOVE (A0), TC",
+		},
+		{
+			name: "PMOVE from MMUSR",
+			data: []byte{0xF0, 0x10, 0x62, 0x00},
+			want: "PMOVE MMUSR, (A0)",
+		},
+		{
+			name: "PMOVEFD to TT0",
+			data: []byte{0xF0, 0x10, 0x09, 0x00},
+			want: "PMOVEFD (A0), TT0",
+		},
+		{
+			name: "PLOADR",
+			data: []byte{0xF0, 0x10, 0x22, 0x11},
+			want: "PLOADR #1, (A0)",
+		},
+		{
+			name: "PFLUSHA 68030",
+			data: []byte{0xF0, 0x00, 0x24, 0x00},
+			want: "PFLUSHA",
+		},
+		{
+			name: "PFLUSH function code and mask",
+			data: []byte{0xF0, 0x10, 0x38, 0xF5},
+			want: "PFLUSH #5, #7, (A0)",
+		},
+		{
+			name: "PTESTR with address register",
+			data: []byte{0xF0, 0x10, 0x9F, 0x12},
+			want: "PTESTR #2, (A0), #7, A0",
+		},
+		{
+			name: "CINVP both caches",
+			data: []byte{0xF4, 0xD0},
+			want: "CINVP BC, (A0)",
+		},
+		{
+			name: "CPUSHA both caches",
+			data: []byte{0xF4, 0xF8},
+			want: "CPUSHA BC",
+		},
+		{
+			name: "PFLUSH 68040 address",
+			data: []byte{0xF5, 0x08},
+			want: "PFLUSH (A0)",
+		},
+		{
+			name: "PFLUSHA 68040",
+			data: []byte{0xF5, 0x18},
+			want: "PFLUSHA",
+		},
+		{
+			name: "PTESTR 68040",
+			data: []byte{0xF5, 0x68},
+			want: "PTESTR (A0)",
+		},
+		{
+			name: "PLPAR",
+			data: []byte{0xF5, 0xC9},
+			want: "PLPAR (A1)",
+		},
+		{
+			name: "MOVE16 postincrement",
+			data: []byte{0xF6, 0x20, 0x90, 0x00},
+			want: "MOVE16 (A0)+, (A1)+",
+		},
+		{
+			name: "MOVE16 absolute source",
+			data: []byte{0xF6, 0x08, 0x00, 0x00, 0x10, 0x00},
+			want: "MOVE16 $00001000, (A0)+",
+		},
+		{
+			name: "RTD",
+			data: []byte{0x4E, 0x74, 0x00, 0x08},
+			want: "RTD #8",
+		},
+		{
+			name: "BKPT not shadowed by PEA",
+			data: []byte{0x48, 0x4B},
+			want: "BKPT #3",
+		},
+		{
+			name: "MOVE from CCR not shadowed by CLR",
+			data: []byte{0x42, 0xE7},
+			want: "MOVE.W CCR, -(A7)",
+		},
+		{
+			name: "Brief extension word with scale",
+			data: []byte{0x32, 0x30, 0x1C, 0x04},
+			want: "MOVE.W (4,A0,D1.L*4), D1",
+		},
+		{
+			name: "Full extension word memory indirect pre-indexed",
+			data: []byte{0x32, 0x30, 0x1D, 0x22, 0x00, 0x10, 0x00, 0x08},
+			want: "MOVE.W ([16,A0,D1.L*4],8), D1",
+		},
+		{
+			name: "Full extension word memory indirect post-indexed",
+			data: []byte{0x32, 0x30, 0x1D, 0x26, 0x00, 0x10, 0x00, 0x08},
+			want: "MOVE.W ([16,A0],D1.L*4,8), D1",
+		},
+		{
+			name: "Full extension word suppressed base and null displacement",
+			data: []byte{0x30, 0x30, 0x19, 0x90},
+			want: "MOVE.W (D1.L), D0",
+		},
+		{
+			name: "Full extension word suppressed PC",
+			data: []byte{0x30, 0x3B, 0x01, 0xD0},
+			want: "MOVE.W (ZPC), D0",
+		},
+		{
+			name: "Line-A trap",
+			data: []byte{0xA1, 0x1E},
+			want: "LINEA #$011E",
+		},
+		{
+			name: "Line-F trap",
+			data: []byte{0xFF, 0x09},
+			want: "LINEF #$0F09",
+		},
+		{
+			name:    "DBF renders as DBRA",
+			address: 0x1000,
+			data:    []byte{0x51, 0xC8, 0xFF, 0xFE},
+			want:    "DBRA D0, $1000",
+		},
+		{
+			name:    "DBNE backwards",
+			address: 0x1000,
+			data:    []byte{0x56, 0xC9, 0xFF, 0xFC},
+			want:    "DBNE D1, $0FFE",
+		},
+		{
+			name: "ROR register shift",
+			data: []byte{0xE0, 0x58},
+			want: "ROR.W #8, D0",
+		},
+		{
+			name: "ROR byte shift",
+			data: []byte{0xE4, 0x18},
+			want: "ROR.B #2, D0",
+		},
+		{
+			name: "ASR memory shift",
+			data: []byte{0xE0, 0xD0},
+			want: "ASR.W (A0)",
+		},
+		{
+			name: "ROXL memory shift",
+			data: []byte{0xE5, 0xE8, 0x00, 0x0A},
+			want: "ROXL.W (10,A0)",
+		},
+		{
+			name: "MOVEM predecrement mask is reversed",
+			data: []byte{0x48, 0xE7, 0xC0, 0xC0},
+			want: "MOVEM.L D0-D1/A0-A1, -(A7)",
+		},
+		{
+			name: "BTST with D1 bit number",
+			data: []byte{0x03, 0x10},
+			want: "BTST D1, (A0)",
+		},
+		{
+			name: "BCHG with D7 bit number",
+			data: []byte{0x0F, 0x40},
+			want: "BCHG D7, D0",
+		},
+		{
+			name: "ORI.B ignores the high immediate byte",
+			data: []byte{0x00, 0x00, 0x81, 0x20},
+			want: "ORI.B #32, D0",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -260,6 +848,53 @@ func TestDecodeRegressionMaintainsInstructionAlignment(t *testing.T) {
 	}
 }
 
+func TestDecodeLongLogicalImmediates(t *testing.T) {
+	testCases := []struct {
+		data []byte
+		want string
+	}{
+		{data: []byte{0x00, 0x80, 0x12, 0x34, 0x56, 0x78}, want: "ORI.L #$12345678, D0"},
+		{data: []byte{0x02, 0x81, 0xFF, 0xFF, 0x00, 0x00}, want: "ANDI.L #$FFFF0000, D1"},
+		{data: []byte{0x0A, 0x82, 0x80, 0x00, 0x00, 0x01}, want: "EORI.L #$80000001, D2"},
+		{data: []byte{0x0C, 0x83, 0x00, 0x01, 0x00, 0x00}, want: "CMPI.L #$00010000, D3"},
+	}
+
+	for _, tc := range testCases {
+		inst, err := Decode(tc.data, 0)
+		if err != nil {
+			t.Fatalf("Decode-Fehler für % X: %v", tc.data, err)
+		}
+		if got := inst.Assembly(); got != tc.want {
+			t.Errorf("Unerwartete Assembly für % X: %q, erwartet %q", tc.data, got, tc.want)
+		}
+		if inst.Size != 6 {
+			t.Errorf("%s: Größe %d, erwartet 6", tc.want, inst.Size)
+		}
+	}
+}
+
+func TestDecodeImmediateRadix(t *testing.T) {
+	testCases := []struct {
+		data []byte
+		want string
+	}{
+		{data: []byte{0x08, 0x00, 0x00, 0x1F}, want: "BTST #31, D0"},
+		{data: []byte{0x08, 0x00, 0x00, 0x80}, want: "BTST #$80, D0"},
+		{data: []byte{0x08, 0xD0, 0x00, 0xC8}, want: "BSET #$C8, (A0)"},
+		{data: []byte{0x06, 0xD0, 0x00, 0x64}, want: "CALLM #$64, (A0)"},
+	}
+
+	for _, tc := range testCases {
+		inst, err := Decode(tc.data, 0)
+		if err != nil {
+			t.Fatalf("Decode-Fehler für % X: %v", tc.data, err)
+		}
+		if got := inst.Assembly(); got != tc.want {
+			t.Errorf("Unerwartete Assembly für % X: %q, erwartet %q", tc.data, got, tc.want)
+		}
+	}
+}
+
 func TestDecodeReturnsStructuredMetadataAndExtensionWords(t *testing.T) {
 	data := []byte{0x20, 0x7C, 0x00, 0x00, 0x21, 0x40} // MOVEA.L #$00002140, A0
 
@@ -268,31 +903,542 @@ func TestDecodeReturnsStructuredMetadataAndExtensionWords(t *testing.T) {
 		t.Fatalf("Decode-Fehler: %v", err)
 	}
 
-	if inst.Size != 6 {
-		t.Fatalf("Erwartete Instruktionsgröße 6, erhielt %d", inst.Size)
+	if inst.Size != 6 {
+		t.Fatalf("Erwartete Instruktionsgröße 6, erhielt %d", inst.Size)
+	}
+	if len(inst.ExtensionWords) != 2 || inst.ExtensionWords[0] != 0x0000 || inst.ExtensionWords[1] != 0x2140 {
+		t.Fatalf("Unerwartete Extension-Wörter: %#v", inst.ExtensionWords)
+	}
+	if inst.Metadata.MnemonicBase != "MOVEA" || inst.Metadata.SizeSuffix != "L" {
+		t.Fatalf("Unerwartete Metadaten für Mnemonic: %+v", inst.Metadata)
+	}
+	if len(inst.Metadata.Operands) != 2 {
+		t.Fatalf("Erwartete 2 Operanden, erhielt %d", len(inst.Metadata.Operands))
+	}
+	src := inst.Metadata.Operands[0]
+	if src.Kind != OperandKindEffectiveAddr || src.EffectiveAddress == nil || src.EffectiveAddress.Kind != EAKindImmediate {
+		t.Fatalf("Quelloperand wurde nicht als Immediate-EA dekodiert: %+v", src)
+	}
+	if src.EffectiveAddress.Immediate == nil || src.EffectiveAddress.Immediate.Value != 0x2140 {
+		t.Fatalf("Immediate-Metadaten fehlen: %+v", src.EffectiveAddress)
+	}
+	dst := inst.Metadata.Operands[1]
+	if dst.Kind != OperandKindRegister || dst.Register == nil || dst.Register.Kind != RegisterKindAddress || dst.Register.Number != 0 {
+		t.Fatalf("Zieloperand wurde nicht als A0 dekodiert: %+v", dst)
+	}
+	if len(inst.Metadata.ImmediateValues) != 1 || inst.Metadata.ImmediateValues[0].Value != 0x2140 {
+		t.Fatalf("Unerwartete Immediate-Liste: %+v", inst.Metadata.ImmediateValues)
+	}
+}
+
+func TestDecodeDBccMetadata(t *testing.T) {
+	inst, err := Decode([]byte{0x51, 0xCB, 0x00, 0x10}, 0x4000) // DBRA D3, $4012
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if inst.Metadata.MnemonicBase != "DBRA" || inst.Size != 4 {
+		t.Fatalf("Unerwartete Metadaten: %+v", inst.Metadata)
+	}
+	if inst.Metadata.BranchTarget == nil || *inst.Metadata.BranchTarget != 0x4012 {
+		t.Fatalf("Unerwartetes Sprungziel: %+v", inst.Metadata.BranchTarget)
+	}
+	if len(inst.Metadata.Operands) != 2 {
+		t.Fatalf("Erwartete 2 Operanden, erhielt %d", len(inst.Metadata.Operands))
+	}
+	counter := inst.Metadata.Operands[0]
+	if counter.Kind != OperandKindRegister || counter.Register.Kind != RegisterKindData || counter.Register.Number != 3 {
+		t.Fatalf("Zähleroperand wurde nicht als D3 dekodiert: %+v", counter)
+	}
+	if target := inst.Metadata.Operands[1]; target.Kind != OperandKindBranchTarget {
+		t.Fatalf("Zieloperand ist kein Sprungziel: %+v", target)
+	}
+}
+
+func TestDecodeADDXPredecrementMetadata(t *testing.T) {
+	inst, err := Decode([]byte{0xD3, 0x88}, 0) // ADDX.L -(A0), -(A1)
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if inst.Metadata.MnemonicBase != "ADDX" || inst.Metadata.SizeSuffix != "L" {
+		t.Fatalf("Unerwartete Metadaten: %+v", inst.Metadata)
+	}
+	for i, want := range []uint8{0, 1} {
+		operand := inst.Metadata.Operands[i]
+		if operand.EffectiveAddress == nil || operand.EffectiveAddress.Kind != EAKindPreDecrement || operand.EffectiveAddress.Register != want {
+			t.Fatalf("Operand %d ist kein -(A%d): %+v", i, want, operand)
+		}
+	}
+}
+
+func TestDecodeFullExtensionWordMetadata(t *testing.T) {
+	data := []byte{0x32, 0x30, 0x1D, 0x26, 0x00, 0x10, 0x00, 0x08} // MOVE.W ([16,A0],D1.L*4,8), D1
+	inst, err := Decode(data, 0)
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if inst.Size != 8 || len(inst.ExtensionWords) != 3 {
+		t.Fatalf("Unerwartete Größe oder Extension-Wörter: %d %v", inst.Size, inst.ExtensionWords)
+	}
+	ea := inst.Metadata.Operands[0].EffectiveAddress
+	if ea == nil || ea.Kind != EAKindIndex || ea.Indirection != IndirectionPostIndexed {
+		t.Fatalf("Unerwartete EA-Metadaten: %+v", ea)
+	}
+	if ea.Base == nil || ea.Base.Number != 0 || ea.Index == nil || ea.Index.Register.Number != 1 || ea.Index.Size != "L" || ea.Scale != 4 {
+		t.Fatalf("Unerwartete Basis-/Index-Metadaten: %+v", ea)
+	}
+	if ea.Displacement == nil || *ea.Displacement != 16 || ea.OuterDisplacement == nil || *ea.OuterDisplacement != 8 {
+		t.Fatalf("Unerwartete Displacements: %+v", ea)
+	}
+
+	inst, err = Decode([]byte{0x30, 0x30, 0x19, 0x90}, 0) // MOVE.W (D1.L), D0
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	ea = inst.Metadata.Operands[0].EffectiveAddress
+	if ea.Base != nil || ea.Displacement != nil || ea.Indirection != IndirectionNone {
+		t.Fatalf("Unterdrückte Basis wurde nicht erkannt: %+v", ea)
+	}
+}
+
+func TestDecodeStatusRegisterOperands(t *testing.T) {
+	inst, err := Decode([]byte{0x00, 0x7C, 0x07, 0x00}, 0) // ORI #$0700, SR
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if len(inst.Metadata.Operands) != 2 {
+		t.Fatalf("Erwartete 2 Operanden, erhielt %d", len(inst.Metadata.Operands))
+	}
+	imm := inst.Metadata.Operands[0]
+	if imm.Kind != OperandKindImmediate || imm.Immediate.Value != 0x0700 || imm.Immediate.Size != 2 {
+		t.Fatalf("Unerwarteter Immediate-Operand: %+v", imm)
+	}
+	sr := inst.Metadata.Operands[1]
+	if sr.Kind != OperandKindRegister || sr.Register.Kind != RegisterKindSystem || sr.Register.Name != "SR" {
+		t.Fatalf("Zieloperand wurde nicht als SR dekodiert: %+v", sr)
+	}
+}
+
+func TestDecodeMOVECControlRegisterOperand(t *testing.T) {
+	inst, err := Decode([]byte{0x4E, 0x7B, 0x08, 0x01}, 0) // MOVEC D0, VBR
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	ctrl := inst.Metadata.Operands[1]
+	if ctrl.Kind != OperandKindRegister || ctrl.Register.Kind != RegisterKindControl || ctrl.Register.Name != "VBR" {
+		t.Fatalf("Zieloperand wurde nicht als VBR dekodiert: %+v", ctrl)
+	}
+	if inst.Size != 4 || len(inst.ExtensionWords) != 1 || inst.ExtensionWords[0] != 0x0801 {
+		t.Fatalf("Unerwartete Größe oder Extension-Wörter: %d %v", inst.Size, inst.ExtensionWords)
+	}
+}
+
+func TestDecodeBitfieldAndRegisterPairMetadata(t *testing.T) {
+	inst, err := Decode([]byte{0xE9, 0xD0, 0x19, 0xA3}, 0) // BFEXTU (A0){D6:D3}, D1
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	field := inst.Metadata.Operands[0].Bitfield
+	if field == nil || field.OffsetRegister == nil || field.OffsetRegister.Number != 6 || field.WidthRegister == nil || field.WidthRegister.Number != 3 {
+		t.Fatalf("Bitfeld-Angabe fehlt oder ist falsch: %+v", field)
+	}
+	if got := inst.Assembly(); got != "BFEXTU (A0){D6:D3}, D1" {
+		t.Fatalf("Unerwartete Assembly: %s", got)
+	}
+
+	inst, err = Decode([]byte{0x4C, 0x01, 0x24, 0x03}, 0) // MULU.L D1, D3:D2
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	pair := inst.Metadata.Operands[1]
+	if pair.Kind != OperandKindRegisterPair || pair.RegisterPair.First.Number != 3 || pair.RegisterPair.Second.Number != 2 {
+		t.Fatalf("Registerpaar wurde nicht dekodiert: %+v", pair)
+	}
+}
+
+func TestDecodeFPUOperandMetadata(t *testing.T) {
+	inst, err := Decode([]byte{0xF2, 0x3C, 0x44, 0x80, 0x3F, 0xC0, 0x00, 0x00}, 0) // FMOVE.S #1.5, FP1
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if len(inst.Metadata.ImmediateValues) != 1 || inst.Metadata.ImmediateValues[0].Float == nil || *inst.Metadata.ImmediateValues[0].Float != 1.5 {
+		t.Fatalf("Gleitkomma-Immediate fehlt: %+v", inst.Metadata.ImmediateValues)
+	}
+	dst := inst.Metadata.Operands[1]
+	if dst.Register == nil || dst.Register.Kind != RegisterKindFP || dst.Register.Number != 1 {
+		t.Fatalf("FP-Register wurde nicht dekodiert: %+v", dst)
+	}
+
+	inst, err = Decode([]byte{0xF2, 0x10, 0x90, 0x00}, 0) // FMOVE.L (A0), FPCR
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	ctrl := inst.Metadata.Operands[1]
+	if ctrl.Register == nil || ctrl.Register.Kind != RegisterKindFPControl || ctrl.Register.Name != "FPCR" {
+		t.Fatalf("FPCR wurde nicht dekodiert: %+v", ctrl)
+	}
+}
+
+func TestDecodeMMURegisterOperand(t *testing.T) {
+	inst, err := Decode([]byte{0xF0, 0x10, 0x4A, 0x00}, 0) // PMOVE SRP, (A0)
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got := inst.Assembly(); got != "PMOVE SRP, (A0)" {
+		t.Fatalf("Unerwartete Assembly: %s", got)
+	}
+	srp := inst.Metadata.Operands[0]
+	if srp.Register == nil || srp.Register.Kind != RegisterKindMMU || srp.Register.Name != "SRP" {
+		t.Fatalf("SRP wurde nicht als MMU-Register dekodiert: %+v", srp)
+	}
+}
+
+func TestDecodeCPUGatesInstructionSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		cpu    CPU
+		data   []byte
+		want   string
+		reason string
+	}{
+		{name: "bitfield on 68000", cpu: CPU68000, data: []byte{0xE9, 0xD0, 0x11, 0x08}, want: "DC.W $E9D0", reason: "not available on 68000 (requires 68020 full ISA)"},
+		{name: "bitfield on 68030", cpu: CPU68030, data: []byte{0xE9, 0xD0, 0x11, 0x08}, want: "BFEXTU (A0){4:8}, D1"},
+		{name: "MOVEC on 68000", cpu: CPU68000, data: []byte{0x4E, 0x7A, 0x08, 0x01}, want: "DC.W $4E7A", reason: "not available on 68000 (requires 68010)"},
+		{name: "MOVEC VBR on 68010", cpu: CPU68010, data: []byte{0x4E, 0x7A, 0x08, 0x01}, want: "MOVEC VBR, D0"},
+		{name: "MOVEC CACR on 68010", cpu: CPU68010, data: []byte{0x4E, 0x7A, 0x00, 0x02}, want: "DC.W $4E7A", reason: "not available on 68010 (requires 68020 full ISA)"},
+		{name: "MOVEC URP on 68030", cpu: CPU68030, data: []byte{0x4E, 0x7A, 0x08, 0x06}, want: "DC.W $4E7A", reason: "not available on 68030 (requires 68040)"},
+		{name: "MOVEC CAAR on 68030", cpu: CPU68030, data: []byte{0x4E, 0x7B, 0x08, 0x02}, want: "MOVEC D0, CAAR"},
+		{name: "MOVEC CAAR on 68040", cpu: CPU68040, data: []byte{0x4E, 0x7B, 0x08, 0x02}, want: "DC.W $4E7B", reason: "not available on 68040 (requires 68020/68030 CAAR)"},
+		{name: "MOVEC CAAR on 68060", cpu: CPU68060, data: []byte{0x4E, 0x7B, 0x08, 0x02}, want: "DC.W $4E7B", reason: "not available on 68060 (requires 68020/68030 CAAR)"},
+		{name: "MOVEC MSP on 68040", cpu: CPU68040, data: []byte{0x4E, 0x7B, 0x08, 0x03}, want: "MOVEC D0, MSP"},
+		{name: "MOVEC MSP on 68060", cpu: CPU68060, data: []byte{0x4E, 0x7B, 0x08, 0x03}, want: "DC.W $4E7B", reason: "not available on 68060 (requires 68020-68040 MSP/ISP)"},
+		{name: "MOVEC MMUSR on 68060", cpu: CPU68060, data: []byte{0x4E, 0x7A, 0x08, 0x05}, want: "DC.W $4E7A", reason: "not available on 68060 (requires 68040 PTEST/MMUSR)"},
+		{name: "FPU on 68000 traps as Line-F", cpu: CPU68000, data: []byte{0xF2, 0x00, 0x04, 0x22}, want: "LINEF #$0200"},
+		{name: "PMMU on 68040 traps as Line-F", cpu: CPU68040, data: []byte{0xF0, 0x10, 0x42, 0x00}, want: "LINEF #16"},
+		{name: "PMOVE CAL on 68020", cpu: CPU68020, data: []byte{0xF0, 0x10, 0x50, 0x00}, want: "PMOVE (A0), CAL"},
+		{name: "PMOVE CAL on 68030", cpu: CPU68030, data: []byte{0xF0, 0x10, 0x50, 0x00}, want: "DC.W $F010", reason: "not available on 68030 (requires 68851)"},
+		{name: "PMOVE PCSR on 68030", cpu: CPU68030, data: []byte{0xF0, 0x10, 0x66, 0x00}, want: "DC.W $F010", reason: "not available on 68030 (requires 68851)"},
+		{name: "PFLUSHS on 68030", cpu: CPU68030, data: []byte{0xF0, 0x00, 0x34, 0xF5}, want: "DC.W $F000", reason: "not available on 68030 (requires 68851)"},
+		{name: "PMOVE TT0 on 68030", cpu: CPU68030, data: []byte{0xF0, 0x10, 0x08, 0x00}, want: "PMOVE (A0), TT0"},
+		{name: "PMOVE TT0 on 68020", cpu: CPU68020, data: []byte{0xF0, 0x10, 0x08, 0x00}, want: "DC.W $F010", reason: "not available on 68020 (requires 68030 MMU)"},
+		{name: "PMOVEFD on 68020", cpu: CPU68020, data: []byte{0xF0, 0x10, 0x41, 0x00}, want: "DC.W $F010", reason: "not available on 68020 (requires 68030 MMU)"},
+		{name: "PMOVE TC on 68020", cpu: CPU68020, data: []byte{0xF0, 0x10, 0x40, 0x00}, want: "PMOVE (A0), TC"},
+		{name: "CALLM only on 68020", cpu: CPU68030, data: []byte{0x06, 0xD0, 0x00, 0x04}, want: "DC.W $06D0", reason: "not available on 68030 (requires 68020 CALLM/RTM)"},
+		{name: "TST An on 68000", cpu: CPU68000, data: []byte{0x4A, 0x49}, want: "DC.W $4A49", reason: "not available on 68000 (requires 68020)"},
+		{name: "TST An on CPU32", cpu: CPU32, data: []byte{0x4A, 0x49}, want: "TST.W A1"},
+		{name: "Bcc.L on 68010", cpu: CPU68010, data: []byte{0x60, 0xFF, 0x00, 0x00, 0x00, 0x10}, want: "DC.W $60FF", reason: "not available on 68010 (requires 68020)"},
+		{name: "scaled index on 68000", cpu: CPU68000, data: []byte{0x20, 0x30, 0x12, 0x04}, want: "DC.W $2030", reason: "not available on 68000 (requires 68020)"},
+		{name: "full extension word on CPU32", cpu: CPU32, data: []byte{0x20, 0x30, 0x01, 0x20, 0x12, 0x34}, want: "DC.W $2030", reason: "not available on CPU32 (requires 68020 full ISA)"},
+		{name: "plain index on 68000", cpu: CPU68000, data: []byte{0x20, 0x30, 0x10, 0x04}, want: "MOVE.L (4,A0,D1.W), D0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inst, err := DecodeWithOptions(tc.data, 0, DecodeOptions{CPU: tc.cpu})
+			if err != nil {
+				t.Fatalf("Decode-Fehler: %v", err)
+			}
+			if got := inst.Assembly(); got != tc.want {
+				t.Fatalf("Unerwartete Assembly: %s", got)
+			}
+			if inst.Metadata.UnavailableReason != tc.reason {
+				t.Fatalf("Unerwarteter Grund: %q", inst.Metadata.UnavailableReason)
+			}
+		})
+	}
+}
+
+func TestDecodeCPU32Extensions(t *testing.T) {
+	opts := DecodeOptions{CPU: CPU32}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "TBLS memory", data: []byte{0xF8, 0x10, 0x18, 0x40}, want: "TBLS.W (A0), D1"},
+		{name: "TBLUN register", data: []byte{0xF8, 0x02, 0x45, 0x03}, want: "TBLUN.B D2:D3, D4"},
+		{name: "LPSTOP", data: []byte{0xF8, 0x00, 0x01, 0xC0, 0x27, 0x00}, want: "LPSTOP #$2700"},
+		{name: "CAS2 rejected", data: []byte{0x0C, 0xFC, 0x80, 0x40, 0x90, 0xC1}, want: "DC.W $0CFC"},
+		{name: "bitfield rejected", data: []byte{0xE8, 0xC0, 0x00, 0x08}, want: "DC.W $E8C0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inst, err := DecodeWithOptions(tc.data, 0, opts)
+			if err != nil {
+				t.Fatalf("Decode-Fehler: %v", err)
+			}
+			if got := inst.Assembly(); got != tc.want {
+				t.Fatalf("Unerwartete Assembly: %s", got)
+			}
+		})
+	}
+
+	inst, err := DecodeWithOptions([]byte{0xF8, 0x02, 0x45, 0x03}, 0, opts)
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	pair := inst.Metadata.Operands[0].RegisterPair
+	if pair == nil || pair.First.Number != 2 || pair.Second.Number != 3 || pair.Indirect {
+		t.Fatalf("Registerpaar fehlt: %+v", inst.Metadata.Operands[0])
+	}
+
+	inst, err = Decode([]byte{0xF8, 0x10, 0x18, 0x40}, 0)
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if inst.Metadata.MnemonicBase != "LINEF" {
+		t.Fatalf("TBL ohne CPU32-Profil dekodiert: %s", inst.Assembly())
+	}
+
+	for _, data := range [][]byte{
+		{0xF8, 0x10, 0x19, 0x40}, // Registerform-Bit mit (A0)
+		{0xF8, 0x02, 0x44, 0x03}, // Dn ohne Registerform-Bit
+	} {
+		if inst, err := DecodeWithOptions(data, 0, opts); err == nil {
+			t.Fatalf("% X: Fehler erwartet, erhielt %s", data, inst.Assembly())
+		}
+	}
+}
+
+func TestDecodeColdFireProfiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		cpu    CPU
+		data   []byte
+		want   string
+		reason string
+	}{
+		{name: "ADD.L", cpu: CPUColdFireISAA, data: []byte{0xD2, 0x80}, want: "ADD.L D0, D1"},
+		{name: "ADD.W rejected", cpu: CPUColdFireISAA, data: []byte{0xD2, 0x40}, want: "DC.W $D240", reason: "not available on ColdFire ISA_A (requires 680x0)"},
+		{name: "ANDI.L", cpu: CPUColdFireISAA, data: []byte{0x02, 0x80, 0x00, 0x00, 0x00, 0xFF}, want: "ANDI.L #$000000FF, D0"},
+		{name: "ABCD rejected", cpu: CPUColdFireISAA, data: []byte{0xC3, 0x00}, want: "DC.W $C300", reason: "not available on ColdFire ISA_A (requires 680x0)"},
+		{name: "MOVEM predecrement rejected", cpu: CPUColdFireISAA, data: []byte{0x48, 0xE7, 0xC0, 0x00}, want: "DC.W $48E7", reason: "not available on ColdFire ISA_A (requires 680x0)"},
+		{name: "MOVEM indirect", cpu: CPUColdFireISAA, data: []byte{0x48, 0xD7, 0x00, 0x03}, want: "MOVEM.L D0-D1, (A7)"},
+		{name: "MOVE.L #imm to displacement", cpu: CPUColdFireISAC, data: []byte{0x21, 0x7C, 0x00, 0x00, 0x00, 0x01, 0x00, 0x04}, want: "DC.W $217C", reason: "not available on ColdFire ISA_C (requires 680x0)"},
+		{name: "MOVE.W #imm to displacement on ISA_A", cpu: CPUColdFireISAA, data: []byte{0x31, 0x7C, 0x00, 0x01, 0x00, 0x04}, want: "DC.W $317C", reason: "not available on ColdFire ISA_A (requires ColdFire ISA_B)"},
+		{name: "MOVE.W #imm to displacement on ISA_B", cpu: CPUColdFireISAB, data: []byte{0x31, 0x7C, 0x00, 0x01, 0x00, 0x04}, want: "MOVE.W #1, (4,A0)"},
+		{name: "CMP.B on ISA_A", cpu: CPUColdFireISAA, data: []byte{0xB2, 0x00}, want: "DC.W $B200", reason: "not available on ColdFire ISA_A (requires ColdFire ISA_B)"},
+		{name: "CMP.B on ISA_B", cpu: CPUColdFireISAB, data: []byte{0xB2, 0x00}, want: "CMP.B D0, D1"},
+		{name: "scale 8 rejected", cpu: CPUColdFireISAA, data: []byte{0x20, 0x30, 0x0E, 0x04}, want: "DC.W $2030", reason: "not available on ColdFire ISA_A (requires 680x0)"},
+		{name: "word index rejected", cpu: CPUColdFireISAA, data: []byte{0x30, 0x30, 0x14, 0x00}, want: "DC.W $3030", reason: "not available on ColdFire ISA_A (requires 680x0)"},
+		{name: "long index", cpu: CPUColdFireISAA, data: []byte{0x30, 0x30, 0x1C, 0x00}, want: "MOVE.W (0,A0,D1.L*4), D0"},
+		{name: "MOVEC read rejected", cpu: CPUColdFireISAA, data: []byte{0x4E, 0x7A, 0x08, 0x01}, want: "DC.W $4E7A", reason: "not available on ColdFire ISA_A (requires 680x0)"},
+		{name: "MOVEC write", cpu: CPUColdFireISAA, data: []byte{0x4E, 0x7B, 0x08, 0x01}, want: "MOVEC D0, VBR"},
+		{name: "MOVEC CACR", cpu: CPUColdFireISAA, data: []byte{0x4E, 0x7B, 0x00, 0x02}, want: "MOVEC D0, CACR"},
+		{name: "MOVEC ACR0", cpu: CPUColdFireISAA, data: []byte{0x4E, 0x7B, 0x00, 0x04}, want: "MOVEC D0, ACR0"},
+		{name: "MOVEC RAMBAR", cpu: CPUColdFireISAB, data: []byte{0x4E, 0x7B, 0x0C, 0x05}, want: "MOVEC D0, RAMBAR1"},
+		{name: "MOVEC MBAR", cpu: CPUColdFireISAC, data: []byte{0x4E, 0x7B, 0x8C, 0x0F}, want: "MOVEC A0, MBAR"},
+		{name: "MOVEC ITT0 on 68040", cpu: CPU68040, data: []byte{0x4E, 0x7B, 0x00, 0x04}, want: "MOVEC D0, ITT0"},
+		{name: "REMS.L", cpu: CPUColdFireISAA, data: []byte{0x4C, 0x41, 0x28, 0x03}, want: "REMS.L D1, D3:D2"},
+		{name: "DIVS.L", cpu: CPUColdFireISAA, data: []byte{0x4C, 0x41, 0x28, 0x02}, want: "DIVS.L D1, D2"},
+		{name: "MOV3Q", cpu: CPUColdFireISAB, data: []byte{0xA1, 0x40}, want: "MOV3Q.L #-$1, D0"},
+		{name: "MOV3Q on ISA_A traps", cpu: CPUColdFireISAA, data: []byte{0xA1, 0x40}, want: "LINEA #$0140"},
+		{name: "MVS.W", cpu: CPUColdFireISAB, data: []byte{0x73, 0x50}, want: "MVS.W (A0), D1"},
+		{name: "MVZ.B", cpu: CPUColdFireISAB, data: []byte{0x77, 0x82}, want: "MVZ.B D2, D3"},
+		{name: "SATS", cpu: CPUColdFireISAB, data: []byte{0x4C, 0x82}, want: "SATS.L D2"},
+		{name: "BITREV", cpu: CPUColdFireISAC, data: []byte{0x00, 0xC0}, want: "BITREV.L D0"},
+		{name: "BYTEREV", cpu: CPUColdFireISAC, data: []byte{0x02, 0xC1}, want: "BYTEREV.L D1"},
+		{name: "FF1", cpu: CPUColdFireISAC, data: []byte{0x04, 0xC2}, want: "FF1.L D2"},
+		{name: "MAC.W", cpu: CPUColdFireISAA, data: []byte{0xA4, 0x01, 0x02, 0x40}, want: "MAC.W D1.U, D2.L, <<, ACC0"},
+		{name: "MAC.L with load", cpu: CPUColdFireISAA, data: []byte{0xA6, 0x98, 0x28, 0x01}, want: "MAC.L D1, D2, (A0)+, D3, ACC1"},
+		{name: "MOVE to ACC", cpu: CPUColdFireISAA, data: []byte{0xA5, 0x00}, want: "MOVE.L D0, ACC2"},
+		{name: "MOVE from ACC", cpu: CPUColdFireISAA, data: []byte{0xA3, 0x83}, want: "MOVE.L ACC1, D3"},
+		{name: "MOVCLR", cpu: CPUColdFireISAA, data: []byte{0xA1, 0xC9}, want: "MOVCLR.L ACC0, A1"},
+		{name: "MOVE to MASK", cpu: CPUColdFireISAA, data: []byte{0xAD, 0x3C, 0x00, 0x00, 0xFF, 0xFF}, want: "MOVE.L #$0000FFFF, MASK"},
+		{name: "MOVE MACSR to CCR", cpu: CPUColdFireISAA, data: []byte{0xA9, 0xC0}, want: "MOVE.L MACSR, CCR"},
+		{name: "MAC on 68000 traps", cpu: CPU68000, data: []byte{0xA4, 0x01, 0x02, 0x40}, want: "LINEA #$0401"},
+		{name: "SATS on 68000 is MOVEM", cpu: CPU68000, data: []byte{0x4C, 0x90, 0x00, 0x03}, want: "MOVEM.W (A0), D0-D1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inst, err := DecodeWithOptions(tc.data, 0, DecodeOptions{CPU: tc.cpu})
+			if err != nil {
+				t.Fatalf("Decode-Fehler: %v", err)
+			}
+			if got := inst.Assembly(); got != tc.want {
+				t.Fatalf("Unerwartete Assembly: %s", got)
+			}
+			if inst.Metadata.UnavailableReason != tc.reason {
+				t.Fatalf("Unerwarteter Grund: %q", inst.Metadata.UnavailableReason)
+			}
+		})
+	}
+}
+
+func TestDecodeColdFireMACRegisterMetadata(t *testing.T) {
+	inst, err := DecodeWithOptions([]byte{0xA6, 0x98, 0x28, 0x01}, 0, DecodeOptions{CPU: CPUColdFireISAA})
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	operands := inst.Metadata.Operands
+	if len(operands) != 5 {
+		t.Fatalf("Unerwartete Operanden: %+v", operands)
+	}
+	if ea := operands[2].EffectiveAddress; ea == nil || ea.Kind != EAKindPostIncrement {
+		t.Fatalf("Lade-Adresse fehlt: %+v", operands[2])
+	}
+	acc := operands[4].Register
+	if acc == nil || acc.Kind != RegisterKindMAC || acc.Name != "ACC1" {
+		t.Fatalf("Akkumulator wurde nicht als MAC-Register dekodiert: %+v", operands[4])
+	}
+}
+
+func TestDecodeSyntaxDialects(t *testing.T) {
+	tests := []struct {
+		data   []byte
+		cpu    CPU
+		mit    string
+		devpac string
+	}{
+		{[]byte{0x20, 0x28, 0x00, 0x04}, CPUAny, "movel %a0@(4),%d0", "move.l 4(a0),d0"},
+		{[]byte{0x20, 0x30, 0x0A, 0x02}, CPUAny, "movel %a0@(2,%d0:l:2),%d0", "move.l 2(a0,d0.l*2),d0"},
+		{[]byte{0x20, 0x30, 0x01, 0x26, 0x00, 0x10, 0x00, 0x08}, CPUAny, "movel %a0@(16)@(8,%d0:w),%d0", "move.l ([16,a0],d0.w,8),d0"},
+		{[]byte{0x20, 0x3B, 0x00, 0x10}, CPUAny, "movel %pc@(16,%d0:w),%d0", "move.l 16(pc,d0.w),d0"},
+		{[]byte{0x20, 0x39, 0x00, 0x01, 0x23, 0x45}, CPUAny, "movel 0x12345,%d0", "move.l $00012345.l,d0"},
+		{[]byte{0x20, 0x38, 0x12, 0x34}, CPUAny, "movel 0x1234:w,%d0", "move.l $1234.w,d0"},
+		{[]byte{0x4C, 0xDF, 0x7F, 0xFF}, CPUAny, "moveml %sp@+,%d0-%d7/%a0-%a6", "movem.l (a7)+,d0-d7/a0-a6"},
+		{[]byte{0x4E, 0x56, 0xFF, 0xF8}, CPUAny, "link %fp,#-0x8", "link a6,#-$8"},
+		{[]byte{0x46, 0xFC, 0x27, 0x00}, CPUAny, "movew #0x2700,%sr", "move.w #$2700,sr"},
+		{[]byte{0x67, 0x00, 0x00, 0x10}, CPUAny, "beqw 0x1014", "beq.w $1014"},
+		{[]byte{0xE9, 0xC0, 0x01, 0x24}, CPUAny, "bfextu %d0{4:%d4},%d0", "bfextu d0{4:d4},d0"},
+		{[]byte{0xF2, 0x3C, 0x44, 0x00, 0x3F, 0xC0, 0x00, 0x00}, CPUAny, "fmoves #0r1.5,%fp0", "fmove.s #1.5,fp0"},
+		{[]byte{0xF2, 0x10, 0x7C, 0x30}, CPUAny, "fmovep %fp0,%a0@{%d3}", "fmove.p fp0,(a0){d3}"},
+		{[]byte{0x4A, 0xFC}, CPUAny, "illegal", "illegal"},
+		{[]byte{0x4E, 0x7B, 0x08, 0x01}, CPU68000, ".short 0x4e7b", "dc.w $4e7b"},
+		{[]byte{0xA4, 0x90, 0x2E, 0xA1}, CPUColdFireISAA, "macl %d1,%d2,>>,%a0@&,%d2,%acc1", "mac.l d1,d2,>>,(a0)&,d2,acc1"},
+	}
+
+	for _, tt := range tests {
+		for _, dialect := range []struct {
+			syntax Syntax
+			want   string
+		}{{SyntaxMIT, tt.mit}, {SyntaxDevpac, tt.devpac}} {
+			inst, err := DecodeWithOptions(tt.data, 0x1000, DecodeOptions{CPU: tt.cpu, Syntax: dialect.syntax})
+			if err != nil {
+				t.Fatalf("Decode-Fehler für % X: %v", tt.data, err)
+			}
+			if got := inst.Assembly(); got != dialect.want {
+				t.Errorf("%s: Unerwartete Assembly für % X: %q, erwartet %q", dialect.syntax, tt.data, got, dialect.want)
+			}
+		}
+	}
+
+	symbolizer := SymbolizeFunc(func(address uint32) (string, bool) {
+		return "loop", address == 0x1014
+	})
+	inst, err := DecodeWithOptions([]byte{0x67, 0x00, 0x00, 0x10}, 0x1000, DecodeOptions{Symbolizer: symbolizer, Syntax: SyntaxMIT})
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got := inst.Assembly(); got != "beqw loop" {
+		t.Fatalf("Unerwartete Assembly: %s", got)
+	}
+	if inst.Metadata.Mnemonic != "BEQ.W" {
+		t.Fatalf("Metadaten dürfen nicht vom Dialekt abhängen: %+v", inst.Metadata)
+	}
+}
+
+// stackFormatter schreibt A7 als SP, Immediates mit 0x und trennt Operanden
+// ohne Leerzeichen.
+type stackFormatter struct {
+	SyntaxFormatter
+}
+
+func (f stackFormatter) FormatRegister(operand Operand) string {
+	if operand.Register.Kind == RegisterKindAddress && operand.Register.Number == 7 {
+		return "SP"
+	}
+	return f.SyntaxFormatter.FormatRegister(operand)
+}
+
+func (stackFormatter) FormatImmediateAddress(operand Operand) string {
+	return fmt.Sprintf("#0x%x", operand.EffectiveAddress.Immediate.Value)
+}
+
+func (stackFormatter) Separator() string {
+	return ","
+}
+
+func TestDecodeFormatterOverridesCallbacks(t *testing.T) {
+	opts := DecodeOptions{Formatter: stackFormatter{}}
+	inst, err := DecodeWithOptions([]byte{0x2E, 0x7C, 0x12, 0x34, 0x56, 0x78}, 0, opts)
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got := inst.Assembly(); got != "MOVEA.L #0x12345678,SP" {
+		t.Fatalf("Unerwartete Assembly: %s", got)
+	}
+	if inst.Metadata.Operands[1].Text != "A7" {
+		t.Fatalf("Formatter darf Operand.Text nicht ändern: %+v", inst.Metadata.Operands[1])
+	}
+
+	// Nicht überschriebene Callbacks rendern die Motorola-Syntax.
+	inst, err = DecodeWithOptions([]byte{0x20, 0x30, 0x0A, 0x02}, 0, opts)
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got := inst.Assembly(); got != "MOVE.L (2,A0,D0.L*2),D0" {
+		t.Fatalf("Unerwartete Assembly: %s", got)
+	}
+}
+
+func TestSyntaxFormatterMatchesDecoderText(t *testing.T) {
+	tests := [][]byte{
+		{0x20, 0x30, 0x01, 0x26, 0x00, 0x10, 0x00, 0x08},             // MOVE.L ([16,A0],D0.W,8), D0
+		{0x20, 0x3B, 0x00, 0x10},                                     // MOVE.L (16,PC,D0.W), D0
+		{0x4C, 0xDF, 0x0C, 0x04},                                     // MOVEM.L (A7)+, D2/A2-A3
+		{0x4E, 0x56, 0xFF, 0xF8},                                     // LINK A6, #-$8
+		{0x08, 0x00, 0x00, 0x80},                                     // BTST #$80, D0
+		{0xE9, 0xC0, 0x01, 0x24},                                     // BFEXTU D0{4:D4}, D0
+		{0xF2, 0x10, 0x7C, 0x30},                                     // FMOVE.P FP0, (A0){D3}
+		{0xF2, 0x3C, 0x44, 0x00, 0x3F, 0xC0, 0x00, 0x00},             // FMOVE.S #1.5, FP0
+		{0x0C, 0xB9, 0x12, 0x34, 0x56, 0x78, 0x00, 0x00, 0x12, 0x34}, // CMPI.L #$12345678, $00001234
+		{0x67, 0x00, 0x00, 0x10},                                     // BEQ.W $0012
 	}
-	if len(inst.ExtensionWords) != 2 || inst.ExtensionWords[0] != 0x0000 || inst.ExtensionWords[1] != 0x2140 {
-		t.Fatalf("Unerwartete Extension-Wörter: %#v", inst.ExtensionWords)
+	for _, data := range tests {
+		want, err := Decode(data, 0)
+		if err != nil {
+			t.Fatalf("Decode-Fehler für % X: %v", data, err)
+		}
+		got, err := DecodeWithOptions(data, 0, DecodeOptions{Formatter: SyntaxFormatter{}})
+		if err != nil {
+			t.Fatalf("Decode-Fehler für % X: %v", data, err)
+		}
+		if got.Assembly() != want.Assembly() {
+			t.Errorf("Formatter liefert %q, Decoder %q", got.Assembly(), want.Assembly())
+		}
 	}
-	if inst.Metadata.MnemonicBase != "MOVEA" || inst.Metadata.SizeSuffix != "L" {
-		t.Fatalf("Unerwartete Metadaten für Mnemonic: %+v", inst.Metadata)
+}
+
+func TestDecodeTrapNamerRendersPlatformNames(t *testing.T) {
+	namer := TrapNameFunc(func(opcode uint16) (string, bool) {
+		if opcode == 0xA11E {
+			return "_NewPtr", true
+		}
+		return "", false
+	})
+
+	inst, err := DecodeWithOptions([]byte{0xA1, 0x1E}, 0, DecodeOptions{TrapNamer: namer})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions-Fehler: %v", err)
 	}
-	if len(inst.Metadata.Operands) != 2 {
-		t.Fatalf("Erwartete 2 Operanden, erhielt %d", len(inst.Metadata.Operands))
+	if got := inst.Assembly(); got != "_NewPtr" {
+		t.Fatalf("Unerwartete Assembly: %s", got)
 	}
-	src := inst.Metadata.Operands[0]
-	if src.Kind != OperandKindEffectiveAddr || src.EffectiveAddress == nil || src.EffectiveAddress.Kind != EAKindImmediate {
-		t.Fatalf("Quelloperand wurde nicht als Immediate-EA dekodiert: %+v", src)
+	if inst.Metadata.MnemonicBase != "LINEA" {
+		t.Fatalf("Unerwartete Metadaten: %+v", inst.Metadata)
 	}
-	if src.EffectiveAddress.Immediate == nil || src.EffectiveAddress.Immediate.Value != 0x2140 {
-		t.Fatalf("Immediate-Metadaten fehlen: %+v", src.EffectiveAddress)
+	if len(inst.Metadata.ImmediateValues) != 1 || inst.Metadata.ImmediateValues[0].Value != 0x11E {
+		t.Fatalf("Trap-Nummer fehlt in den Immediate-Werten: %+v", inst.Metadata.ImmediateValues)
 	}
-	dst := inst.Metadata.Operands[1]
-	if dst.Kind != OperandKindRegister || dst.Register == nil || dst.Register.Kind != RegisterKindAddress || dst.Register.Number != 0 {
-		t.Fatalf("Zieloperand wurde nicht als A0 dekodiert: %+v", dst)
+
+	inst, err = DecodeWithOptions([]byte{0xA0, 0x00}, 0, DecodeOptions{TrapNamer: namer})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions-Fehler: %v", err)
 	}
-	if len(inst.Metadata.ImmediateValues) != 1 || inst.Metadata.ImmediateValues[0].Value != 0x2140 {
-		t.Fatalf("Unerwartete Immediate-Liste: %+v", inst.Metadata.ImmediateValues)
+	if got := inst.Assembly(); got != "LINEA #0" {
+		t.Fatalf("Unbenannter Trap wurde verändert: %s", got)
 	}
 }
 
@@ -352,6 +1498,75 @@ func TestDecodePartialErrorsReportMissingBytes(t *testing.T) {
 	}
 }
 
+func TestDecodeRejectsIllegalEffectiveAddresses(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		mnemonic string
+		operand  string
+		kind     EffectiveAddressKind
+	}{
+		{
+			name:     "LEA D0, A1",
+			data:     []byte{0x43, 0xC0},
+			mnemonic: "LEA",
+			operand:  "source",
+			kind:     EAKindDataRegisterDirect,
+		},
+		{
+			name:     "CLR.W #5",
+			data:     []byte{0x42, 0x7C, 0x00, 0x05},
+			mnemonic: "CLR",
+			operand:  "destination",
+			kind:     EAKindImmediate,
+		},
+		{
+			name:     "MOVE.W D0, (12,PC)",
+			data:     []byte{0x35, 0xC0, 0x00, 0x0C},
+			mnemonic: "MOVE",
+			operand:  "destination",
+			kind:     EAKindPCDisplacement,
+		},
+		{
+			name:     "ORI.B #1, A0",
+			data:     []byte{0x00, 0x08, 0x00, 0x01},
+			mnemonic: "ORI",
+			operand:  "destination",
+			kind:     EAKindAddressRegisterDirect,
+		},
+		{
+			name:     "MOVE.B A0, D0",
+			data:     []byte{0x10, 0x08},
+			mnemonic: "MOVE",
+			operand:  "source",
+			kind:     EAKindAddressRegisterDirect,
+		},
+		{
+			name:     "JMP (A0)+",
+			data:     []byte{0x4E, 0xD8},
+			mnemonic: "JMP",
+			operand:  "destination",
+			kind:     EAKindPostIncrement,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(tc.data, 0x1000)
+			var illegal *IllegalEncodingError
+			if !errors.As(err, &illegal) {
+				t.Fatalf("Erwartete IllegalEncodingError, erhielt %v", err)
+			}
+			if illegal.Opcode != uint16(tc.data[0])<<8|uint16(tc.data[1]) || illegal.Address != 0x1000 {
+				t.Fatalf("Unerwartete Fehlerdaten: %+v", illegal)
+			}
+			if illegal.Mnemonic != tc.mnemonic || illegal.Operand != tc.operand || illegal.Kind != tc.kind {
+				t.Fatalf("Unerwartete Fehlerdaten: %+v", illegal)
+			}
+		})
+	}
+}
+
 func TestDecodeSymbolizerFormatsResolvedAddresses(t *testing.T) {
 	data := []byte{0x4E, 0xB9, 0x00, 0x00, 0x12, 0x34} // JSR $00001234
 
@@ -374,3 +1589,584 @@ func TestDecodeSymbolizerFormatsResolvedAddresses(t *testing.T) {
 		t.Fatalf("Rohoperand wurde unerwartet überschrieben: %+v", inst.Metadata.Operands[0])
 	}
 }
+
+func TestDecodeSymbolizerSignExtendsAbsoluteShort(t *testing.T) {
+	var looked []uint32
+	symbolizer := SymbolizeFunc(func(address uint32) (string, bool) {
+		looked = append(looked, address)
+		return "COLOR0", address == 0xFFFF8240
+	})
+	inst, err := DecodeWithOptions([]byte{0x31, 0xC0, 0x82, 0x40}, 0, DecodeOptions{Symbolizer: symbolizer}) // MOVE.W D0, $8240.W
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got := inst.Assembly(); got != "MOVE.W D0, COLOR0" {
+		t.Fatalf("Unerwartete Assembly: %s (angefragt: %X)", got, looked)
+	}
+
+	// Positive kurze Adressen bleiben unverändert.
+	inst, err = DecodeWithOptions([]byte{0x31, 0xC0, 0x12, 0x40}, 0, DecodeOptions{Symbolizer: symbolizer}) // MOVE.W D0, $1240.W
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got := inst.Assembly(); got != "MOVE.W D0, $1240" || looked[len(looked)-1] != 0x1240 {
+		t.Fatalf("Unerwartete Assembly: %s (angefragt: %X)", got, looked)
+	}
+}
+
+func TestDecodeResolvesPCRelativeTargets(t *testing.T) {
+	testCases := []struct {
+		data []byte
+		want uint32
+	}{
+		{data: []byte{0x41, 0xFA, 0x00, 0x0E}, want: 0x1010},             // LEA (14,PC), A0
+		{data: []byte{0x30, 0x3B, 0x10, 0x04}, want: 0x1006},             // MOVE.W (4,PC,D1.W), D0
+		{data: []byte{0x0C, 0x3A, 0x00, 0x01, 0xFF, 0xFE}, want: 0x1002}, // CMPI.B #1, (-2,PC)
+	}
+
+	for _, tc := range testCases {
+		inst, err := Decode(tc.data, 0x1000)
+		if err != nil {
+			t.Fatalf("Decode-Fehler: %v", err)
+		}
+		var resolved *uint32
+		for _, operand := range inst.Metadata.Operands {
+			if operand.EffectiveAddress != nil && isPCRelative(operand.EffectiveAddress.Kind) {
+				resolved = operand.EffectiveAddress.ResolvedAddress
+			}
+		}
+		if resolved == nil || *resolved != tc.want {
+			t.Fatalf("%s: unerwartetes PC-relatives Ziel: %v", inst.Assembly(), resolved)
+		}
+	}
+}
+
+func TestDecodePCRelativeStyles(t *testing.T) {
+	symbolizer := SymbolizeFunc(func(address uint32) (string, bool) {
+		return "table", address == 0x1010
+	})
+	lea := []byte{0x41, 0xFA, 0x00, 0x0E}     // LEA (14,PC), A0 -> $1010
+	indexed := []byte{0x30, 0x3B, 0x10, 0x0E} // MOVE.W (14,PC,D1.W), D0 -> $1010
+	scaled := []byte{0x30, 0x3B, 0x14, 0x0E}  // MOVE.W (14,PC,D1.W*4), D0 -> $1010
+	other := []byte{0x41, 0xFA, 0x00, 0x10}   // LEA (16,PC), A0 -> $1012
+	testCases := []struct {
+		data []byte
+		opts DecodeOptions
+		want string
+	}{
+		{data: lea, opts: DecodeOptions{Symbolizer: symbolizer}, want: "LEA (14,PC), A0"},
+		{data: lea, opts: DecodeOptions{Symbolizer: symbolizer, PCRelative: PCRelativeLabel}, want: "LEA table(PC), A0"},
+		{data: lea, opts: DecodeOptions{Symbolizer: symbolizer, PCRelative: PCRelativeLabel, Syntax: SyntaxMIT}, want: "lea %pc@(table),%a0"},
+		{data: lea, opts: DecodeOptions{Symbolizer: symbolizer, PCRelative: PCRelativeLabel, Syntax: SyntaxDevpac}, want: "lea table(pc),a0"},
+		{data: indexed, opts: DecodeOptions{Symbolizer: symbolizer, PCRelative: PCRelativeLabel}, want: "MOVE.W table(PC,D1.W), D0"},
+		{data: scaled, opts: DecodeOptions{Symbolizer: symbolizer, PCRelative: PCRelativeLabel, Syntax: SyntaxMIT}, want: "movew %pc@(table,%d1:w:4),%d0"},
+		{data: other, opts: DecodeOptions{Symbolizer: symbolizer, PCRelative: PCRelativeLabel}, want: "LEA (16,PC), A0"},
+	}
+	for _, tc := range testCases {
+		inst, err := DecodeWithOptions(tc.data, 0x1000, tc.opts)
+		if err != nil {
+			t.Fatalf("Decode-Fehler: %v", err)
+		}
+		if got := inst.Assembly(); got != tc.want {
+			t.Fatalf("Unerwartete Assembly: %s, erwartet %s", got, tc.want)
+		}
+		if inst.Comment != "" {
+			t.Fatalf("%s: unerwarteter Kommentar %q", inst.Assembly(), inst.Comment)
+		}
+	}
+
+	inst, err := DecodeWithOptions(indexed, 0x1000, DecodeOptions{PCRelative: PCRelativeComment})
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got, want := inst.String(), "00001000: MOVE.W (14,PC,D1.W), D0 ; =$1010"; got != want {
+		t.Fatalf("Unerwartete Ausgabe: %s", got)
+	}
+	if inst.Assembly() != "MOVE.W (14,PC,D1.W), D0" {
+		t.Fatalf("Kommentar in Assembly: %s", inst.Assembly())
+	}
+}
+
+func TestDecodeMarksNonCanonicalEncodings(t *testing.T) {
+	testCases := []struct {
+		data []byte
+		want bool
+	}{
+		{data: []byte{0x00, 0x00, 0x00, 0x20}, want: false},             // ORI.B #32, D0
+		{data: []byte{0x00, 0x00, 0x81, 0x20}, want: true},              // ORI.B mit gesetztem High-Byte
+		{data: []byte{0x30, 0x30, 0x11, 0x20, 0xFF, 0xFE}, want: true},  // (-2,A0,D1.W) im Full-Format
+		{data: []byte{0x30, 0x30, 0x11, 0x20, 0x10, 0x00}, want: false}, // (4096,A0,D1.W)
+		{data: []byte{0x32, 0x30, 0x1D, 0x26, 0x00, 0x10, 0x00, 0x08}, want: false},
+		{data: []byte{0x20, 0x30, 0x01, 0xA0, 0x00, 0x10}, want: false}, // (16,D0.W) mit unterdrücktem A0
+		{data: []byte{0x20, 0x33, 0x01, 0xA0, 0x00, 0x10}, want: true},  // (16,D0.W) mit unterdrücktem A3
+	}
+
+	for _, tc := range testCases {
+		inst, err := Decode(tc.data, 0)
+		if err != nil {
+			t.Fatalf("Decode-Fehler: %v", err)
+		}
+		if inst.Metadata.NonCanonical != tc.want {
+			t.Fatalf("%s: NonCanonical = %v", inst.Assembly(), inst.Metadata.NonCanonical)
+		}
+	}
+}
+
+// sourceProgram ist ein kleines Programm mit Sprüngen, PC-relativen und
+// absoluten Zielen, einer Datentabelle und nicht dekodierbaren Bytes.
+var sourceProgram = []byte{
+	0x41, 0xFA, 0x00, 0x12, // LEA (18,PC), A0 -> $1014
+	0x30, 0x3B, 0x00, 0x0E, // MOVE.W (14,PC,D0.W), D0 -> $1014
+	0x4A, 0x78, 0x10, 0x00, // TST.W $1000
+	0x66, 0xF2, // BNE.S $1000
+	0x4E, 0xB9, 0x00, 0x00, 0x10, 0x1C, // JSR $0000101C
+	0x00, 0x01, 0x00, 0x02, 0x12, 0x34, 0x56, 0x78, // Tabelle
+	0x4A, 0xFB, // unbekanntes Opcode-Wort
+	0x4E, 0x75, // RTS
+	0xAB, // einzelnes Byte
+}
+
+func TestWriteSourceEmitsLabelsAndData(t *testing.T) {
+	var out strings.Builder
+	err := WriteSource(&out, sourceProgram, 0x1000, SourceOptions{
+		CPU:  CPU68000,
+		Data: []AddressRange{{Start: 0x1014, End: 0x101C}},
+		Symbolizer: SymbolizeFunc(func(address uint32) (string, bool) {
+			switch address {
+			case 0x1000:
+				return "start", true
+			case 0xDFF180:
+				return "COLOR00", true
+			}
+			return "", false
+		}),
+	})
+	if err != nil {
+		t.Fatalf("WriteSource-Fehler: %v", err)
+	}
+
+	want := "\tORG\t$00001000\n" +
+		"start:\n" +
+		"\tLEA (L00001014,PC), A0\n" +
+		"\tMOVE.W (L00001014,PC,D0.W), D0\n" +
+		"\tTST.W start.W\n" +
+		"\tBNE.S start\n" +
+		"\tJSR L0000101C.L\n" +
+		"L00001014:\n" +
+		"\tDC.L\t$00010002,$12345678\n" +
+		"L0000101C:\n" +
+		"\tDC.W\t$4AFB\n" +
+		"\tRTS\n" +
+		"\tDC.B\t$AB\n"
+	if got := out.String(); got != want {
+		t.Fatalf("Unerwarteter Quelltext:\n%s\nErwartet:\n%s", got, want)
+	}
+
+	out.Reset()
+	data := []byte{0x33, 0xC0, 0x00, 0xDF, 0xF1, 0x80} // MOVE.W D0, $00DFF180
+	if err := WriteSource(&out, data, 0, SourceOptions{Symbolizer: SymbolizeFunc(func(address uint32) (string, bool) {
+		return "COLOR00", address == 0xDFF180
+	})}); err != nil {
+		t.Fatalf("WriteSource-Fehler: %v", err)
+	}
+	if got, want := out.String(), "COLOR00\tEQU\t$00DFF180\n\tORG\t$00000000\n\tMOVE.W D0, COLOR00.L\n"; got != want {
+		t.Fatalf("Unerwarteter Quelltext:\n%s\nErwartet:\n%s", got, want)
+	}
+}
+
+func TestWriteSourceRoundTrip(t *testing.T) {
+	var source strings.Builder
+	if err := WriteSource(&source, sourceProgram, 0x1000, SourceOptions{
+		Data: []AddressRange{{Start: 0x1014, End: 0x101C}},
+	}); err != nil {
+		t.Fatalf("WriteSource-Fehler: %v", err)
+	}
+
+	assembled, err := m68kasm.AssembleString(source.String())
+	if err != nil {
+		t.Fatalf("Assembler-Fehler:\n%s\n%v", source.String(), err)
+	}
+	if !bytes.Equal(assembled, sourceProgram) {
+		t.Fatalf("Bytes weichen ab:\n%s\nErhalten: % X\nErwartet: % X", source.String(), assembled, sourceProgram)
+	}
+}
+
+func TestWriteSourceKeepsSuppressedBaseRegister(t *testing.T) {
+	// MOVE.L (16,D0.W), D0 mit unterdrücktem A3 und A0: nur A0 entsteht
+	// beim Assemblieren wieder, A3 bleibt als Daten erhalten.
+	data := []byte{0x20, 0x33, 0x01, 0xA0, 0x00, 0x10, 0x20, 0x30, 0x01, 0xA0, 0x00, 0x10}
+	var out strings.Builder
+	if err := WriteSource(&out, data, 0x1000, SourceOptions{}); err != nil {
+		t.Fatalf("WriteSource-Fehler: %v", err)
+	}
+	want := "\tORG\t$00001000\n" +
+		"\tDC.W\t$2033\n" +
+		"\tBCLR D0, -(A0)\n" +
+		"\tDC.W\t$0010\n" +
+		"\tMOVE.L (16,D0.W), D0\n"
+	if got := out.String(); got != want {
+		t.Fatalf("Unerwarteter Quelltext:\n%s\nErwartet:\n%s", got, want)
+	}
+}
+
+func TestInstructionTokens(t *testing.T) {
+	inst, err := Decode([]byte{0x30, 0x3B, 0x10, 0x04}, 0) // MOVE.W (4,PC,D1.W), D0
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	want := []Token{
+		{Kind: TokenMnemonic, Text: "MOVE", Operand: -1},
+		{Kind: TokenPunctuation, Text: ".", Operand: -1},
+		{Kind: TokenSizeSuffix, Text: "W", Operand: -1},
+		{Kind: TokenWhitespace, Text: " ", Operand: -1},
+		{Kind: TokenPunctuation, Text: "(", Operand: 0},
+		{Kind: TokenNumber, Text: "4", Operand: 0},
+		{Kind: TokenPunctuation, Text: ",", Operand: 0},
+		{Kind: TokenRegister, Text: "PC", Operand: 0},
+		{Kind: TokenPunctuation, Text: ",", Operand: 0},
+		{Kind: TokenRegister, Text: "D1", Operand: 0},
+		{Kind: TokenPunctuation, Text: ".", Operand: 0},
+		{Kind: TokenSizeSuffix, Text: "W", Operand: 0},
+		{Kind: TokenPunctuation, Text: ")", Operand: 0},
+		{Kind: TokenPunctuation, Text: ",", Operand: -1},
+		{Kind: TokenWhitespace, Text: " ", Operand: -1},
+		{Kind: TokenRegister, Text: "D0", Operand: 1},
+	}
+	if got := inst.Tokens(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unerwartete Tokens:\n%+v", got)
+	}
+
+	testCases := []struct {
+		data []byte
+		opts DecodeOptions
+		want string
+	}{
+		{data: []byte{0x20, 0x28, 0x00, 0x04}, opts: DecodeOptions{Syntax: SyntaxMIT}, want: "mne:move siz:l whi reg:%a0 pun:@ pun:( num:4 pun:) pun:, reg:%d0"},
+		{data: []byte{0x70, 0x80}, want: "mne:MOVEQ whi pun:# num:-$80 pun:, whi reg:D0"},
+		{data: []byte{0x48, 0xE7, 0xC0, 0xC0}, opts: DecodeOptions{Syntax: SyntaxDevpac}, want: "mne:movem pun:. siz:l whi reg:d0 pun:- reg:d1 pun:/ reg:a0 pun:- reg:a1 pun:, pun:- pun:( reg:a7 pun:)"},
+		{data: []byte{0x67, 0xFE}, opts: DecodeOptions{Symbolizer: SymbolizeFunc(func(address uint32) (string, bool) {
+			return "loop", address == 0
+		})}, want: "mne:BEQ pun:. siz:S whi sym:loop"},
+	}
+	for _, tc := range testCases {
+		inst, err := DecodeWithOptions(tc.data, 0, tc.opts)
+		if err != nil {
+			t.Fatalf("Decode-Fehler: %v", err)
+		}
+		var parts []string
+		var text strings.Builder
+		for _, token := range inst.Tokens() {
+			text.WriteString(token.Text)
+			if token.Kind == TokenWhitespace {
+				parts = append(parts, "whi")
+				continue
+			}
+			parts = append(parts, string(token.Kind)[:3]+":"+token.Text)
+		}
+		if got := strings.Join(parts, " "); got != tc.want {
+			t.Fatalf("%s: unerwartete Tokens: %s", inst.Assembly(), got)
+		}
+		if text.String() != inst.Assembly() {
+			t.Fatalf("Tokens ergeben %q statt %q", text.String(), inst.Assembly())
+		}
+	}
+}
+
+func TestListing(t *testing.T) {
+	data := []byte{
+		0x4E, 0x56, 0x00, 0x00, // LINK A6, #0
+		0x23, 0xFC, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x20, 0x00, // MOVE.L #1, $00002000
+		0x4E, 0x75, // RTS
+	}
+	instructions, err := DisassembleRangeWithOptions(data, 0x1000, DecodeOptions{Syntax: SyntaxMIT})
+	if err != nil {
+		t.Fatalf("Disassemble-Fehler: %v", err)
+	}
+
+	var out strings.Builder
+	listing := NewListing(&out)
+	listing.Symbolizer = SymbolizeFunc(func(address uint32) (string, bool) {
+		return "main", address == 0x1000
+	})
+	listing.Comment = func(inst Instruction) string {
+		if inst.Metadata.MnemonicBase == "RTS" {
+			return "done"
+		}
+		return ""
+	}
+	if err := listing.WriteSection(".text"); err != nil {
+		t.Fatalf("WriteSection-Fehler: %v", err)
+	}
+	if err := listing.WriteInstructions(instructions); err != nil {
+		t.Fatalf("WriteInstructions-Fehler: %v", err)
+	}
+
+	want := "\nDisassembly of section .text:\n" +
+		"\n00001000 <main>:\n" +
+		"    1000:\t4e56 0000      \tlink %fp,#0\n" +
+		"    1004:\t23fc 0000 0001 \tmovel #1,0x2000\n" +
+		"    100a:\t0000 2000 \n" +
+		"    100e:\t4e75           \trts ; done\n"
+	if got := out.String(); got != want {
+		t.Fatalf("Unerwartetes Listing:\n%q\nErwartet:\n%q", got, want)
+	}
+
+	// Below $1000 the address column shrinks to four digits like objdump's.
+	inst, err := DecodeWithOptions([]byte{0x4E, 0x75}, 0x10, DecodeOptions{Syntax: SyntaxMIT})
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	out.Reset()
+	if err := NewListing(&out).WriteInstruction(*inst); err != nil {
+		t.Fatalf("WriteInstruction-Fehler: %v", err)
+	}
+	if got, want := out.String(), "  10:\t4e75           \trts\n"; got != want {
+		t.Fatalf("Unerwartetes Listing: %q", got)
+	}
+}
+
+func TestInstructionJSONRoundTrip(t *testing.T) {
+	testCases := []struct {
+		data []byte
+		opts DecodeOptions
+	}{
+		{data: []byte{0x4E, 0x75}},                         // RTS
+		{data: []byte{0x67, 0x08}},                         // BEQ.S $000A
+		{data: []byte{0x70, 0x80}},                         // MOVEQ #-$80, D0
+		{data: []byte{0x30, 0x3B, 0x10, 0x04}},             // MOVE.W (4,PC,D1.W), D0
+		{data: []byte{0x20, 0x30, 0x05, 0x25, 0x00, 0x10}}, // MOVE.L ([16,A0],D0.W,4), D0
+		{data: []byte{0x48, 0xE7, 0xC0, 0xC0}, opts: DecodeOptions{Syntax: SyntaxMIT}},             // MOVEM.L D0-D1/A0-A1, -(A7)
+		{data: []byte{0xE9, 0xC0, 0x10, 0x48}},                                                     // BFEXTU D0{1:8}, D1
+		{data: []byte{0x4C, 0x01, 0x10, 0x02}},                                                     // MULS.L D1, D2:D1
+		{data: []byte{0xF2, 0x3C, 0x54, 0x00, 0x3F, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},     // FMOVE.D #1.0, FP0
+		{data: []byte{0x4E, 0x7A, 0x08, 0x01}, opts: DecodeOptions{CPU: CPU68000}},                 // MOVEC unavailable
+		{data: []byte{0x41, 0xFA, 0x00, 0x0E}, opts: DecodeOptions{PCRelative: PCRelativeComment}}, // LEA (14,PC), A0 ; =$1010
+	}
+	var out bytes.Buffer
+	enc := NewJSONLinesEncoder(&out)
+	var want []Instruction
+	for _, tc := range testCases {
+		inst, err := DecodeWithOptions(tc.data, 0x1000, tc.opts)
+		if err != nil {
+			t.Fatalf("Decode-Fehler: %v", err)
+		}
+		want = append(want, *inst)
+		if err := enc.Encode(*inst); err != nil {
+			t.Fatalf("Encode-Fehler: %v", err)
+		}
+	}
+
+	dec := NewJSONLinesDecoder(&out)
+	for _, inst := range want {
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode-Fehler (JSON): %v", err)
+		}
+		if !reflect.DeepEqual(got, inst) {
+			t.Fatalf("%s: Instruktion weicht nach JSON ab:\n%+v\nErwartet:\n%+v", inst.Assembly(), got, inst)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Fatalf("Erwartet io.EOF, erhalten: %v", err)
+	}
+}
+
+func TestInstructionJSONSchema(t *testing.T) {
+	inst, err := Decode([]byte{0x4E, 0xB9, 0x00, 0x00, 0x12, 0x34}, 0x1000) // JSR $00001234
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	data, err := json.Marshal(inst)
+	if err != nil {
+		t.Fatalf("Marshal-Fehler: %v", err)
+	}
+	want := `{"schema_version":1,"address":"0x00001000","opcode":"0x4EB9","size":6,"bytes":"4eb900001234",` +
+		`"extension_words":["0x0000","0x1234"],"mnemonic":"JSR","operands":"$00001234","assembly":"JSR $00001234",` +
+		`"metadata":{"mnemonic":"JSR","mnemonic_base":"JSR","operands":[{"text":"$00001234","kind":"effective_address",` +
+		`"effective_address":{"kind":"absolute_long","mode":7,"register":1,"absolute_address":"0x00001234","resolved_address":"0x00001234"}}],` +
+		`"reads":[{"kind":"address","number":7}],"writes":[{"kind":"address","number":7}]}}`
+	if string(data) != want {
+		t.Fatalf("Unerwartetes JSON:\n%s\nErwartet:\n%s", data, want)
+	}
+
+	var decoded Instruction
+	if err := json.Unmarshal([]byte(`{"schema_version":2,"address":"0x00001000"}`), &decoded); err == nil {
+		t.Fatal("Erwartet Fehler für unbekannte Schema-Version")
+	}
+
+	var out strings.Builder
+	if err := WriteJSONLines(&out, []byte{0x4E, 0x71, 0x4E, 0x75}, 0, DecodeOptions{}); err != nil {
+		t.Fatalf("WriteJSONLines-Fehler: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); len(lines) != 2 {
+		t.Fatalf("Erwartet 2 Zeilen, erhalten %d:\n%s", len(lines), out.String())
+	}
+}
+
+func TestSymbolTableContext(t *testing.T) {
+	table := NewSymbolTable([]Symbol{
+		{Name: "buffer", Address: 0x2000, Size: 0x100},
+		{Name: "count", Address: 0x2010, Size: 4},
+		{Name: "start", Address: 0x1000, Size: 0x20},
+	})
+	testCases := []struct {
+		data []byte
+		opts DecodeOptions
+		want string
+	}{
+		{data: []byte{0x30, 0x39, 0x00, 0x00, 0x20, 0x12}, want: "MOVE.W count+$2, D0"},
+		{data: []byte{0x41, 0xF9, 0x00, 0x00, 0x20, 0x40}, want: "LEA buffer+$40, A0"},
+		{data: []byte{0x41, 0xF9, 0x00, 0x00, 0x20, 0x40}, opts: DecodeOptions{Syntax: SyntaxMIT}, want: "lea buffer+0x40,%a0"},
+		{data: []byte{0x41, 0xF9, 0x00, 0x00, 0x30, 0x00}, want: "LEA $00003000, A0"},
+		{data: []byte{0x60, 0xFE}, want: "BRA.S start"},
+		{data: []byte{0x41, 0xFA, 0x00, 0x0E}, opts: DecodeOptions{PCRelative: PCRelativeLabel}, want: "LEA start+$10(PC), A0"},
+		{data: []byte{0x20, 0x3C, 0x00, 0x00, 0x20, 0x00}, want: "MOVE.L #$00002000, D0"},
+	}
+	for _, tc := range testCases {
+		tc.opts.Symbolizer = table
+		inst, err := DecodeWithOptions(tc.data, 0x1000, tc.opts)
+		if err != nil {
+			t.Fatalf("Decode-Fehler: %v", err)
+		}
+		if got := inst.Assembly(); got != tc.want {
+			t.Fatalf("Unerwartete Assembly: %s, erwartet %s", got, tc.want)
+		}
+	}
+
+	var contexts []SymbolContext
+	table.Accept = func(value uint32, ctx SymbolContext) bool {
+		contexts = append(contexts, ctx)
+		return ctx.Role != SymbolRoleImmediate || ctx.Size == 4
+	}
+	inst, err := DecodeWithOptions([]byte{0x20, 0x3C, 0x00, 0x00, 0x20, 0x00}, 0x1000, DecodeOptions{Symbolizer: table})
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got := inst.Assembly(); got != "MOVE.L #buffer, D0" {
+		t.Fatalf("Unerwartete Assembly: %s", got)
+	}
+	if len(contexts) != 1 || contexts[0].Role != SymbolRoleImmediate || contexts[0].Operand != 0 || contexts[0].Size != 4 || contexts[0].Instruction.Address != 0x1000 {
+		t.Fatalf("Unerwarteter Kontext: %+v", contexts)
+	}
+	inst, err = DecodeWithOptions([]byte{0x70, 0x10}, 0x1000, DecodeOptions{Symbolizer: table}) // MOVEQ #16, D0
+	if err != nil {
+		t.Fatalf("Decode-Fehler: %v", err)
+	}
+	if got := inst.Assembly(); got != "MOVEQ #16, D0" {
+		t.Fatalf("Kleines Immediate wurde symbolisiert: %s", got)
+	}
+
+	if name, ok := table.Symbolize(0x2010); !ok || name != "count" {
+		t.Fatalf("Symbolize(0x2010) = %q, %v", name, ok)
+	}
+	if _, ok := table.Symbolize(0x2012); ok {
+		t.Fatal("Symbolize darf nur exakte Adressen benennen")
+	}
+}
+
+func TestDecodeRegisterEffects(t *testing.T) {
+	testCases := []struct {
+		data   []byte
+		reads  string
+		writes string
+	}{
+		{[]byte{0x20, 0x18}, "A0", "D0 A0"},                                    // MOVE.L (A0)+, D0
+		{[]byte{0x30, 0x18}, "D0 A0", "D0 A0"},                                 // MOVE.W (A0)+, D0
+		{[]byte{0x2F, 0x00}, "D0 A7", "A7"},                                    // MOVE.L D0, -(A7)
+		{[]byte{0x22, 0x81}, "D1 A1", ""},                                      // MOVE.L D1, (A1)
+		{[]byte{0x41, 0xF0, 0x18, 0x04}, "D1 A0", "A0"},                        // LEA (4,A0,D1.L), A0
+		{[]byte{0x61, 0x10}, "A7", "A7"},                                       // BSR.S
+		{[]byte{0x4E, 0x75}, "A7", "A7"},                                       // RTS
+		{[]byte{0x48, 0x50}, "A0 A7", "A7"},                                    // PEA (A0)
+		{[]byte{0x4E, 0x56, 0xFF, 0xF8}, "A6 A7", "A6 A7"},                     // LINK A6, #-8
+		{[]byte{0x4E, 0x5E}, "A6", "A6 A7"},                                    // UNLK A6
+		{[]byte{0x48, 0xE7, 0xC0, 0xC0}, "D0 D1 A0 A1 A7", "A7"},               // MOVEM.L D0-D1/A0-A1, -(A7)
+		{[]byte{0x4C, 0xDF, 0x03, 0x03}, "A7", "D0 D1 A0 A1 A7"},               // MOVEM.L (A7)+, D0-D1/A0-A1
+		{[]byte{0x4C, 0x90, 0x00, 0x03}, "A0", "D0 D1"},                        // MOVEM.W (A0), D0-D1
+		{[]byte{0x51, 0xC8, 0xFF, 0xFE}, "D0", "D0"},                           // DBRA D0
+		{[]byte{0x0C, 0x40, 0x00, 0x01}, "D0", ""},                             // CMPI.W #1, D0
+		{[]byte{0xC3, 0x40}, "D0 D1", "D0 D1"},                                 // EXG D1, D0
+		{[]byte{0x4C, 0x00, 0x14, 0x02}, "D0 D1", "D1 D2"},                     // MULU.L D0, D2:D1
+		{[]byte{0x44, 0xFC, 0x00, 0x00}, "", "CCR"},                            // MOVE.W #0, CCR
+		{[]byte{0x4E, 0x73}, "A7", "A7 SR"},                                    // RTE
+		{[]byte{0xF2, 0x27, 0xE0, 0x0F}, "A7 FP0 FP1 FP2 FP3", "A7"},           // FMOVEM.X FP0-FP3, -(A7)
+		{[]byte{0xF2, 0x3C, 0x44, 0x22, 0x3F, 0x80, 0x00, 0x00}, "FP0", "FP0"}, // FADD.S #1, FP0
+		{[]byte{0xF0, 0x10, 0x9F, 0x10}, "A0", "A0"},                           // PTESTR #0, (A0), #7, A0
+		{[]byte{0xF0, 0x10, 0x9E, 0x10}, "A0", ""},                             // PTESTR #0, (A0), #7
+		{[]byte{0xF0, 0x10, 0x38, 0xE9}, "D1 A0", ""},                          // PFLUSH D1, #7, (A0)
+		{[]byte{0xF5, 0x08}, "A0", ""},                                         // PFLUSH (A0)
+	}
+	for _, tc := range testCases {
+		inst, err := Decode(tc.data, 0x1000)
+		if err != nil {
+			t.Fatalf("Decode-Fehler: %v", err)
+		}
+		if got := registerSetText(inst.Metadata.Reads); got != tc.reads {
+			t.Fatalf("%s: Reads %q, erwartet %q", inst.Assembly(), got, tc.reads)
+		}
+		if got := registerSetText(inst.Metadata.Writes); got != tc.writes {
+			t.Fatalf("%s: Writes %q, erwartet %q", inst.Assembly(), got, tc.writes)
+		}
+	}
+}
+
+func registerSetText(regs []Register) string {
+	names := make([]string, len(regs))
+	for i, reg := range regs {
+		switch reg.Kind {
+		case RegisterKindData:
+			names[i] = fmt.Sprintf("D%d", reg.Number)
+		case RegisterKindAddress:
+			names[i] = fmt.Sprintf("A%d", reg.Number)
+		case RegisterKindFP:
+			names[i] = fmt.Sprintf("FP%d", reg.Number)
+		default:
+			names[i] = reg.Name
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+func TestDecodeConditionCodes(t *testing.T) {
+	testCases := []struct {
+		data    []byte
+		effects string
+		uses    string
+	}{
+		{[]byte{0xD0, 0x41}, "*****", ""},                // ADD.W D1, D0
+		{[]byte{0x52, 0x88}, "-----", ""},                // ADDQ.L #1, A0
+		{[]byte{0xD1, 0x81}, "*****", "XZ"},              // ADDX.L D1, D0
+		{[]byte{0x42, 0x40}, "-0100", ""},                // CLR.W D0
+		{[]byte{0x4A, 0x40}, "-**00", ""},                // TST.W D0
+		{[]byte{0x30, 0x40}, "-----", ""},                // MOVEA.W D0, A0
+		{[]byte{0xE5, 0x50}, "***0*", "X"},               // ROXL.W #2, D0
+		{[]byte{0x01, 0x00}, "--*--", ""},                // BTST D0, D0
+		{[]byte{0x6E, 0x00, 0x00, 0x10}, "-----", "NZV"}, // BGT.W
+		{[]byte{0x57, 0xC0}, "-----", "Z"},               // SEQ D0
+		{[]byte{0x51, 0xC8, 0xFF, 0xFE}, "-----", ""},    // DBRA D0
+		{[]byte{0x4E, 0x76}, "-----", "V"},               // TRAPV
+		{[]byte{0x02, 0x3C, 0x00, 0xFE}, "----0", ""},    // ANDI #$FE, CCR
+		{[]byte{0x0A, 0x3C, 0x00, 0x01}, "----*", "C"},   // EORI #1, CCR
+		{[]byte{0x44, 0xFC, 0x00, 0x04}, "00100", ""},    // MOVE.W #4, CCR
+		{[]byte{0x40, 0xC0}, "-----", "XNZVC"},           // MOVE.W SR, D0
+		{[]byte{0x4E, 0x73}, "*****", ""},                // RTE
+	}
+	for _, tc := range testCases {
+		inst, err := Decode(tc.data, 0x1000)
+		if err != nil {
+			t.Fatalf("Decode-Fehler: %v", err)
+		}
+		cc := inst.Metadata.ConditionCodes
+		if got := flagEffectText(cc); got != tc.effects || cc.Uses.String() != tc.uses {
+			t.Fatalf("%s: Flags %s/%s, erwartet %s/%s", inst.Assembly(), got, cc.Uses, tc.effects, tc.uses)
+		}
+	}
+}
+
+func flagEffectText(cc ConditionCodes) string {
+	var b strings.Builder
+	for _, effect := range []FlagEffect{cc.X, cc.N, cc.Z, cc.V, cc.C} {
+		b.WriteByte(map[FlagEffect]byte{FlagUnchanged: '-', FlagResult: '*', FlagCleared: '0', FlagSet: '1', FlagUndefined: 'U'}[effect])
+	}
+	return b.String()
+}
@@ -110,3 +110,55 @@ func (ed *ELFDisassembler) disassembleSection(section *elf.Section) ([]Instructi
 	// Use the section's virtual address as the starting address.
 	return DisassembleRange(data, uint32(section.Addr))
 }
+
+// WriteListing writes all executable sections to listing in file order, each
+// after its section header. Unless the listing has a Symbolizer of its own,
+// label lines come from the function, object and untyped symbols of the file.
+func (ed *ELFDisassembler) WriteListing(listing *Listing, opts DecodeOptions) error {
+	l := *listing
+	if l.Symbolizer == nil {
+		l.Symbolizer = ed.symbols()
+	}
+
+	for _, section := range ed.file.Sections {
+		if section.Flags&elf.SHF_EXECINSTR == 0 {
+			continue
+		}
+
+		data, err := section.Data()
+		if err != nil {
+			return fmt.Errorf("failed to read section %q: %w", section.Name, err)
+		}
+		instrs, err := DisassembleRangeWithOptions(data, uint32(section.Addr), opts)
+		if err != nil {
+			return fmt.Errorf("failed to disassemble section %q: %w", section.Name, err)
+		}
+
+		if err := l.WriteSection(section.Name); err != nil {
+			return err
+		}
+		if err := l.WriteInstructions(instrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// symbols returns a SymbolTable of the named symbols of the file, covering
+// their sizes. A file without a symbol table has no labels.
+func (ed *ELFDisassembler) symbols() *SymbolTable {
+	var table []Symbol
+	symbols, _ := ed.file.Symbols()
+	for _, sym := range symbols {
+		switch elf.ST_TYPE(sym.Info) {
+		case elf.STT_FUNC, elf.STT_OBJECT, elf.STT_NOTYPE:
+		default:
+			continue
+		}
+		if sym.Name == "" || sym.Section == elf.SHN_UNDEF {
+			continue
+		}
+		table = append(table, Symbol{Name: sym.Name, Address: uint32(sym.Value), Size: uint32(sym.Size)})
+	}
+	return NewSymbolTable(table)
+}
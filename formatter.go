@@ -0,0 +1,246 @@
+package m68kdasm
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Formatter renders operands from their structured metadata. The decoder
+// calls the method matching Operand.Kind, or Operand.EffectiveAddress.Kind
+// for effective addresses, and appends FormatBitfield and FormatSuffix when
+// the operand carries a bit-field specifier or suffix. Operands are joined
+// with Separator.
+//
+// Embed SyntaxFormatter to override only some of the callbacks.
+type Formatter interface {
+	FormatRegister(operand Operand) string
+	FormatImmediate(operand Operand) string
+	FormatRegisterList(operand Operand) string
+	FormatBranchTarget(operand Operand) string
+	FormatRegisterPair(operand Operand) string
+
+	FormatDataRegisterDirect(operand Operand) string
+	FormatAddressRegisterDirect(operand Operand) string
+	FormatAddressIndirect(operand Operand) string
+	FormatPostIncrement(operand Operand) string
+	FormatPreDecrement(operand Operand) string
+	FormatDisplacement(operand Operand) string
+	FormatIndex(operand Operand) string
+	FormatAbsoluteShort(operand Operand) string
+	FormatAbsoluteLong(operand Operand) string
+	FormatPCDisplacement(operand Operand) string
+	FormatPCIndex(operand Operand) string
+	FormatImmediateAddress(operand Operand) string
+
+	FormatBitfield(operand Operand) string
+	FormatSuffix(operand Operand) string
+	Separator() string
+}
+
+// SyntaxFormatter is the Formatter behind DecodeOptions.Syntax.
+type SyntaxFormatter struct {
+	Syntax Syntax
+}
+
+func (f SyntaxFormatter) FormatRegister(operand Operand) string {
+	return f.Syntax.register(*operand.Register)
+}
+
+func (f SyntaxFormatter) FormatImmediate(operand Operand) string {
+	return f.Syntax.immediate(operand, operand.Immediate)
+}
+
+func (f SyntaxFormatter) FormatRegisterList(operand Operand) string {
+	return f.Syntax.registerList(operand.RegisterList)
+}
+
+func (f SyntaxFormatter) FormatBranchTarget(operand Operand) string {
+	return f.Syntax.address(*operand.BranchTarget)
+}
+
+func (f SyntaxFormatter) FormatRegisterPair(operand Operand) string {
+	pair := operand.RegisterPair
+	first, second := f.Syntax.register(pair.First), f.Syntax.register(pair.Second)
+	if !pair.Indirect {
+		return first + ":" + second
+	}
+	if f.Syntax == SyntaxMIT {
+		return first + "@:" + second + "@"
+	}
+	return "(" + first + "):(" + second + ")"
+}
+
+func (f SyntaxFormatter) FormatDataRegisterDirect(operand Operand) string {
+	return f.Syntax.register(*operand.EffectiveAddress.Base)
+}
+
+func (f SyntaxFormatter) FormatAddressRegisterDirect(operand Operand) string {
+	return f.Syntax.register(*operand.EffectiveAddress.Base)
+}
+
+func (f SyntaxFormatter) FormatAddressIndirect(operand Operand) string {
+	base := f.Syntax.register(*operand.EffectiveAddress.Base)
+	if f.Syntax == SyntaxMIT {
+		return base + "@"
+	}
+	return "(" + base + ")"
+}
+
+func (f SyntaxFormatter) FormatPostIncrement(operand Operand) string {
+	base := f.Syntax.register(*operand.EffectiveAddress.Base)
+	if f.Syntax == SyntaxMIT {
+		return base + "@+"
+	}
+	return "(" + base + ")+"
+}
+
+func (f SyntaxFormatter) FormatPreDecrement(operand Operand) string {
+	base := f.Syntax.register(*operand.EffectiveAddress.Base)
+	if f.Syntax == SyntaxMIT {
+		return base + "@-"
+	}
+	return "-(" + base + ")"
+}
+
+func (f SyntaxFormatter) FormatDisplacement(operand Operand) string {
+	ea := operand.EffectiveAddress
+	base := f.Syntax.register(*ea.Base)
+	displacement := strconv.Itoa(int(*ea.Displacement))
+	switch f.Syntax {
+	case SyntaxMIT:
+		return base + "@(" + displacement + ")"
+	case SyntaxDevpac:
+		return displacement + "(" + base + ")"
+	}
+	return "(" + displacement + "," + base + ")"
+}
+
+func (f SyntaxFormatter) FormatIndex(operand Operand) string {
+	return f.Syntax.indexed(*operand.EffectiveAddress)
+}
+
+func (f SyntaxFormatter) FormatAbsoluteShort(operand Operand) string {
+	address := *operand.EffectiveAddress.AbsoluteAddress
+	switch f.Syntax {
+	case SyntaxMIT:
+		return f.Syntax.hex(address, 0) + ":w"
+	case SyntaxDevpac:
+		return f.Syntax.hex(address, 4) + ".w"
+	}
+	return f.Syntax.hex(address, 4)
+}
+
+func (f SyntaxFormatter) FormatAbsoluteLong(operand Operand) string {
+	address := *operand.EffectiveAddress.AbsoluteAddress
+	switch f.Syntax {
+	case SyntaxMIT:
+		return f.Syntax.hex(address, 0)
+	case SyntaxDevpac:
+		return f.Syntax.hex(address, 8) + ".l"
+	}
+	return f.Syntax.hex(address, 8)
+}
+
+func (f SyntaxFormatter) FormatPCDisplacement(operand Operand) string {
+	return f.FormatDisplacement(operand)
+}
+
+func (f SyntaxFormatter) FormatPCIndex(operand Operand) string {
+	return f.Syntax.indexed(*operand.EffectiveAddress)
+}
+
+func (f SyntaxFormatter) FormatImmediateAddress(operand Operand) string {
+	return f.Syntax.immediate(operand, operand.EffectiveAddress.Immediate)
+}
+
+func (f SyntaxFormatter) FormatBitfield(operand Operand) string {
+	field := operand.Bitfield
+	offset := strconv.Itoa(int(field.Offset))
+	if field.OffsetRegister != nil {
+		offset = f.Syntax.register(*field.OffsetRegister)
+	}
+	width := strconv.Itoa(int(field.Width))
+	if field.WidthRegister != nil {
+		width = f.Syntax.register(*field.WidthRegister)
+	}
+	return "{" + offset + ":" + width + "}"
+}
+
+// FormatSuffix renders the MAC .U/.L and & decorations and the FMOVE.P
+// k-factor {#k} or {Dn}.
+func (f SyntaxFormatter) FormatSuffix(operand Operand) string {
+	if f.Syntax == SyntaxMotorola {
+		return operand.Suffix
+	}
+	suffix := strings.ToLower(operand.Suffix)
+	if f.Syntax == SyntaxMIT && strings.HasPrefix(suffix, "{d") {
+		suffix = "{%" + suffix[1:]
+	}
+	return suffix
+}
+
+func (f SyntaxFormatter) Separator() string {
+	if f.Syntax == SyntaxMotorola {
+		return ", "
+	}
+	return ","
+}
+
+// formatWith renders an operand through the Formatter callback for its kind.
+// Operands without structured data, such as the MAC shift, keep their Text.
+func formatWith(formatter Formatter, operand Operand) string {
+	var text string
+	switch operand.Kind {
+	case OperandKindRegister:
+		text = formatter.FormatRegister(operand)
+	case OperandKindImmediate:
+		text = formatter.FormatImmediate(operand)
+	case OperandKindRegisterList:
+		text = formatter.FormatRegisterList(operand)
+	case OperandKindBranchTarget:
+		text = formatter.FormatBranchTarget(operand)
+	case OperandKindRegisterPair:
+		text = formatter.FormatRegisterPair(operand)
+	case OperandKindEffectiveAddr:
+		text = formatEffectiveAddress(formatter, operand)
+	default:
+		return operand.Text
+	}
+	if operand.Bitfield != nil {
+		text += formatter.FormatBitfield(operand)
+	}
+	if operand.Suffix != "" {
+		text += formatter.FormatSuffix(operand)
+	}
+	return text
+}
+
+func formatEffectiveAddress(formatter Formatter, operand Operand) string {
+	switch operand.EffectiveAddress.Kind {
+	case EAKindDataRegisterDirect:
+		return formatter.FormatDataRegisterDirect(operand)
+	case EAKindAddressRegisterDirect:
+		return formatter.FormatAddressRegisterDirect(operand)
+	case EAKindAddressIndirect:
+		return formatter.FormatAddressIndirect(operand)
+	case EAKindPostIncrement:
+		return formatter.FormatPostIncrement(operand)
+	case EAKindPreDecrement:
+		return formatter.FormatPreDecrement(operand)
+	case EAKindDisplacement:
+		return formatter.FormatDisplacement(operand)
+	case EAKindIndex:
+		return formatter.FormatIndex(operand)
+	case EAKindAbsoluteShort:
+		return formatter.FormatAbsoluteShort(operand)
+	case EAKindAbsoluteLong:
+		return formatter.FormatAbsoluteLong(operand)
+	case EAKindPCDisplacement:
+		return formatter.FormatPCDisplacement(operand)
+	case EAKindPCIndex:
+		return formatter.FormatPCIndex(operand)
+	case EAKindImmediate:
+		return formatter.FormatImmediateAddress(operand)
+	}
+	return operand.Text
+}
@@ -0,0 +1,142 @@
+// Package hwmaps provides Symbolizers that name the memory-mapped hardware
+// registers of 68000 platforms, such as COLOR00 for $DFF180 on the Amiga.
+//
+// A Map is a m68kdasm.ContextSymbolizer: set it as DecodeOptions.Symbolizer
+// and absolute addresses render as register names, accesses inside a wider
+// register or a register block as name+offset:
+//
+//	opts := m68kdasm.DecodeOptions{Symbolizer: hwmaps.Amiga()}
+//	// MOVE.W D0, COLOR00
+//	// MOVE.B D0, CIAA_PRA
+//
+// Built-in maps exist for the Amiga, the Atari ST, the Sega Genesis/Mega
+// Drive and the Sharp X68000; Load reads user-defined maps.
+package hwmaps
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jenska/m68kdasm"
+)
+
+// Register is a memory-mapped register or a block of registers.
+type Register struct {
+	Name    string
+	Address uint32
+	// Size is the width in bytes: 1, 2 or 4 for a single register, more
+	// for a block such as a palette. Zero counts as one byte.
+	Size uint32
+}
+
+func (r Register) size() uint32 {
+	return max(r.Size, 1)
+}
+
+func (r Register) contains(address uint32) bool {
+	return address-r.Address < r.size()
+}
+
+// Map names the registers of one platform.
+type Map struct {
+	// Name is the platform or file the map describes.
+	Name string
+	// AddressMask is applied to addresses before the lookup, e.g. 0x00FFFFFF
+	// for the 24-bit bus of the 68000 so $FFFF8240.W finds $FF8240. Zero
+	// keeps all 32 bits.
+	AddressMask uint32
+
+	registers []Register // sorted by address
+	maxSize   uint32
+}
+
+// New returns a map of registers. Registers may overlap: a long register
+// such as COP1LC can cover the word registers COP1LCH and COP1LCL, and
+// blocks may contain single registers.
+func New(name string, mask uint32, registers []Register) *Map {
+	sorted := append([]Register(nil), registers...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Address < sorted[j].Address })
+	m := &Map{Name: name, AddressMask: mask, registers: sorted}
+	for _, reg := range sorted {
+		m.maxSize = max(m.maxSize, reg.size())
+	}
+	return m
+}
+
+// Registers returns the registers of the map sorted by address.
+func (m *Map) Registers() []Register {
+	return append([]Register(nil), m.registers...)
+}
+
+// Symbolize names the register starting at address. Of several registers
+// starting there the narrowest wins.
+func (m *Map) Symbolize(address uint32) (string, bool) {
+	reg, ok := m.Lookup(address, 0)
+	if !ok || reg.Address != m.mask(address) {
+		return "", false
+	}
+	return reg.Name, true
+}
+
+// SymbolizeContext names absolute addresses only; hardware registers are no
+// branch targets, and immediates and PC-relative operands do not point into
+// I/O space. The access size picks between overlapping registers, so a
+// MOVE.L to $DFF080 names COP1LC and a MOVE.W COP1LCH.
+func (m *Map) SymbolizeContext(value uint32, ctx m68kdasm.SymbolContext) (string, int32, bool) {
+	if ctx.Role != m68kdasm.SymbolRoleAbsolute {
+		return "", 0, false
+	}
+	reg, ok := m.Lookup(value, uint32(ctx.Size))
+	if !ok {
+		return "", 0, false
+	}
+	return reg.Name, int32(m.mask(value) - reg.Address), true
+}
+
+// Lookup returns the register covering address. A register starting at
+// address with the access size wins; otherwise the narrowest covering
+// register does. Size 0 means any size.
+func (m *Map) Lookup(address, size uint32) (Register, bool) {
+	address = m.mask(address)
+	i := sort.Search(len(m.registers), func(i int) bool { return m.registers[i].Address > address })
+	best := -1
+	for i--; i >= 0 && address-m.registers[i].Address < m.maxSize; i-- {
+		reg := m.registers[i]
+		if !reg.contains(address) {
+			continue
+		}
+		if reg.Address == address && reg.size() == size {
+			return reg, true
+		}
+		if best < 0 || reg.size() <= m.registers[best].size() {
+			best = i
+		}
+	}
+	if best < 0 {
+		return Register{}, false
+	}
+	return m.registers[best], true
+}
+
+func (m *Map) mask(address uint32) uint32 {
+	if m.AddressMask == 0 {
+		return address
+	}
+	return address & m.AddressMask
+}
+
+// Builtin returns the built-in map of a platform: "amiga", "atari-st",
+// "genesis" (or "megadrive") and "x68000".
+func Builtin(platform string) (*Map, bool) {
+	switch strings.ToLower(platform) {
+	case "amiga":
+		return Amiga(), true
+	case "atari-st", "atarist":
+		return AtariST(), true
+	case "genesis", "megadrive":
+		return Genesis(), true
+	case "x68000", "x68k":
+		return X68000(), true
+	}
+	return nil, false
+}
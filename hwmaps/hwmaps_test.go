@@ -0,0 +1,115 @@
+package hwmaps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenska/m68kdasm"
+)
+
+func TestBuiltinMapsSymbolizeRegisters(t *testing.T) {
+	testCases := []struct {
+		platform string
+		data     []byte
+		want     string
+	}{
+		{"amiga", []byte{0x33, 0xC0, 0x00, 0xDF, 0xF1, 0x80}, "MOVE.W D0, COLOR00"},
+		{"amiga", []byte{0x13, 0xC0, 0x00, 0xDF, 0xF1, 0x81}, "MOVE.B D0, COLOR00+$1"},
+		{"amiga", []byte{0x23, 0xC8, 0x00, 0xDF, 0xF0, 0x80}, "MOVE.L A0, COP1LC"},
+		{"amiga", []byte{0x33, 0xC8, 0x00, 0xDF, 0xF0, 0x82}, "MOVE.W A0, COP1LCL"},
+		{"amiga", []byte{0x41, 0xF9, 0x00, 0xDF, 0xF0, 0x00}, "LEA BLTDDAT, A0"},
+		{"amiga", []byte{0x10, 0x39, 0x00, 0xBF, 0xE0, 0x01}, "MOVE.B CIAA_PRA, D0"},
+		{"amiga", []byte{0x20, 0x3C, 0x00, 0xDF, 0xF1, 0x80}, "MOVE.L #$00DFF180, D0"},
+		{"atari-st", []byte{0x31, 0xC0, 0x82, 0x40}, "MOVE.W D0, PALETTE0"},
+		{"atari-st", []byte{0x11, 0xC0, 0xFA, 0x07}, "MOVE.B D0, MFP_IERA"},
+		{"genesis", []byte{0x23, 0xC0, 0x00, 0xC0, 0x00, 0x04}, "MOVE.L D0, VDP_CTRL"},
+		{"genesis", []byte{0x33, 0xC0, 0x00, 0xC0, 0x00, 0x02}, "MOVE.W D0, VDP_DATA"},
+		{"x68000", []byte{0x33, 0xC0, 0x00, 0xE8, 0x20, 0x10}, "MOVE.W D0, GPALETTE+$10"},
+		{"x68000", []byte{0x33, 0xC0, 0x00, 0xF0, 0x00, 0x00}, "MOVE.W D0, $00F00000"},
+	}
+	for _, tc := range testCases {
+		symbols, ok := Builtin(tc.platform)
+		if !ok {
+			t.Fatalf("no built-in map for %q", tc.platform)
+		}
+		inst, err := m68kdasm.DecodeWithOptions(tc.data, 0, m68kdasm.DecodeOptions{Symbolizer: symbols})
+		if err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		if got := inst.Assembly(); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.platform, got, tc.want)
+		}
+	}
+}
+
+func TestMapSkipsBranchTargets(t *testing.T) {
+	amiga := Amiga()
+	inst, err := m68kdasm.DecodeWithOptions([]byte{0x61, 0x7E}, 0xDFF100, m68kdasm.DecodeOptions{Symbolizer: amiga})
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if got := inst.Assembly(); got != "BSR.S $00DFF180" {
+		t.Fatalf("branch target was symbolized: %q", got)
+	}
+	if name, ok := amiga.Symbolize(0xDFF080); !ok || name != "COP1LCH" {
+		t.Fatalf("Symbolize($DFF080) = %q, %v", name, ok)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	text := `# Custom board
+name  MyBoard
+mask  $00FFFFFF
+UART_DATA   $F00001  b   ; receive/transmit
+TIMER       0xF00010 l
+FRAMEBUF    $E00000  $8000
+`
+	m, err := Load(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	want := []Register{
+		{Name: "FRAMEBUF", Address: 0xE00000, Size: 0x8000},
+		{Name: "UART_DATA", Address: 0xF00001, Size: 1},
+		{Name: "TIMER", Address: 0xF00010, Size: 4},
+	}
+	if m.Name != "MyBoard" || m.AddressMask != 0x00FFFFFF || !equalRegisters(m.Registers(), want) {
+		t.Fatalf("unexpected map: %q %08X %+v", m.Name, m.AddressMask, m.Registers())
+	}
+
+	m, err = Load(strings.NewReader(`{"name": "MyBoard", "mask": "0x00FFFFFF", "registers": [
+		{"name": "UART_DATA", "address": "$F00001", "size": "b"},
+		{"name": "TIMER", "address": 15728656, "size": 4},
+		{"name": "FRAMEBUF", "address": "0xE00000", "size": 32768}]}`))
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if m.Name != "MyBoard" || m.AddressMask != 0x00FFFFFF || !equalRegisters(m.Registers(), want) {
+		t.Fatalf("unexpected map: %q %08X %+v", m.Name, m.AddressMask, m.Registers())
+	}
+	inst, err := m68kdasm.DecodeWithOptions([]byte{0x33, 0xC0, 0xFF, 0xE0, 0x01, 0x00}, 0, m68kdasm.DecodeOptions{Symbolizer: m})
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if got := inst.Assembly(); got != "MOVE.W D0, FRAMEBUF+$100" {
+		t.Fatalf("got %q", got)
+	}
+
+	for _, bad := range []string{"TIMER", "TIMER $F00010 q", "mask", `{"registers": [{"name": "X"}]}`} {
+		if _, err := Load(strings.NewReader(bad)); err == nil {
+			t.Fatalf("Load(%q) succeeded", bad)
+		}
+	}
+}
+
+func equalRegisters(got, want []Register) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,199 @@
+package hwmaps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Load reads a user-defined map in the text or JSON format. Input starting
+// with { is JSON.
+//
+// The text format has one register per line: name, address and an optional
+// size, either in bytes or as b, w or l (default one byte). Addresses and
+// sizes are decimal, $hex or 0xhex. The directives "name" and "mask" set the
+// map name and AddressMask; # and ; start comments:
+//
+//	# Custom board
+//	name  MyBoard
+//	mask  $00FFFFFF
+//	UART_DATA   $F00001  b
+//	TIMER       $F00010  l
+//	FRAMEBUF    $E00000  $8000
+//
+// The JSON format holds the same data; addresses and sizes may be numbers or
+// strings:
+//
+//	{"name": "MyBoard", "mask": "0x00FFFFFF", "registers": [
+//	  {"name": "UART_DATA", "address": "0xF00001", "size": "b"},
+//	  {"name": "FRAMEBUF", "address": "0xE00000", "size": 32768}]}
+func Load(r io.Reader) (*Map, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read register map: %w", err)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return loadJSON(data)
+	}
+	return loadText(data)
+}
+
+// LoadFile reads a map with Load. The map is named after the file unless it
+// names itself.
+func LoadFile(path string) (*Map, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open register map: %w", err)
+	}
+	defer f.Close()
+
+	m, err := Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if m.Name == "" {
+		m.Name = path
+	}
+	return m, nil
+}
+
+func loadText(data []byte) (*Map, error) {
+	var name string
+	var mask uint32
+	var regs []Register
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexAny(text, "#;"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToLower(fields[0]) {
+		case "name":
+			name = strings.Join(fields[1:], " ")
+			continue
+		case "mask":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: mask needs one value", line)
+			}
+			value, err := parseNumber(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid mask: %w", line, err)
+			}
+			mask = value
+			continue
+		}
+
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: want name, address and optional size, got %q", line, strings.TrimSpace(text))
+		}
+		address, err := parseNumber(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid address: %w", line, err)
+		}
+		size := uint32(1)
+		if len(fields) == 3 {
+			if size, err = parseSize(fields[2]); err != nil {
+				return nil, fmt.Errorf("line %d: invalid size: %w", line, err)
+			}
+		}
+		regs = append(regs, Register{Name: fields[0], Address: address, Size: size})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read register map: %w", err)
+	}
+	return New(name, mask, regs), nil
+}
+
+type jsonMap struct {
+	Name      string         `json:"name"`
+	Mask      jsonNumber     `json:"mask"`
+	Registers []jsonRegister `json:"registers"`
+}
+
+type jsonRegister struct {
+	Name    string     `json:"name"`
+	Address jsonNumber `json:"address"`
+	Size    jsonNumber `json:"size"`
+}
+
+// jsonNumber is a JSON number or a string accepted by parseSize.
+type jsonNumber struct {
+	value uint32
+	set   bool
+}
+
+func (n *jsonNumber) UnmarshalJSON(data []byte) error {
+	var number uint32
+	if err := json.Unmarshal(data, &number); err == nil {
+		*n = jsonNumber{value: number, set: true}
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("want a number or string, got %s", data)
+	}
+	value, err := parseSize(text)
+	if err != nil {
+		return err
+	}
+	*n = jsonNumber{value: value, set: true}
+	return nil
+}
+
+func loadJSON(data []byte) (*Map, error) {
+	var wire jsonMap
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, fmt.Errorf("invalid register map: %w", err)
+	}
+	regs := make([]Register, len(wire.Registers))
+	for n, reg := range wire.Registers {
+		if reg.Name == "" || !reg.Address.set {
+			return nil, fmt.Errorf("register %d: name and address are required", n)
+		}
+		size := uint32(1)
+		if reg.Size.set {
+			size = reg.Size.value
+		}
+		regs[n] = Register{Name: reg.Name, Address: reg.Address.value, Size: size}
+	}
+	return New(wire.Name, wire.Mask.value, regs), nil
+}
+
+// parseSize parses a size in bytes or the letters b, w and l.
+func parseSize(text string) (uint32, error) {
+	switch strings.ToLower(text) {
+	case "b":
+		return 1, nil
+	case "w":
+		return 2, nil
+	case "l":
+		return 4, nil
+	}
+	return parseNumber(text)
+}
+
+// parseNumber parses decimal, $hex and 0xhex numbers.
+func parseNumber(text string) (uint32, error) {
+	digits, base := text, 10
+	if rest, ok := strings.CutPrefix(text, "$"); ok {
+		digits, base = rest, 16
+	} else if rest, ok := strings.CutPrefix(strings.ToLower(text), "0x"); ok {
+		digits, base = rest, 16
+	}
+	value, err := strconv.ParseUint(digits, base, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", text)
+	}
+	return uint32(value), nil
+}
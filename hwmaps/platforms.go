@@ -0,0 +1,237 @@
+package hwmaps
+
+import "fmt"
+
+// mask24 is the address bus of the 68000: $FFFF8240.W reaches $FF8240.
+const mask24 = 0x00FFFFFF
+
+// Amiga returns the OCS/ECS/AGA custom chip registers at $DFF000, with long
+// names such as COP1LC for the pointer pairs, and the registers of the two
+// CIAs: CIA-A on the odd bytes at $BFE001, CIA-B on the even bytes at
+// $BFD000.
+func Amiga() *Map {
+	regs := words(0xDFF000, []string{
+		"BLTDDAT", "DMACONR", "VPOSR", "VHPOSR", "DSKDATR", "JOY0DAT", "JOY1DAT", "CLXDAT",
+		"ADKCONR", "POT0DAT", "POT1DAT", "POTGOR", "SERDATR", "DSKBYTR", "INTENAR", "INTREQR",
+		"DSKPTH", "DSKPTL", "DSKLEN", "DSKDAT", "REFPTR", "VPOSW", "VHPOSW", "COPCON",
+		"SERDAT", "SERPER", "POTGO", "JOYTEST", "STREQU", "STRVBL", "STRHOR", "STRLONG",
+		"BLTCON0", "BLTCON1", "BLTAFWM", "BLTALWM", "BLTCPTH", "BLTCPTL", "BLTBPTH", "BLTBPTL",
+		"BLTAPTH", "BLTAPTL", "BLTDPTH", "BLTDPTL", "BLTSIZE", "BLTCON0L", "BLTSIZV", "BLTSIZH",
+		"BLTCMOD", "BLTBMOD", "BLTAMOD", "BLTDMOD", "", "", "", "",
+		"BLTCDAT", "BLTBDAT", "BLTADAT", "", "SPRHDAT", "BPLHDAT", "DENISEID", "DSKSYNC",
+		"COP1LCH", "COP1LCL", "COP2LCH", "COP2LCL", "COPJMP1", "COPJMP2", "COPINS", "DIWSTRT",
+		"DIWSTOP", "DDFSTRT", "DDFSTOP", "DMACON", "CLXCON", "INTENA", "INTREQ", "ADKCON",
+	})
+	for n := range 4 {
+		regs = append(regs, words(0xDFF0A0+uint32(n)*0x10, []string{
+			fmt.Sprintf("AUD%dLCH", n), fmt.Sprintf("AUD%dLCL", n), fmt.Sprintf("AUD%dLEN", n),
+			fmt.Sprintf("AUD%dPER", n), fmt.Sprintf("AUD%dVOL", n), fmt.Sprintf("AUD%dDAT", n),
+		})...)
+		regs = append(regs, Register{Name: fmt.Sprintf("AUD%dLC", n), Address: 0xDFF0A0 + uint32(n)*0x10, Size: 4})
+	}
+	for n := 1; n <= 8; n++ {
+		address := 0xDFF0E0 + uint32(n-1)*4
+		regs = append(regs, pointer(address, fmt.Sprintf("BPL%dPT", n))...)
+		regs = append(regs, Register{Name: fmt.Sprintf("BPL%dDAT", n), Address: 0xDFF110 + uint32(n-1)*2, Size: 2})
+	}
+	regs = append(regs, words(0xDFF100, []string{
+		"BPLCON0", "BPLCON1", "BPLCON2", "BPLCON3", "BPL1MOD", "BPL2MOD", "BPLCON4", "CLXCON2",
+	})...)
+	for n := range 8 {
+		regs = append(regs, pointer(0xDFF120+uint32(n)*4, fmt.Sprintf("SPR%dPT", n))...)
+		regs = append(regs, words(0xDFF140+uint32(n)*8, []string{
+			fmt.Sprintf("SPR%dPOS", n), fmt.Sprintf("SPR%dCTL", n), fmt.Sprintf("SPR%dDATA", n), fmt.Sprintf("SPR%dDATB", n),
+		})...)
+	}
+	regs = append(regs, series("COLOR%02d", 0xDFF180, 2, 32)...)
+	regs = append(regs, words(0xDFF1C0, []string{
+		"HTOTAL", "HSSTOP", "HBSTRT", "HBSTOP", "VTOTAL", "VSSTOP", "VBSTRT", "VBSTOP",
+		"SPRHSTRT", "SPRHSTOP", "BPLHSTRT", "BPLHSTOP", "HHPOSW", "HHPOSR", "BEAMCON0", "HSSTRT",
+		"VSSTRT", "HCENTER", "DIWHIGH",
+	})...)
+	regs = append(regs,
+		Register{Name: "FMODE", Address: 0xDFF1FC, Size: 2},
+		Register{Name: "DSKPT", Address: 0xDFF020, Size: 4},
+		Register{Name: "BLTCPT", Address: 0xDFF048, Size: 4},
+		Register{Name: "BLTBPT", Address: 0xDFF04C, Size: 4},
+		Register{Name: "BLTAPT", Address: 0xDFF050, Size: 4},
+		Register{Name: "BLTDPT", Address: 0xDFF054, Size: 4},
+		Register{Name: "COP1LC", Address: 0xDFF080, Size: 4},
+		Register{Name: "COP2LC", Address: 0xDFF084, Size: 4},
+	)
+
+	cia := []string{"PRA", "PRB", "DDRA", "DDRB", "TALO", "TAHI", "TBLO", "TBHI",
+		"TODLOW", "TODMID", "TODHI", "", "SDR", "ICR", "CRA", "CRB"}
+	for n, name := range cia {
+		if name == "" {
+			continue
+		}
+		regs = append(regs,
+			Register{Name: "CIAA_" + name, Address: 0xBFE001 + uint32(n)*0x100, Size: 1},
+			Register{Name: "CIAB_" + name, Address: 0xBFD000 + uint32(n)*0x100, Size: 1},
+		)
+	}
+	return New("Amiga", mask24, regs)
+}
+
+// AtariST returns the I/O registers of the Atari ST: memory configuration,
+// Shifter, DMA and floppy controller, YM2149, MFP 68901 and the keyboard
+// and MIDI ACIAs.
+func AtariST() *Map {
+	regs := []Register{
+		{Name: "MEMCONF", Address: 0xFF8001, Size: 1},
+		{Name: "VBASEHI", Address: 0xFF8201, Size: 1},
+		{Name: "VBASEMID", Address: 0xFF8203, Size: 1},
+		{Name: "VCOUNTHI", Address: 0xFF8205, Size: 1},
+		{Name: "VCOUNTMID", Address: 0xFF8207, Size: 1},
+		{Name: "VCOUNTLO", Address: 0xFF8209, Size: 1},
+		{Name: "SYNCMODE", Address: 0xFF820A, Size: 1},
+		{Name: "SHIFTMOD", Address: 0xFF8260, Size: 1},
+		{Name: "DISKCTL", Address: 0xFF8604, Size: 2},
+		{Name: "DMAMODE", Address: 0xFF8606, Size: 2},
+		{Name: "DMAHIGH", Address: 0xFF8609, Size: 1},
+		{Name: "DMAMID", Address: 0xFF860B, Size: 1},
+		{Name: "DMALOW", Address: 0xFF860D, Size: 1},
+		{Name: "GISELECT", Address: 0xFF8800, Size: 1},
+		{Name: "GIWRITE", Address: 0xFF8802, Size: 1},
+		{Name: "KEYCTL", Address: 0xFFFC00, Size: 1},
+		{Name: "KEYBD", Address: 0xFFFC02, Size: 1},
+		{Name: "MIDICTL", Address: 0xFFFC04, Size: 1},
+		{Name: "MIDI", Address: 0xFFFC06, Size: 1},
+	}
+	regs = append(regs, series("PALETTE%d", 0xFF8240, 2, 16)...)
+	regs = append(regs, mfp(0xFFFA01)...)
+	return New("Atari ST", mask24, regs)
+}
+
+// Genesis returns the registers of the Sega Genesis/Mega Drive: the VDP
+// ports with their mirrors, the PSG, the I/O and Z80 control area, TMSS, and
+// the Z80 RAM and YM2612 as seen from the 68000.
+func Genesis() *Map {
+	regs := []Register{
+		{Name: "Z80_RAM", Address: 0xA00000, Size: 0x2000},
+		{Name: "YM2612_A0", Address: 0xA04000, Size: 1},
+		{Name: "YM2612_D0", Address: 0xA04001, Size: 1},
+		{Name: "YM2612_A1", Address: 0xA04002, Size: 1},
+		{Name: "YM2612_D1", Address: 0xA04003, Size: 1},
+		{Name: "IO_VERSION", Address: 0xA10001, Size: 1},
+		{Name: "IO_DATA1", Address: 0xA10003, Size: 1},
+		{Name: "IO_DATA2", Address: 0xA10005, Size: 1},
+		{Name: "IO_DATA3", Address: 0xA10007, Size: 1},
+		{Name: "IO_CTRL1", Address: 0xA10009, Size: 1},
+		{Name: "IO_CTRL2", Address: 0xA1000B, Size: 1},
+		{Name: "IO_CTRL3", Address: 0xA1000D, Size: 1},
+		{Name: "Z80_BUSREQ", Address: 0xA11100, Size: 2},
+		{Name: "Z80_RESET", Address: 0xA11200, Size: 2},
+		{Name: "TMSS", Address: 0xA14000, Size: 4},
+		{Name: "VDP_DATA", Address: 0xC00000, Size: 2},
+		{Name: "VDP_DATA", Address: 0xC00002, Size: 2},
+		{Name: "VDP_CTRL", Address: 0xC00004, Size: 2},
+		{Name: "VDP_CTRL", Address: 0xC00006, Size: 2},
+		{Name: "VDP_HVCOUNTER", Address: 0xC00008, Size: 2},
+		{Name: "PSG", Address: 0xC00011, Size: 1},
+	}
+	return New("Genesis", mask24, regs)
+}
+
+// X68000 returns the I/O registers of the Sharp X68000: CRTC, video
+// controller and palettes, DMAC, MFP, OPM, ADPCM, floppy controller, SCC,
+// PPI and the sprite controller, plus the text and graphic VRAM blocks.
+func X68000() *Map {
+	regs := []Register{
+		{Name: "GVRAM", Address: 0xC00000, Size: 0x200000},
+		{Name: "TVRAM", Address: 0xE00000, Size: 0x80000},
+		{Name: "CRTC_OP", Address: 0xE80480, Size: 2},
+		{Name: "GPALETTE", Address: 0xE82000, Size: 0x200},
+		{Name: "TSPALETTE", Address: 0xE82200, Size: 0x200},
+		{Name: "VC_R0", Address: 0xE82400, Size: 2},
+		{Name: "VC_R1", Address: 0xE82500, Size: 2},
+		{Name: "VC_R2", Address: 0xE82600, Size: 2},
+		{Name: "DMAC_GCR", Address: 0xE840FF, Size: 1},
+		{Name: "RTC", Address: 0xE8A000, Size: 0x20},
+		{Name: "PRT_DATA", Address: 0xE8C001, Size: 1},
+		{Name: "PRT_STROBE", Address: 0xE8C003, Size: 1},
+		{Name: "SYSPORT", Address: 0xE8E000, Size: 0x10},
+		{Name: "OPM_ADDR", Address: 0xE90001, Size: 1},
+		{Name: "OPM_DATA", Address: 0xE90003, Size: 1},
+		{Name: "ADPCM_CMD", Address: 0xE92001, Size: 1},
+		{Name: "ADPCM_DATA", Address: 0xE92003, Size: 1},
+		{Name: "FDC_STATUS", Address: 0xE94001, Size: 1},
+		{Name: "FDC_DATA", Address: 0xE94003, Size: 1},
+		{Name: "FDD_CTRL", Address: 0xE94005, Size: 1},
+		{Name: "FDD_SELECT", Address: 0xE94007, Size: 1},
+		{Name: "SCC_B_CMD", Address: 0xE98001, Size: 1},
+		{Name: "SCC_B_DATA", Address: 0xE98003, Size: 1},
+		{Name: "SCC_A_CMD", Address: 0xE98005, Size: 1},
+		{Name: "SCC_A_DATA", Address: 0xE98007, Size: 1},
+		{Name: "PPI_A", Address: 0xE9A001, Size: 1},
+		{Name: "PPI_B", Address: 0xE9A003, Size: 1},
+		{Name: "PPI_C", Address: 0xE9A005, Size: 1},
+		{Name: "PPI_CTRL", Address: 0xE9A007, Size: 1},
+		{Name: "SPRITE", Address: 0xEB0000, Size: 0x400},
+	}
+	regs = append(regs, series("CRTC_R%02d", 0xE80000, 2, 24)...)
+	for n := range 4 {
+		base := 0xE84000 + uint32(n)*0x40
+		for _, reg := range []Register{
+			{Name: "CSR", Address: 0x00, Size: 1}, {Name: "CER", Address: 0x01, Size: 1},
+			{Name: "DCR", Address: 0x04, Size: 1}, {Name: "OCR", Address: 0x05, Size: 1},
+			{Name: "SCR", Address: 0x06, Size: 1}, {Name: "CCR", Address: 0x07, Size: 1},
+			{Name: "MTC", Address: 0x0A, Size: 2}, {Name: "MAR", Address: 0x0C, Size: 4},
+			{Name: "DAR", Address: 0x14, Size: 4}, {Name: "BTC", Address: 0x1A, Size: 2},
+			{Name: "BAR", Address: 0x1C, Size: 4}, {Name: "NIV", Address: 0x25, Size: 1},
+			{Name: "EIV", Address: 0x27, Size: 1}, {Name: "MFC", Address: 0x29, Size: 1},
+			{Name: "CPR", Address: 0x2D, Size: 1}, {Name: "DFC", Address: 0x31, Size: 1},
+			{Name: "BFC", Address: 0x39, Size: 1},
+		} {
+			regs = append(regs, Register{Name: fmt.Sprintf("DMA%d_%s", n, reg.Name), Address: base + reg.Address, Size: reg.Size})
+		}
+	}
+	regs = append(regs, words(0xEB0800, []string{
+		"BG0_X", "BG0_Y", "BG1_X", "BG1_Y", "BG_CTRL", "SP_HTOTAL", "SP_HDISP", "SP_VDISP", "SP_RES",
+	})...)
+	regs = append(regs, mfp(0xE88001)...)
+	return New("X68000", mask24, regs)
+}
+
+// mfp returns the registers of an MC68901 MFP on the odd bytes from base.
+func mfp(base uint32) []Register {
+	names := []string{"GPIP", "AER", "DDR", "IERA", "IERB", "IPRA", "IPRB", "ISRA",
+		"ISRB", "IMRA", "IMRB", "VR", "TACR", "TBCR", "TCDCR", "TADR",
+		"TBDR", "TCDR", "TDDR", "SCR", "UCR", "RSR", "TSR", "UDR"}
+	regs := make([]Register, len(names))
+	for n, name := range names {
+		regs[n] = Register{Name: "MFP_" + name, Address: base + uint32(n)*2, Size: 1}
+	}
+	return regs
+}
+
+// words returns consecutive word registers from base; empty names are gaps.
+func words(base uint32, names []string) []Register {
+	var regs []Register
+	for n, name := range names {
+		if name != "" {
+			regs = append(regs, Register{Name: name, Address: base + uint32(n)*2, Size: 2})
+		}
+	}
+	return regs
+}
+
+// pointer returns an Amiga pointer register pair, e.g. BPL1PTH and BPL1PTL,
+// and the long register BPL1PT covering both.
+func pointer(address uint32, name string) []Register {
+	return []Register{
+		{Name: name, Address: address, Size: 4},
+		{Name: name + "H", Address: address, Size: 2},
+		{Name: name + "L", Address: address + 2, Size: 2},
+	}
+}
+
+// series returns count registers of one size named by format and index.
+func series(format string, base, size uint32, count int) []Register {
+	regs := make([]Register, count)
+	for n := range regs {
+		regs[n] = Register{Name: fmt.Sprintf(format, n), Address: base + uint32(n)*size, Size: size}
+	}
+	return regs
+}
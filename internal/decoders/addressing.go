@@ -89,20 +89,12 @@ func decodeAddressingMode(data []byte, mode, reg uint8, operandSize int) (string
 		if err := requireLength(data, 2, "index extension word"); err != nil {
 			return "", 0, Operand{}, err
 		}
-		indexWord := binary.BigEndian.Uint16(data[:2])
-		indexType, indexReg, indexSize, displacement := decodeIndexWord(indexWord)
-		text := fmt.Sprintf("(%d,A%d,%s%d.%c)", displacement, reg, indexType, indexReg, indexSize)
-		return text, 1, effectiveAddressOperand(text, EffectiveAddress{
-			Kind:         EAKindIndex,
-			Mode:         mode,
-			Register:     reg,
-			Base:         &Register{Kind: RegisterKindAddress, Number: reg},
-			Displacement: int32Ptr(int32(displacement)),
-			Index: &IndexRegister{
-				Register: Register{Kind: parseIndexRegisterKind(indexType), Number: indexReg},
-				Size:     string(indexSize),
-			},
-		}), nil
+		return decodeIndexedMode(data, EffectiveAddress{
+			Kind:     EAKindIndex,
+			Mode:     mode,
+			Register: reg,
+			Base:     &Register{Kind: RegisterKindAddress, Number: reg},
+		})
 
 	case 7:
 		// Special cases based on register field
@@ -154,20 +146,12 @@ func decodeAddressingMode(data []byte, mode, reg uint8, operandSize int) (string
 			if err := requireLength(data, 2, "pc index extension word"); err != nil {
 				return "", 0, Operand{}, err
 			}
-			indexWord := binary.BigEndian.Uint16(data[:2])
-			indexType, indexReg, indexSize, displacement := decodeIndexWord(indexWord)
-			text := fmt.Sprintf("(%d,PC,%s%d.%c)", displacement, indexType, indexReg, indexSize)
-			return text, 1, effectiveAddressOperand(text, EffectiveAddress{
-				Kind:         EAKindPCIndex,
-				Mode:         mode,
-				Register:     reg,
-				Base:         &Register{Kind: RegisterKindPC},
-				Displacement: int32Ptr(int32(displacement)),
-				Index: &IndexRegister{
-					Register: Register{Kind: parseIndexRegisterKind(indexType), Number: indexReg},
-					Size:     string(indexSize),
-				},
-			}), nil
+			return decodeIndexedMode(data, EffectiveAddress{
+				Kind:     EAKindPCIndex,
+				Mode:     mode,
+				Register: reg,
+				Base:     &Register{Kind: RegisterKindPC},
+			})
 
 		case 4: // Immediate Data
 			switch operandSize {
@@ -189,17 +173,20 @@ func decodeAddressingMode(data []byte, mode, reg uint8, operandSize int) (string
 				if err := requireLength(data, 2, "immediate"); err != nil {
 					return "", 0, Operand{}, err
 				}
-				value := uint32(binary.BigEndian.Uint16(data[:2]))
+				raw := uint32(binary.BigEndian.Uint16(data[:2]))
+				value := raw
 				if operandSize == 1 {
 					value &= 0xFF
 				}
 				text := fmt.Sprintf("#%s", formatImmediate(value, operandSize))
-				return text, 1, effectiveAddressOperand(text, EffectiveAddress{
+				operand := effectiveAddressOperand(text, EffectiveAddress{
 					Kind:      EAKindImmediate,
 					Mode:      mode,
 					Register:  reg,
 					Immediate: immediatePtr(value, operandSize),
-				}), nil
+				})
+				operand.nonCanonical = value != raw
+				return text, 1, operand, nil
 			default:
 				return "", 0, Operand{}, fmt.Errorf("unsupported immediate size: %d", operandSize)
 			}
@@ -235,7 +222,7 @@ func formatImmediate(value uint32, size int) string {
 // formatImmediateForMOVEQ formats a signed 8-bit immediate for MOVEQ
 func formatImmediateForMOVEQ(value int32) string {
 	if value < 0 {
-		return fmt.Sprintf("-$%X", -value)
+		return fmt.Sprintf("-$%X", -int64(value))
 	}
 	if value < 100 {
 		return fmt.Sprintf("%d", value)
@@ -243,25 +230,199 @@ func formatImmediateForMOVEQ(value int32) string {
 	return fmt.Sprintf("$%X", value)
 }
 
-// decodeIndexWord extracts index register, type, size, and displacement from index word
-func decodeIndexWord(indexWord uint16) (indexType string, indexReg, indexSize uint8, displacement int8) {
-	indexType = "D"
+// decodeIndexedMode decodes the extension words of modes 6 and 7/3. ea carries
+// the kind, mode and base register; the index, scale, displacements and memory
+// indirection are filled in from the brief or full extension word format.
+// Returns: (formatted string, extra words consumed, structured operand, error)
+func decodeIndexedMode(data []byte, ea EffectiveAddress) (string, int, Operand, error) {
+	indexWord := binary.BigEndian.Uint16(data[:2])
+	ea.Index = decodeIndexRegister(indexWord)
+	ea.Scale = uint8(1) << ((indexWord >> 9) & 0x3)
+
+	if indexWord&0x0100 == 0 {
+		// Brief format: 8-bit displacement, always base and index
+		ea.Displacement = int32Ptr(int32(int8(indexWord & 0xFF)))
+		text := formatIndexedMode(ea)
+		operand := effectiveAddressOperand(text, ea)
+		if ea.Scale != 1 {
+			operand.requires = Feature68020
+		}
+		return text, 1, operand, nil
+	}
+
+	// Full format (68020+): optional base/outer displacement, suppressed
+	// base/index and memory indirection
+	bdSize := (indexWord >> 4) & 0x3
+	indirect := indexWord & 0x7
+	indexSuppressed := indexWord&0x0040 != 0
+	if bdSize == 0 || indexWord&0x0008 != 0 || (indexSuppressed && indirect > 3) || (!indexSuppressed && indirect == 4) {
+		return "", 0, Operand{}, fmt.Errorf("reserved full extension word format: $%04X", indexWord)
+	}
+	if indexWord&0x0080 != 0 {
+		ea.Base = nil
+	}
+	if indexSuppressed {
+		ea.Index = nil
+		ea.Scale = 0
+	}
+
+	offset := 2
+	var err error
+	if ea.Displacement, offset, err = readDisplacement(data, offset, bdSize, "base displacement"); err != nil {
+		return "", 0, Operand{}, err
+	}
+	if indirect != 0 {
+		ea.Indirection = IndirectionPreIndexed
+		if indirect > 4 {
+			ea.Indirection = IndirectionPostIndexed
+		}
+		if ea.OuterDisplacement, offset, err = readDisplacement(data, offset, indirect&0x3, "outer displacement"); err != nil {
+			return "", 0, Operand{}, err
+		}
+	}
+
+	text := formatIndexedMode(ea)
+	operand := effectiveAddressOperand(text, ea)
+	operand.requires = Feature68020Full
+	operand.nonCanonical = !canonicalFullFormat(ea, bdSize, indirect&0x3)
+	return text, offset / 2, operand, nil
+}
+
+// canonicalFullFormat reports whether an assembler would choose this full
+// extension word for its text: the brief format and (d16,An) are shorter
+// where they apply, displacements are encoded in the smallest size and a
+// suppressed base register, which the text omits, is encoded as A0.
+func canonicalFullFormat(ea EffectiveAddress, bdSize, odSize uint16) bool {
+	if ea.Kind == EAKindIndex && ea.Base == nil && ea.Register != 0 {
+		return false
+	}
+	if ea.Indirection == IndirectionNone && ea.Base != nil {
+		var displacement int32
+		if ea.Displacement != nil {
+			displacement = *ea.Displacement
+		}
+		if ea.Index == nil && int32(int16(displacement)) == displacement {
+			return false
+		}
+		if ea.Index != nil && int32(int8(displacement)) == displacement {
+			return false
+		}
+	}
+	return minimalDisplacement(ea.Displacement, bdSize) && minimalDisplacement(ea.OuterDisplacement, odSize)
+}
+
+// minimalDisplacement reports whether a null (1), word (2) or long (3)
+// displacement uses the smallest size for its value.
+func minimalDisplacement(displacement *int32, size uint16) bool {
+	switch size {
+	case 2:
+		return *displacement != 0
+	case 3:
+		return int32(int16(*displacement)) != *displacement
+	}
+	return true
+}
+
+// readDisplacement reads a null (1), word (2) or long (3) displacement as used
+// by the full extension word format. A null displacement yields nil.
+func readDisplacement(data []byte, offset int, size uint16, context string) (*int32, int, error) {
+	switch size {
+	case 2:
+		if err := requireLength(data, offset+2, context); err != nil {
+			return nil, offset, err
+		}
+		return int32Ptr(int32(int16(binary.BigEndian.Uint16(data[offset:])))), offset + 2, nil
+	case 3:
+		if err := requireLength(data, offset+4, context); err != nil {
+			return nil, offset, err
+		}
+		return int32Ptr(int32(binary.BigEndian.Uint32(data[offset:]))), offset + 4, nil
+	default:
+		return nil, offset, nil
+	}
+}
+
+// decodeIndexRegister extracts the index register and its size from an extension word
+func decodeIndexRegister(indexWord uint16) *IndexRegister {
+	index := &IndexRegister{
+		Register: Register{Kind: RegisterKindData, Number: uint8((indexWord >> 12) & 0x7)},
+		Size:     "W",
+	}
 	if (indexWord>>15)&0x1 == 1 {
-		indexType = "A"
+		index.Register.Kind = RegisterKindAddress
 	}
-	indexReg = uint8((indexWord >> 12) & 0x7)
 	if (indexWord>>11)&0x1 == 1 {
-		indexSize = 'L'
-	} else {
-		indexSize = 'W'
+		index.Size = "L"
 	}
-	displacement = int8(indexWord & 0xFF)
-	return
+	return index
 }
 
-func parseIndexRegisterKind(indexType string) RegisterKind {
-	if indexType == "A" {
-		return RegisterKindAddress
+// formatIndexedMode renders an indexed effective address in Motorola syntax:
+// (d,An,Xn.S*k), ([bd,An,Xn.S*k],od) or ([bd,An],Xn.S*k,od).
+// Suppressed registers and null displacements are omitted.
+func formatIndexedMode(ea EffectiveAddress) string {
+	var base string
+	switch {
+	case ea.Base != nil && ea.Base.Kind == RegisterKindPC:
+		base = "PC"
+	case ea.Base != nil:
+		base = fmt.Sprintf("A%d", ea.Base.Number)
+	case ea.Kind == EAKindPCIndex:
+		base = "ZPC"
+	}
+	var index string
+	if ea.Index != nil {
+		index = fmt.Sprintf("%s%d.%s", registerPrefix(ea.Index.Register.Kind), ea.Index.Register.Number, ea.Index.Size)
+		if ea.Scale > 1 {
+			index += fmt.Sprintf("*%d", ea.Scale)
+		}
+	}
+	var displacement string
+	if ea.Displacement != nil {
+		displacement = fmt.Sprintf("%d", *ea.Displacement)
+	}
+
+	switch ea.Indirection {
+	case IndirectionPreIndexed:
+		return fmt.Sprintf("([%s]%s)", joinNonEmpty(displacement, base, index), prefixNonEmpty(ea.OuterDisplacement))
+	case IndirectionPostIndexed:
+		return fmt.Sprintf("([%s]%s)", joinNonEmpty(displacement, base), prefixNonEmpty(ea.OuterDisplacement, index))
+	default:
+		return fmt.Sprintf("(%s)", joinNonEmpty(displacement, base, index))
+	}
+}
+
+// joinNonEmpty joins the non-empty parts with commas; an all-empty address
+// renders as a zero displacement.
+func joinNonEmpty(parts ...string) string {
+	out := ""
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if out != "" {
+			out += ","
+		}
+		out += part
+	}
+	if out == "" {
+		return "0"
+	}
+	return out
+}
+
+// prefixNonEmpty renders the parts following the memory-indirect bracket,
+// each preceded by a comma: the optional index (post-indexed) and the outer
+// displacement.
+func prefixNonEmpty(outer *int32, parts ...string) string {
+	out := ""
+	for _, part := range parts {
+		if part != "" {
+			out += "," + part
+		}
+	}
+	if outer != nil {
+		out += fmt.Sprintf(",%d", *outer)
 	}
-	return RegisterKindData
+	return out
 }
@@ -20,6 +20,27 @@ func decodeSUB(data []byte, opcode uint16, inst *Instruction) error {
 	return decodeDirectedBinaryOp("SUB", data, opcode, inst)
 }
 
+// decodeADDX - Add Extended
+// Format: 1101 xxx1 ss00 myyy (m=0: Dy, Dx; m=1: -(Ay), -(Ax))
+func decodeADDX(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeExtendedArithmetic("ADDX", data, opcode, inst)
+}
+
+// decodeSUBX - Subtract Extended
+// Format: 1001 xxx1 ss00 myyy
+func decodeSUBX(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeExtendedArithmetic("SUBX", data, opcode, inst)
+}
+
+func decodeExtendedArithmetic(mnemonic string, data []byte, opcode uint16, inst *Instruction) error {
+	sizeBits := (opcode >> 6) & 0x3
+	if _, err := operandSize(sizeBits, mnemonic); err != nil {
+		return err
+	}
+	decodeRegisterOrPredecrementPair(mnemonic+"."+getSizeString(sizeBits), data, opcode, inst)
+	return nil
+}
+
 // decodeADDI - Add Immediate
 // Format: 0000 0110 sz 000 mmm rrr (sz: 00=Byte, 01=Word, 10=Long)
 func decodeADDI(data []byte, opcode uint16, inst *Instruction) error {
@@ -32,6 +53,44 @@ func decodeSUBI(data []byte, opcode uint16, inst *Instruction) error {
 	return decodeImmediateBinaryOp("SUBI", data, opcode, inst, true)
 }
 
+// decodeADDQ - Add Quick
+// Format: 0101 ddd 0 ss mmm rrr (ddd: 1-7, 0 bedeutet 8)
+func decodeADDQ(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeQuickArithmetic("ADDQ", data, opcode, inst)
+}
+
+// decodeSUBQ - Subtract Quick
+// Format: 0101 ddd 1 ss mmm rrr
+func decodeSUBQ(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeQuickArithmetic("SUBQ", data, opcode, inst)
+}
+
+func decodeQuickArithmetic(mnemonic string, data []byte, opcode uint16, inst *Instruction) error {
+	sizeBits := (opcode >> 6) & 0x3
+	sizeBytes, err := operandSize(sizeBits, mnemonic)
+	if err != nil {
+		return err
+	}
+	quick := uint32((opcode >> 9) & 0x7)
+	if quick == 0 {
+		quick = 8
+	}
+	dstMode := uint8((opcode >> 3) & 0x7)
+	dstReg := uint8(opcode & 0x7)
+	if err := checkEA(mnemonic, eaDestination, dstMode, dstReg, sizeBytes); err != nil {
+		return err
+	}
+
+	dstOperand, offset, dstMeta, err := decodeEAWithSize(data, 2, dstMode, dstReg, sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	immText := fmt.Sprintf("#%d", quick)
+	setInstruction(data, inst, offset, mnemonic+"."+getSizeString(sizeBits), fmt.Sprintf("%s, %s", immText, dstOperand), immediateOperand(immText, quick, 1), dstMeta)
+	return nil
+}
+
 func decodeImmediateBinaryOp(mnemonic string, data []byte, opcode uint16, inst *Instruction, longImmediate bool) error {
 	sizeStr, immSize, err := immediateSpec((opcode>>6)&0x3, longImmediate, mnemonic)
 	if err != nil {
@@ -40,11 +99,15 @@ func decodeImmediateBinaryOp(mnemonic string, data []byte, opcode uint16, inst *
 
 	dstMode := uint8((opcode >> 3) & 0x7)
 	dstReg := uint8(opcode & 0x7)
+	if err := checkEA(mnemonic, eaDestination, dstMode, dstReg, immSize); err != nil {
+		return err
+	}
 
 	immediate, offset, err := readImmediate(data, 2, immSize, mnemonic)
 	if err != nil {
 		return err
 	}
+	immediate, immSize, canonical := byteImmediate(sizeStr, immediate, immSize)
 
 	dstOperand, offset, dstMeta, err := decodeEA(data, offset, dstMode, dstReg)
 	if err != nil {
@@ -52,7 +115,9 @@ func decodeImmediateBinaryOp(mnemonic string, data []byte, opcode uint16, inst *
 	}
 
 	immText := fmt.Sprintf("#%s", formatImmediate(immediate, immSize))
-	setInstruction(data, inst, offset, fmt.Sprintf("%s.%s", mnemonic, sizeStr), fmt.Sprintf("%s, %s", immText, dstOperand), immediateOperand(immText, immediate, immSize), dstMeta)
+	immMeta := immediateOperand(immText, immediate, immSize)
+	immMeta.nonCanonical = !canonical
+	setInstruction(data, inst, offset, fmt.Sprintf("%s.%s", mnemonic, sizeStr), fmt.Sprintf("%s, %s", immText, dstOperand), immMeta, dstMeta)
 	return nil
 }
 
@@ -74,6 +139,9 @@ func decodeAddressRegisterArithmetic(mnemonic string, data []byte, opcode uint16
 		sizeBytes = 4
 	}
 
+	if err := checkEA(mnemonic+"A", eaSource, srcMode, srcReg, sizeBytes); err != nil {
+		return err
+	}
 	srcOperand, offset, srcMeta, err := decodeEAWithSize(data, 2, srcMode, srcReg, sizeBytes)
 	if err != nil {
 		return err
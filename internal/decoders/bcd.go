@@ -1,6 +1,9 @@
 package decoders
 
-import "fmt"
+import (
+	"encoding/binary"
+	"fmt"
+)
 
 func decodeABCD(data []byte, opcode uint16, inst *Instruction) error {
 	return decodeBCD("ABCD", data, opcode, inst)
@@ -10,24 +13,51 @@ func decodeSBCD(data []byte, opcode uint16, inst *Instruction) error {
 	return decodeBCD("SBCD", data, opcode, inst)
 }
 
+// decodeNBCD - Negate Decimal with Extend
+// Format: 0100 1000 00 mmm rrr
+func decodeNBCD(data []byte, opcode uint16, inst *Instruction) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("NBCD", eaDestination, mode, reg, 1); err != nil {
+		return err
+	}
+	operand, offset, meta, err := decodeEAWithSize(data, 2, mode, reg, 1)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, "NBCD", operand, meta)
+	return nil
+}
+
 func decodeBCD(mn string, data []byte, opcode uint16, inst *Instruction) error {
+	decodeRegisterOrPredecrementPair(mn, data, opcode, inst)
+	return nil
+}
+
+// decodePACK - Pack BCD (68020+)
+// Format: 1000 yyy1 0100 mxxx + 16-bit adjustment
+func decodePACK(data []byte, opcode uint16, inst *Instruction) error {
+	return decodePackUnpack("PACK", data, opcode, inst)
+}
+
+// decodeUNPK - Unpack BCD (68020+)
+// Format: 1000 yyy1 1000 mxxx + 16-bit adjustment
+func decodeUNPK(data []byte, opcode uint16, inst *Instruction) error {
+	return decodePackUnpack("UNPK", data, opcode, inst)
+}
+
+func decodePackUnpack(mn string, data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, mn+" adjustment"); err != nil {
+		return err
+	}
 	srcReg := uint8(opcode & 0x7)
 	dstReg := uint8((opcode >> 9) & 0x7)
-	addressingMode := (opcode >> 3) & 0x1
-	if addressingMode == 0 {
-		setInstruction(data, inst, 2, mn, fmt.Sprintf("D%d, D%d", srcReg, dstReg), registerOperand(RegisterKindData, srcReg), registerOperand(RegisterKindData, dstReg))
-		return nil
+	src, dst := registerOperand(RegisterKindData, srcReg), registerOperand(RegisterKindData, dstReg)
+	if (opcode>>3)&0x1 != 0 {
+		src, dst = preDecrementOperand(srcReg), preDecrementOperand(dstReg)
 	}
-	srcText := fmt.Sprintf("-(A%d)", srcReg)
-	dstText := fmt.Sprintf("-(A%d)", dstReg)
-	setInstruction(data, inst, 2, mn, fmt.Sprintf("%s, %s", srcText, dstText), effectiveAddressOperand(srcText, EffectiveAddress{
-		Kind:     EAKindPreDecrement,
-		Base:     &Register{Kind: RegisterKindAddress, Number: srcReg},
-		Register: srcReg,
-	}), effectiveAddressOperand(dstText, EffectiveAddress{
-		Kind:     EAKindPreDecrement,
-		Base:     &Register{Kind: RegisterKindAddress, Number: dstReg},
-		Register: dstReg,
-	}))
+	adjustment := uint32(binary.BigEndian.Uint16(data[2:4]))
+	immText := fmt.Sprintf("#%s", formatImmediate(adjustment, 2))
+	setInstruction(data, inst, 4, mn, fmt.Sprintf("%s, %s, %s", src.Text, dst.Text, immText), src, dst, immediateOperand(immText, adjustment, 2))
 	return nil
 }
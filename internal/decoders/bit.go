@@ -27,6 +27,13 @@ func decodeBitset(mn string, data []byte, opcode uint16, inst *Instruction) erro
 	offset := 2
 	var bitNumStr string
 	var bitOperand Operand
+	rule := mn
+	if (opcode>>8)&0x1 == 1 && mn == "BTST" {
+		rule = "BTST Dn"
+	}
+	if err := checkEA(rule, eaDestination, mode, reg, 0); err != nil {
+		return err
+	}
 	if (opcode>>8)&0x1 == 1 {
 		bitReg := uint8((opcode >> 9) & 0x7)
 		bitNumStr = fmt.Sprintf("D%d", bitReg)
@@ -36,9 +43,10 @@ func decodeBitset(mn string, data []byte, opcode uint16, inst *Instruction) erro
 			return err
 		}
 		bitNum := binary.BigEndian.Uint16(data[offset : offset+2])
-		bitNumStr = fmt.Sprintf("#%d", bitNum&0xFF)
+		bitNumStr = fmt.Sprintf("#%s", formatImmediate(uint32(bitNum&0xFF), 1))
 		offset += 2
 		bitOperand = immediateOperand(bitNumStr, uint32(bitNum&0xFF), 1)
+		bitOperand.nonCanonical = bitNum > 0xFF
 	}
 	operand, offset, eaMeta, err := decodeEA(data, offset, mode, reg)
 	if err != nil {
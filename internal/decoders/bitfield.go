@@ -0,0 +1,78 @@
+package decoders
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// bitfieldMnemonics is indexed by bits 10-8 of the bit-field opcode.
+var bitfieldMnemonics = [8]string{"BFTST", "BFEXTU", "BFCHG", "BFEXTS", "BFCLR", "BFFFO", "BFSET", "BFINS"}
+
+// decodeBitfield - Bit Field instructions (68020+)
+// Format: 1110 1ttt 11 mmm rrr + extension word
+// Extension: 0 rrr Do ooooo Dw wwwww; Do/Dw select a data register for the
+// offset/width, a width of 0 means 32.
+func decodeBitfield(data []byte, opcode uint16, inst *Instruction) error {
+	op := (opcode >> 8) & 0x7
+	mnemonic := bitfieldMnemonics[op]
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	role := eaSource
+	if op == 2 || op == 4 || op == 6 || op == 7 {
+		role = eaDestination
+	}
+	if err := checkEA(mnemonic, role, mode, reg, 4); err != nil {
+		return err
+	}
+	if err := requireLength(data, 4, mnemonic+" extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	eaStr, offset, eaMeta, err := decodeEAWithSize(data, 4, mode, reg, 4)
+	if err != nil {
+		return err
+	}
+
+	field, fieldText := decodeBitfieldSpec(ext)
+	eaMeta.Text = eaStr + fieldText
+	eaMeta.Bitfield = field
+	dataReg := uint8((ext >> 12) & 0x7)
+	dataMeta := registerOperand(RegisterKindData, dataReg)
+
+	switch mnemonic {
+	case "BFEXTU", "BFEXTS", "BFFFO":
+		setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, D%d", eaMeta.Text, dataReg), eaMeta, dataMeta)
+	case "BFINS":
+		setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("D%d, %s", dataReg, eaMeta.Text), dataMeta, eaMeta)
+	default:
+		setInstruction(data, inst, offset, mnemonic, eaMeta.Text, eaMeta)
+	}
+	return nil
+}
+
+// decodeBitfieldSpec extracts the {offset:width} specifier of a bit-field
+// extension word.
+func decodeBitfieldSpec(ext uint16) (*Bitfield, string) {
+	field := &Bitfield{}
+	var offsetText, widthText string
+	if ext&0x0800 != 0 {
+		reg := uint8((ext >> 6) & 0x7)
+		field.OffsetRegister = &Register{Kind: RegisterKindData, Number: reg}
+		offsetText = fmt.Sprintf("D%d", reg)
+	} else {
+		field.Offset = uint8((ext >> 6) & 0x1F)
+		offsetText = fmt.Sprintf("%d", field.Offset)
+	}
+	if ext&0x0020 != 0 {
+		reg := uint8(ext & 0x7)
+		field.WidthRegister = &Register{Kind: RegisterKindData, Number: reg}
+		widthText = fmt.Sprintf("D%d", reg)
+	} else {
+		field.Width = uint8(ext & 0x1F)
+		if field.Width == 0 {
+			field.Width = 32
+		}
+		widthText = fmt.Sprintf("%d", field.Width)
+	}
+	return field, fmt.Sprintf("{%s:%s}", offsetText, widthText)
+}
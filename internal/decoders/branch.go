@@ -10,6 +10,12 @@ var branchCondNames = [...]string{
 	"BVC", "BVS", "BPL", "BMI", "BGE", "BLT", "BGT", "BLE",
 }
 
+// conditionNames lists the 68000 condition codes in encoding order (bits 8-11).
+var conditionNames = [...]string{
+	"T", "F", "HI", "LS", "HS", "LO", "NE", "EQ",
+	"VC", "VS", "PL", "MI", "GE", "LT", "GT", "LE",
+}
+
 func decodeBxx(data []byte, opcode uint16, inst *Instruction) error {
 	offset := 2
 	condition := (opcode >> 8) & 0x0F
@@ -32,6 +38,7 @@ func decodeBxx(data []byte, opcode uint16, inst *Instruction) error {
 		if err := requireLength(data, offset+4, mnemonic+".L displacement"); err != nil {
 			return err
 		}
+		inst.Requires |= Feature68020
 		displacement32 := int32(binary.BigEndian.Uint32(data[offset : offset+4]))
 		offset += 4
 		target := uint32(int32(inst.Address) + int32(offset) + displacement32)
@@ -45,6 +52,68 @@ func decodeBxx(data []byte, opcode uint16, inst *Instruction) error {
 	return nil
 }
 
+// decodeDBcc - Test Condition, Decrement and Branch
+// Format: 0101 cccc 1100 1rrr + 16-bit displacement
+// DBF is rendered as DBRA, the spelling most assemblers emit.
+func decodeDBcc(data []byte, opcode uint16, inst *Instruction) error {
+	mnemonic := "DB" + conditionNames[(opcode>>8)&0x0F]
+	if mnemonic == "DBF" {
+		mnemonic = "DBRA"
+	}
+	reg := uint8(opcode & 0x7)
+	if err := requireLength(data, 4, mnemonic+" displacement"); err != nil {
+		return err
+	}
+	displacement := int16(binary.BigEndian.Uint16(data[2:4]))
+	target := uint32(int32(inst.Address) + 2 + int32(displacement))
+	targetText := formatBranchTarget(target)
+	setInstruction(data, inst, 4, mnemonic, fmt.Sprintf("D%d, %s", reg, targetText), registerOperand(RegisterKindData, reg), branchOperand(targetText, target))
+	return nil
+}
+
+// decodeScc - Set According to Condition
+// Format: 0101 cccc 11 mmm rrr (operand size is always byte)
+func decodeScc(data []byte, opcode uint16, inst *Instruction) error {
+	mnemonic := "S" + conditionNames[(opcode>>8)&0x0F]
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA(mnemonic, eaDestination, mode, reg, 1); err != nil {
+		return err
+	}
+	operand, offset, meta, err := decodeEAWithSize(data, 2, mode, reg, 1)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, mnemonic, operand, meta)
+	return nil
+}
+
+// decodeTRAPcc - Trap on Condition (68020+)
+// Format: 0101 cccc 1111 1ooo (ooo: 010=word operand, 011=long operand, 100=none)
+func decodeTRAPcc(data []byte, opcode uint16, inst *Instruction) error {
+	mnemonic := "TRAP" + conditionNames[(opcode>>8)&0x0F]
+	switch opcode & 0x7 {
+	case 2:
+		mnemonic += ".W"
+	case 3:
+		mnemonic += ".L"
+	default:
+		setInstruction(data, inst, 2, mnemonic, "")
+		return nil
+	}
+	size := 2
+	if opcode&0x7 == 3 {
+		size = 4
+	}
+	immediate, offset, err := readImmediate(data, 2, size, mnemonic)
+	if err != nil {
+		return err
+	}
+	immText := fmt.Sprintf("#%s", formatImmediate(immediate, size))
+	setInstruction(data, inst, offset, mnemonic, immText, immediateOperand(immText, immediate, size))
+	return nil
+}
+
 func formatBranchTarget(target uint32) string {
 	if target <= 0xFFFF {
 		return fmt.Sprintf("$%04X", target)
@@ -55,6 +124,9 @@ func formatBranchTarget(target uint32) string {
 func decodeJSR(data []byte, opcode uint16, inst *Instruction) error {
 	mode := uint8((opcode >> 3) & 0x7)
 	reg := uint8(opcode & 0x7)
+	if err := checkEA("JSR", eaDestination, mode, reg, 0); err != nil {
+		return err
+	}
 	operand, offset, meta, err := decodeEA(data, 2, mode, reg)
 	if err != nil {
 		return err
@@ -66,6 +138,9 @@ func decodeJSR(data []byte, opcode uint16, inst *Instruction) error {
 func decodeJMP(data []byte, opcode uint16, inst *Instruction) error {
 	mode := uint8((opcode >> 3) & 0x7)
 	reg := uint8(opcode & 0x7)
+	if err := checkEA("JMP", eaDestination, mode, reg, 0); err != nil {
+		return err
+	}
 	operand, offset, meta, err := decodeEA(data, 2, mode, reg)
 	if err != nil {
 		return err
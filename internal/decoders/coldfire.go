@@ -0,0 +1,364 @@
+package decoders
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// decodeMOV3Q - Move 3-Bit Data Quick (ColdFire ISA_B)
+// Format: 1010 ddd 101 mmm rrr; data 0 encodes -1
+func decodeMOV3Q(data []byte, opcode uint16, inst *Instruction) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("MOV3Q", eaDestination, mode, reg, 4); err != nil {
+		return err
+	}
+	value := int32((opcode >> 9) & 0x7)
+	if value == 0 {
+		value = -1
+	}
+	dst, offset, dstMeta, err := decodeEAWithSize(data, 2, mode, reg, 4)
+	if err != nil {
+		return err
+	}
+	immText := fmt.Sprintf("#%s", formatImmediateForMOVEQ(value))
+	setInstruction(data, inst, offset, "MOV3Q.L", fmt.Sprintf("%s, %s", immText, dst), signedImmediateOperand(immText, uint32(value), 4), dstMeta)
+	return nil
+}
+
+// decodeMVSMVZ - Move with Sign/Zero Extend (ColdFire ISA_B)
+// Format: 0111 rrr 1zs mmm rrr; z=1 MVZ, s=1 word source
+func decodeMVSMVZ(data []byte, opcode uint16, inst *Instruction) error {
+	mnemonic := "MVS"
+	if opcode&0x0080 != 0 {
+		mnemonic = "MVZ"
+	}
+	sizeStr, sizeBytes := "B", 1
+	if opcode&0x0040 != 0 {
+		sizeStr, sizeBytes = "W", 2
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA(mnemonic, eaSource, mode, reg, sizeBytes); err != nil {
+		return err
+	}
+	src, offset, srcMeta, err := decodeEAWithSize(data, 2, mode, reg, sizeBytes)
+	if err != nil {
+		return err
+	}
+	dst := registerOperand(RegisterKindData, uint8((opcode>>9)&0x7))
+	setInstruction(data, inst, offset, mnemonic+"."+sizeStr, fmt.Sprintf("%s, %s", src, dst.Text), srcMeta, dst)
+	return nil
+}
+
+// decodeSATS - Signed Saturate (ColdFire ISA_B)
+// Format: 0100 1100 1000 0rrr
+func decodeSATS(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeColdFireRegisterOp(data, opcode, inst, "SATS.L")
+}
+
+// decodeColdFireBitOp - BITREV, BYTEREV and FF1 (ColdFire ISA_C)
+// Format: 0000 0ss0 1100 0rrr; ss: 00 BITREV, 01 BYTEREV, 10 FF1
+func decodeColdFireBitOp(data []byte, opcode uint16, inst *Instruction) error {
+	mnemonics := [...]string{"BITREV.L", "BYTEREV.L", "FF1.L"}
+	return decodeColdFireRegisterOp(data, opcode, inst, mnemonics[(opcode>>9)&0x3])
+}
+
+func decodeColdFireRegisterOp(data []byte, opcode uint16, inst *Instruction, mnemonic string) error {
+	reg := registerOperand(RegisterKindData, uint8(opcode&0x7))
+	setInstruction(data, inst, 2, mnemonic, reg.Text, reg)
+	return nil
+}
+
+// decodeColdFireDIVL - DIVU.L/DIVS.L and REMU.L/REMS.L (ColdFire)
+// Format: 0100 1100 01 mmm rrr + 0 xxx s000 0000 0www
+// ColdFire reuses the 68020 DIVxL.L encoding (w != x) for the remainder-only
+// REMx.L <ea>,Dw:Dx.
+func decodeColdFireDIVL(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, "DIVx.L extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	dividend := uint8((ext >> 12) & 0x7)
+	remainder := uint8(ext & 0x7)
+	if ext&0x0400 != 0 || dividend == remainder {
+		return decodeDIVL(data, opcode, inst)
+	}
+	mnemonic := "REMU"
+	if ext&0x0800 != 0 {
+		mnemonic = "REMS"
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA(mnemonic, eaSource, mode, reg, 4); err != nil {
+		return err
+	}
+	src, offset, srcMeta, err := decodeEAWithSize(data, 4, mode, reg, 4)
+	if err != nil {
+		return err
+	}
+	dst := registerPairOperand(Register{Kind: RegisterKindData, Number: remainder}, Register{Kind: RegisterKindData, Number: dividend}, false)
+	setInstruction(data, inst, offset, mnemonic+".L", fmt.Sprintf("%s, %s", src, dst.Text), srcMeta, dst)
+	return nil
+}
+
+// coldFireControlRegisters maps the MOVEC control register field to the
+// ColdFire register. 0x004-0x008 differ from the 68040/68060 registers; parts
+// with on-chip flash call 0xC04 FLASHBAR and 0xC05 RAMBAR.
+var coldFireControlRegisters = map[uint16]string{
+	0x002: "CACR", 0x003: "ASID",
+	0x004: "ACR0", 0x005: "ACR1", 0x006: "ACR2", 0x007: "ACR3",
+	0x008: "MMUBAR",
+	0x800: "USP", 0x801: "VBR",
+	0xC00: "ROMBAR0", 0xC01: "ROMBAR1",
+	0xC04: "RAMBAR0", 0xC05: "RAMBAR1",
+	0xC0C: "MPCR", 0xC0D: "EDRAMBAR", 0xC0E: "SECMBAR", 0xC0F: "MBAR",
+}
+
+// decodeColdFireMOVEC - Move to Control Register (ColdFire, privileged)
+// Format: 0100 1110 0111 1011 + extension word (A/D, reg, 12-bit control register)
+// ColdFire only writes control registers; reads fall through to decodeMOVEC
+// and are rejected by ColdFireRequirement.
+func decodeColdFireMOVEC(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, "MOVEC extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	name, ok := coldFireControlRegisters[ext&0x0FFF]
+	if !ok {
+		return fmt.Errorf("unknown MOVEC control register: $%03X", ext&0x0FFF)
+	}
+	general := generalRegisterOperand(ext)
+	control := namedRegisterOperand(RegisterKindControl, name)
+	setInstruction(data, inst, 4, "MOVEC", fmt.Sprintf("%s, %s", general.Text, control.Text), general, control)
+	return nil
+}
+
+// coldFireRule describes which sizes and addressing modes of an instruction
+// ColdFire implements.
+type coldFireRule struct {
+	sizes  string  // size suffixes available on ISA_A; unsized forms always pass
+	sizesB string  // size suffixes added by ISA_B
+	modes  eaModes // legal modes of every operand
+	// longModes replaces modes for the .L form (MULx.L/DIVx.L/REMx.L).
+	longModes eaModes
+	// immediateModes restricts the other operands when the first is an
+	// immediate (static bit operations).
+	immediateModes eaModes
+	pairs          bool    // Dh:Dl register pairs are legal (REMx.L)
+	isa            Feature // instruction added after ISA_A
+}
+
+const (
+	cfDataRegister = eaDataRegister | eaImmediate
+	cfShortMemory  = eaIndirect | eaPostIncrement | eaPreDecrement | eaDisplacement
+)
+
+// coldFireRules lists every instruction ColdFire implements, keyed by
+// mnemonic base. Anything missing is 680x0-only.
+var coldFireRules = map[string]coldFireRule{
+	"MOVE":    {sizes: "BWL", modes: eaAll},
+	"MOVEA":   {sizes: "WL", modes: eaAll},
+	"MOVEQ":   {modes: eaAll},
+	"MOVEM":   {sizes: "L", modes: eaIndirect | eaDisplacement},
+	"MOVEC":   {modes: eaAll},
+	"CLR":     {sizes: "BWL", modes: eaAll},
+	"TST":     {sizes: "BWL", modes: eaAll},
+	"TAS":     {sizes: "B", modes: eaAll, isa: FeatureColdFireISAB},
+	"LEA":     {modes: eaAll},
+	"PEA":     {modes: eaAll},
+	"JMP":     {modes: eaAll},
+	"JSR":     {modes: eaAll},
+	"ADD":     {sizes: "L", modes: eaAll},
+	"SUB":     {sizes: "L", modes: eaAll},
+	"ADDA":    {sizes: "L", modes: eaAll},
+	"SUBA":    {sizes: "L", modes: eaAll},
+	"CMP":     {sizes: "L", sizesB: "BW", modes: eaAll},
+	"CMPA":    {sizes: "L", sizesB: "W", modes: eaAll},
+	"AND":     {sizes: "L", modes: eaAll},
+	"OR":      {sizes: "L", modes: eaAll},
+	"EOR":     {sizes: "L", modes: eaAll},
+	"ADDQ":    {sizes: "L", modes: eaAll},
+	"SUBQ":    {sizes: "L", modes: eaAll},
+	"ADDI":    {sizes: "L", modes: cfDataRegister},
+	"SUBI":    {sizes: "L", modes: cfDataRegister},
+	"ANDI":    {sizes: "L", modes: cfDataRegister},
+	"ORI":     {sizes: "L", modes: cfDataRegister},
+	"EORI":    {sizes: "L", modes: cfDataRegister},
+	"CMPI":    {sizes: "L", sizesB: "BW", modes: cfDataRegister},
+	"ADDX":    {sizes: "L", modes: cfDataRegister},
+	"SUBX":    {sizes: "L", modes: cfDataRegister},
+	"NEG":     {sizes: "L", modes: cfDataRegister},
+	"NEGX":    {sizes: "L", modes: cfDataRegister},
+	"NOT":     {sizes: "L", modes: cfDataRegister},
+	"EXT":     {sizes: "WL", modes: cfDataRegister},
+	"EXTB":    {sizes: "L", modes: cfDataRegister},
+	"SWAP":    {sizes: "W", modes: cfDataRegister},
+	"ASL":     {sizes: "L", modes: cfDataRegister},
+	"ASR":     {sizes: "L", modes: cfDataRegister},
+	"LSL":     {sizes: "L", modes: cfDataRegister},
+	"LSR":     {sizes: "L", modes: cfDataRegister},
+	"MULS":    {sizes: "WL", modes: eaAll, longModes: eaDataRegister | cfShortMemory},
+	"MULU":    {sizes: "WL", modes: eaAll, longModes: eaDataRegister | cfShortMemory},
+	"DIVS":    {sizes: "WL", modes: eaAll, longModes: eaDataRegister | cfShortMemory},
+	"DIVU":    {sizes: "WL", modes: eaAll, longModes: eaDataRegister | cfShortMemory},
+	"REMS":    {sizes: "L", longModes: eaDataRegister | cfShortMemory, pairs: true},
+	"REMU":    {sizes: "L", longModes: eaDataRegister | cfShortMemory, pairs: true},
+	"BTST":    {sizes: "BL", modes: eaAll, immediateModes: cfDataRegister | cfShortMemory},
+	"BCHG":    {sizes: "BL", modes: eaAll, immediateModes: cfDataRegister | cfShortMemory},
+	"BCLR":    {sizes: "BL", modes: eaAll, immediateModes: cfDataRegister | cfShortMemory},
+	"BSET":    {sizes: "BL", modes: eaAll, immediateModes: cfDataRegister | cfShortMemory},
+	"LINK":    {sizes: "W", modes: eaAll},
+	"UNLK":    {modes: eaAll},
+	"TRAP":    {modes: eaAll},
+	"TRAPF":   {sizes: "WL", modes: eaAll},
+	"STOP":    {modes: eaAll},
+	"RTE":     {},
+	"RTS":     {},
+	"NOP":     {},
+	"ILLEGAL": {},
+	"LINEA":   {modes: eaAll},
+	"LINEF":   {modes: eaAll},
+	"MOV3Q":   {sizes: "L", modes: eaAll},
+	"MVS":     {sizes: "BW", modes: eaAll},
+	"MVZ":     {sizes: "BW", modes: eaAll},
+	"SATS":    {sizes: "L", modes: eaAll},
+	"BITREV":  {sizes: "L", modes: eaAll},
+	"BYTEREV": {sizes: "L", modes: eaAll},
+	"FF1":     {sizes: "L", modes: eaAll},
+	"MAC":     {sizes: "WL", modes: eaAll},
+	"MSAC":    {sizes: "WL", modes: eaAll},
+	"MOVCLR":  {sizes: "L", modes: eaAll},
+}
+
+func init() {
+	for i, condition := range conditionNames {
+		branch := "B" + condition
+		switch i {
+		case 0:
+			branch = "BRA"
+		case 1:
+			branch = "BSR"
+		}
+		coldFireRules[branch] = coldFireRule{sizes: "SW", sizesB: "L"}
+		coldFireRules["S"+condition] = coldFireRule{modes: cfDataRegister}
+	}
+}
+
+// ColdFireRequirement reports what a decoded instruction needs beyond ColdFire
+// ISA_A: Feature680x0 for instructions, sizes and addressing combinations
+// ColdFire does not implement, or FeatureColdFireISAB for the ISA_B additions.
+func ColdFireRequirement(inst *Instruction) Feature {
+	meta := inst.Metadata
+	rule, ok := coldFireRules[meta.MnemonicBase]
+	if !ok {
+		return Feature680x0
+	}
+	required := rule.isa
+	if suffix := meta.SizeSuffix; suffix != "" && !strings.Contains(rule.sizes, suffix) {
+		if !strings.Contains(rule.sizesB, suffix) {
+			return Feature680x0
+		}
+		required |= FeatureColdFireISAB
+	}
+
+	allowed := rule.modes
+	if meta.SizeSuffix == "L" && rule.longModes != 0 {
+		allowed = rule.longModes
+	}
+	for i, operand := range meta.Operands {
+		if operand.RegisterPair != nil && !rule.pairs {
+			return Feature680x0
+		}
+		if isStatusRegister(operand) && meta.MnemonicBase != "MOVE" {
+			// ANDI/ORI/EORI to CCR and SR
+			return Feature680x0
+		}
+		if ea := operand.EffectiveAddress; ea != nil && (ea.Scale == 8 || ea.Index != nil && ea.Index.Size == "W") {
+			// ColdFire indexes with Xn.L only, scaled by 1, 2 or 4
+			return Feature680x0
+		}
+		modes := operandModes(operand)
+		if modes == 0 {
+			continue
+		}
+		if i > 0 && rule.immediateModes != 0 && meta.Operands[0].Kind == OperandKindImmediate && modes&rule.immediateModes == 0 {
+			return Feature680x0
+		}
+		if modes&allowed == 0 {
+			return Feature680x0
+		}
+	}
+
+	switch meta.MnemonicBase {
+	case "MOVE", "MOVEA":
+		return required | coldFireMOVERequirement(meta)
+	case "MOVEC":
+		// ColdFire control registers are write-only
+		if len(meta.Operands) > 0 && meta.Operands[0].Register != nil && meta.Operands[0].Register.Kind == RegisterKindControl {
+			return Feature680x0
+		}
+	}
+	return required
+}
+
+// coldFireMOVERequirement applies the ColdFire limit on the extension words of
+// MOVE: a source with an index, absolute address or immediate only combines
+// with a destination without extension words, and a (d16,An)/(d16,PC) source
+// not with an indexed or absolute destination. SR and CCR only move to and
+// from Dn or an immediate.
+func coldFireMOVERequirement(meta Metadata) Feature {
+	if len(meta.Operands) != 2 {
+		return 0
+	}
+	src, dst := meta.Operands[0], meta.Operands[1]
+	if isStatusRegister(src) || isStatusRegister(dst) {
+		if (operandModes(src)|operandModes(dst))&^cfDataRegister != 0 {
+			return Feature680x0
+		}
+		return 0
+	}
+	srcModes, dstModes := operandModes(src), operandModes(dst)
+	if srcModes == 0 || dstModes == 0 {
+		// USP and MAC register moves
+		return 0
+	}
+	extended := eaIndex | eaPCIndex | eaAbsoluteShort | eaAbsoluteLong | eaImmediate
+	simple := eaDataRegister | eaAddressRegister | eaIndirect | eaPostIncrement | eaPreDecrement
+	switch {
+	case srcModes&extended != 0 && dstModes&simple == 0:
+		if srcModes == eaImmediate && dstModes == eaDisplacement && meta.SizeSuffix != "L" {
+			return FeatureColdFireISAB
+		}
+		return Feature680x0
+	case srcModes&(eaDisplacement|eaPCDisplacement) != 0 && dstModes&(eaIndex|eaAbsoluteShort|eaAbsoluteLong) != 0:
+		return Feature680x0
+	}
+	return 0
+}
+
+func isStatusRegister(operand Operand) bool {
+	return operand.Register != nil && operand.Register.Kind == RegisterKindSystem &&
+		(operand.Register.Name == "SR" || operand.Register.Name == "CCR")
+}
+
+// operandModes maps an operand to its effective-address mode bit. Operands
+// that are not addressing modes (register lists, control registers, branch
+// targets) return 0.
+func operandModes(operand Operand) eaModes {
+	if ea := operand.EffectiveAddress; ea != nil {
+		return eaModeBit(ea.Mode, ea.Register)
+	}
+	switch {
+	case operand.Kind == OperandKindImmediate:
+		return eaImmediate
+	case operand.Register == nil:
+		return 0
+	case operand.Register.Kind == RegisterKindData:
+		return eaDataRegister
+	case operand.Register.Kind == RegisterKindAddress:
+		return eaAddressRegister
+	}
+	return 0
+}
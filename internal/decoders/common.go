@@ -63,6 +63,7 @@ func decodeEAWithSize(data []byte, offset int, mode, reg uint8, operandSize int)
 	if err != nil {
 		return "", offset, Operand{}, err
 	}
+	structured.pcOffset = offset
 	return operand, offset + extraWords*2, structured, nil
 }
 
@@ -77,6 +78,13 @@ func decodeDirectedBinaryOp(mnemonic string, data []byte, opcode uint16, inst *I
 	dstReg := uint8((opcode >> 9) & 0x7)
 	srcMode := uint8((opcode >> 3) & 0x7)
 	srcReg := uint8(opcode & 0x7)
+	role := eaSource
+	if direction != 0 {
+		role = eaDestination
+	}
+	if err := checkEA(mnemonic, role, srcMode, srcReg, operandSize); err != nil {
+		return err
+	}
 
 	srcOperand, offset, srcMeta, err := decodeEAWithSize(data, 2, srcMode, srcReg, operandSize)
 	if err != nil {
@@ -92,6 +100,28 @@ func decodeDirectedBinaryOp(mnemonic string, data []byte, opcode uint16, inst *I
 	return nil
 }
 
+// decodeRegisterOrPredecrementPair handles the shared Dy,Dx / -(Ay),-(Ax) operand
+// form used by ABCD, SBCD, ADDX and SUBX. Bit 3 selects the predecrement form.
+func decodeRegisterOrPredecrementPair(mnemonic string, data []byte, opcode uint16, inst *Instruction) {
+	srcReg := uint8(opcode & 0x7)
+	dstReg := uint8((opcode >> 9) & 0x7)
+	if (opcode>>3)&0x1 == 0 {
+		setInstruction(data, inst, 2, mnemonic, fmt.Sprintf("D%d, D%d", srcReg, dstReg), registerOperand(RegisterKindData, srcReg), registerOperand(RegisterKindData, dstReg))
+		return
+	}
+	src, dst := preDecrementOperand(srcReg), preDecrementOperand(dstReg)
+	setInstruction(data, inst, 2, mnemonic, fmt.Sprintf("%s, %s", src.Text, dst.Text), src, dst)
+}
+
+func preDecrementOperand(reg uint8) Operand {
+	return effectiveAddressOperand(fmt.Sprintf("-(A%d)", reg), EffectiveAddress{
+		Kind:     EAKindPreDecrement,
+		Mode:     4,
+		Base:     &Register{Kind: RegisterKindAddress, Number: reg},
+		Register: reg,
+	})
+}
+
 func operandSize(size uint16, mnemonic string) (int, error) {
 	switch size {
 	case 0:
@@ -136,6 +166,16 @@ func readImmediate(data []byte, offset, size int, mnemonic string) (uint32, int,
 	}
 }
 
+// byteImmediate keeps the low byte of a .B immediate; the CPU ignores the
+// high byte of its extension word. canonical is false when that byte is set,
+// as an assembler would not reproduce it.
+func byteImmediate(sizeStr string, value uint32, size int) (uint32, int, bool) {
+	if sizeStr == "B" {
+		return value & 0xFF, 1, value <= 0xFF
+	}
+	return value, size, true
+}
+
 type NeedMoreError struct {
 	Missing int
 	Context string
@@ -178,6 +218,34 @@ func registerOperand(kind RegisterKind, number uint8) Operand {
 	}
 }
 
+func namedRegisterOperand(kind RegisterKind, name string) Operand {
+	return Operand{
+		Text: name,
+		Kind: OperandKindRegister,
+		Register: &Register{
+			Kind: kind,
+			Name: name,
+		},
+	}
+}
+
+func registerPairOperand(first, second Register, indirect bool) Operand {
+	firstText := fmt.Sprintf("%s%d", registerPrefix(first.Kind), first.Number)
+	secondText := fmt.Sprintf("%s%d", registerPrefix(second.Kind), second.Number)
+	if indirect {
+		firstText, secondText = "("+firstText+")", "("+secondText+")"
+	}
+	return Operand{
+		Text: firstText + ":" + secondText,
+		Kind: OperandKindRegisterPair,
+		RegisterPair: &RegisterPair{
+			First:    first,
+			Second:   second,
+			Indirect: indirect,
+		},
+	}
+}
+
 func immediateOperand(text string, value uint32, size int) Operand {
 	imm := ImmediateValue{
 		Value:  value,
@@ -191,6 +259,14 @@ func immediateOperand(text string, value uint32, size int) Operand {
 	}
 }
 
+// signedImmediateOperand builds the immediate of MOVEQ, MOV3Q, LINK and RTD,
+// whose data the instruction sign-extends.
+func signedImmediateOperand(text string, value uint32, size int) Operand {
+	operand := immediateOperand(text, value, size)
+	operand.Immediate.SignExtended = true
+	return operand
+}
+
 func effectiveAddressOperand(text string, ea EffectiveAddress) Operand {
 	return Operand{
 		Text:             text,
@@ -250,6 +326,8 @@ func registerPrefix(kind RegisterKind) string {
 		return "A"
 	case RegisterKindPC:
 		return "PC"
+	case RegisterKindFP:
+		return "FP"
 	default:
 		return "D"
 	}
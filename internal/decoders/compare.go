@@ -1,19 +1,15 @@
 package decoders
 
-import "fmt"
+import (
+	"encoding/binary"
+	"fmt"
+)
 
+// decodeCMP - Compare
+// Format: 1011 ddd 0ss mmm rrr. CMPM, CMPA and EOR share the 0xB line and are
+// split off by the jump table before this decoder is reached.
 func decodeCMP(data []byte, opcode uint16, inst *Instruction) error {
 	opmode := (opcode >> 6) & 0x7
-	if (opcode & 0xF138) == 0xB108 {
-		return decodeCMPM(data, opcode, inst)
-	}
-	if opmode == 3 || opmode == 7 {
-		return decodeCMPA(data, opcode, inst)
-	}
-	if opmode >= 4 && opmode <= 6 {
-		return decodeEOR(data, opcode, inst)
-	}
-
 	sizeStr := getSizeString(opmode)
 	sizeBytes, err := operandSize(opmode, "CMP")
 	if err != nil {
@@ -23,6 +19,9 @@ func decodeCMP(data []byte, opcode uint16, inst *Instruction) error {
 	srcMode := uint8((opcode >> 3) & 0x7)
 	srcReg := uint8(opcode & 0x7)
 
+	if err := checkEA("CMP", eaSource, srcMode, srcReg, sizeBytes); err != nil {
+		return err
+	}
 	srcStr, offset, srcMeta, err := decodeEAWithSize(data, 2, srcMode, srcReg, sizeBytes)
 	if err != nil {
 		return err
@@ -44,6 +43,9 @@ func decodeCMPA(data []byte, opcode uint16, inst *Instruction) error {
 		sizeBytes = 4
 	}
 
+	if err := checkEA("CMPA", eaSource, srcMode, srcReg, sizeBytes); err != nil {
+		return err
+	}
 	srcStr, offset, srcMeta, err := decodeEAWithSize(data, 2, srcMode, srcReg, sizeBytes)
 	if err != nil {
 		return err
@@ -62,10 +64,12 @@ func decodeCMPM(data []byte, opcode uint16, inst *Instruction) error {
 	dstText := fmt.Sprintf("(A%d)+", dstReg)
 	setInstruction(data, inst, 2, "CMPM."+sizeStr, fmt.Sprintf("%s, %s", srcText, dstText), effectiveAddressOperand(srcText, EffectiveAddress{
 		Kind:     EAKindPostIncrement,
+		Mode:     3,
 		Base:     &Register{Kind: RegisterKindAddress, Number: srcReg},
 		Register: srcReg,
 	}), effectiveAddressOperand(dstText, EffectiveAddress{
 		Kind:     EAKindPostIncrement,
+		Mode:     3,
 		Base:     &Register{Kind: RegisterKindAddress, Number: dstReg},
 		Register: dstReg,
 	}))
@@ -73,21 +77,133 @@ func decodeCMPM(data []byte, opcode uint16, inst *Instruction) error {
 }
 
 func decodeCMPI(data []byte, opcode uint16, inst *Instruction) error {
-	sizeStr, immSize, err := immediateSpec((opcode>>6)&0x3, false, "CMPI")
+	sizeStr, immSize, err := immediateSpec((opcode>>6)&0x3, true, "CMPI")
 	if err != nil {
 		return err
 	}
 	dstMode := uint8((opcode >> 3) & 0x7)
 	dstReg := uint8(opcode & 0x7)
+	if err := checkEA("CMPI", eaDestination, dstMode, dstReg, immSize); err != nil {
+		// PC-relative destinations were added by the 68020 and CPU32
+		if checkEA("CMPI 68020", eaDestination, dstMode, dstReg, immSize) != nil {
+			return err
+		}
+		inst.Requires |= Feature68020
+	}
 	immediate, offset, err := readImmediate(data, 2, immSize, "CMPI")
 	if err != nil {
 		return err
 	}
+	immediate, immSize, canonical := byteImmediate(sizeStr, immediate, immSize)
 	dstOperand, offset, dstMeta, err := decodeEA(data, offset, dstMode, dstReg)
 	if err != nil {
 		return err
 	}
 	immText := fmt.Sprintf("#%s", formatImmediate(immediate, immSize))
-	setInstruction(data, inst, offset, "CMPI."+sizeStr, fmt.Sprintf("%s, %s", immText, dstOperand), immediateOperand(immText, immediate, immSize), dstMeta)
+	immMeta := immediateOperand(immText, immediate, immSize)
+	immMeta.nonCanonical = !canonical
+	setInstruction(data, inst, offset, "CMPI."+sizeStr, fmt.Sprintf("%s, %s", immText, dstOperand), immMeta, dstMeta)
+	return nil
+}
+
+// decodeCHK - Check Register Against Bounds
+// Format: 0100 ddd 1s0 mmm rrr (s=1: word, s=0: long, 68020+)
+func decodeCHK(data []byte, opcode uint16, inst *Instruction) error {
+	dstReg := uint8((opcode >> 9) & 0x7)
+	srcMode := uint8((opcode >> 3) & 0x7)
+	srcReg := uint8(opcode & 0x7)
+	mnemonic, sizeBytes := "CHK", 2
+	if opcode&0x0080 == 0 {
+		mnemonic, sizeBytes = "CHK.L", 4
+	}
+	if err := checkEA("CHK", eaSource, srcMode, srcReg, sizeBytes); err != nil {
+		return err
+	}
+	srcStr, offset, srcMeta, err := decodeEAWithSize(data, 2, srcMode, srcReg, sizeBytes)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, D%d", srcStr, dstReg), srcMeta, registerOperand(RegisterKindData, dstReg))
+	return nil
+}
+
+// decodeCHK2CMP2 - Check/Compare Register Against Bounds (68020+)
+// Format: 0000 0ss0 11 mmm rrr + extension word (A/D, reg, bit 11: 1=CHK2)
+func decodeCHK2CMP2(data []byte, opcode uint16, inst *Instruction) error {
+	sizeBits := (opcode >> 9) & 0x3
+	sizeBytes, err := operandSize(sizeBits, "CHK2/CMP2")
+	if err != nil {
+		return err
+	}
+	if err := requireLength(data, 4, "CHK2/CMP2 extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	mnemonic := "CMP2"
+	if ext&0x0800 != 0 {
+		mnemonic = "CHK2"
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA(mnemonic, eaSource, mode, reg, sizeBytes); err != nil {
+		return err
+	}
+	srcStr, offset, srcMeta, err := decodeEAWithSize(data, 4, mode, reg, sizeBytes)
+	if err != nil {
+		return err
+	}
+	general := generalRegisterOperand(ext)
+	setInstruction(data, inst, offset, mnemonic+"."+getSizeString(sizeBits), fmt.Sprintf("%s, %s", srcStr, general.Text), srcMeta, general)
+	return nil
+}
+
+// decodeCAS - Compare and Swap with Operand (68020+)
+// Format: 0000 1ss0 11 mmm rrr + extension word (0000 000u uu00 0ccc)
+// ss: 01=Byte, 10=Word, 11=Long
+func decodeCAS(data []byte, opcode uint16, inst *Instruction) error {
+	sizeBits := ((opcode >> 9) & 0x3) - 1
+	sizeBytes, err := operandSize(sizeBits, "CAS")
+	if err != nil {
+		return err
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("CAS", eaDestination, mode, reg, sizeBytes); err != nil {
+		return err
+	}
+	if err := requireLength(data, 4, "CAS extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	compareReg := uint8(ext & 0x7)
+	updateReg := uint8((ext >> 6) & 0x7)
+	dstStr, offset, dstMeta, err := decodeEAWithSize(data, 4, mode, reg, sizeBytes)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, "CAS."+getSizeString(sizeBits), fmt.Sprintf("D%d, D%d, %s", compareReg, updateReg, dstStr),
+		registerOperand(RegisterKindData, compareReg), registerOperand(RegisterKindData, updateReg), dstMeta)
+	return nil
+}
+
+// decodeCAS2 - Compare and Swap with Two Operands (68020+)
+// Format: 0000 1ss0 1111 1100 + two extension words (D/A rrr 000u uu00 0ccc)
+func decodeCAS2(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 6, "CAS2 extension words"); err != nil {
+		return err
+	}
+	sizeStr := "W"
+	if (opcode>>9)&0x3 == 3 {
+		sizeStr = "L"
+	}
+	ext1 := binary.BigEndian.Uint16(data[2:4])
+	ext2 := binary.BigEndian.Uint16(data[4:6])
+	dataReg := func(ext uint16, shift uint) Register {
+		return Register{Kind: RegisterKindData, Number: uint8((ext >> shift) & 0x7)}
+	}
+	compare := registerPairOperand(dataReg(ext1, 0), dataReg(ext2, 0), false)
+	update := registerPairOperand(dataReg(ext1, 6), dataReg(ext2, 6), false)
+	memory := registerPairOperand(*generalRegisterOperand(ext1).Register, *generalRegisterOperand(ext2).Register, true)
+	setInstruction(data, inst, 6, "CAS2."+sizeStr, fmt.Sprintf("%s, %s, %s", compare.Text, update.Text, memory.Text), compare, update, memory)
 	return nil
 }
@@ -0,0 +1,82 @@
+package decoders
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// decodeCPU32 - CPU32 table lookup and low-power stop
+// Format: 1111 1000 00 mmm rrr + extension word
+// LPSTOP: 1111 1000 0000 0000 + 0000 0001 1100 0000 + 16-bit immediate
+// TBLx:   extension 0 xxx S R 0 M ss 00 0 yyy; S=1 signed, R=1 unrounded (TBLxN),
+// M=1 with mode 000 selects the register interpolate form Dym:Dyn.
+func decodeCPU32(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, "CPU32 extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	if opcode == 0xF800 && ext == 0x01C0 {
+		return decodeLPSTOP(data, inst)
+	}
+	return decodeTBL(data, opcode, ext, inst)
+}
+
+// decodeLPSTOP - Low-Power Stop (CPU32, privileged)
+func decodeLPSTOP(data []byte, inst *Instruction) error {
+	if err := requireLength(data, 6, "LPSTOP immediate"); err != nil {
+		return err
+	}
+	immediate := binary.BigEndian.Uint16(data[4:6])
+	immText := fmt.Sprintf("#%s", formatImmediate(uint32(immediate), 2))
+	setInstruction(data, inst, 6, "LPSTOP", immText, immediateOperand(immText, uint32(immediate), 2))
+	return nil
+}
+
+// decodeTBL - Table Lookup and Interpolate (CPU32)
+// TBLS/TBLU round the interpolated result, TBLSN/TBLUN return it unrounded
+// with the fraction in the low byte.
+func decodeTBL(data []byte, opcode, ext uint16, inst *Instruction) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	registerForm := ext&0x0100 != 0
+	if ext&0x8238 != 0 || registerForm != (mode == 0) {
+		return fmt.Errorf("invalid TBL extension word: $%04X", ext)
+	}
+	sizeBits := (ext >> 6) & 0x3
+	sizeBytes, err := operandSize(sizeBits, "TBL")
+	if err != nil {
+		return err
+	}
+	mnemonic := "TBLU"
+	if ext&0x0800 != 0 {
+		mnemonic = "TBLS"
+	}
+	if ext&0x0400 != 0 {
+		mnemonic += "N"
+	}
+	mnemonic += "." + getSizeString(sizeBits)
+
+	if err := checkEA("TBL", eaSource, mode, reg, sizeBytes); err != nil {
+		return err
+	}
+	dst := registerOperand(RegisterKindData, uint8((ext>>12)&0x7))
+
+	if registerForm {
+		pair := registerPairOperand(
+			Register{Kind: RegisterKindData, Number: reg},
+			Register{Kind: RegisterKindData, Number: uint8(ext & 0x7)},
+			false,
+		)
+		setInstruction(data, inst, 4, mnemonic, fmt.Sprintf("%s, %s", pair.Text, dst.Text), pair, dst)
+		return nil
+	}
+	src, offset, srcMeta, err := decodeEAWithSize(data, 4, mode, reg, sizeBytes)
+	if err != nil {
+		return err
+	}
+	if ext&0x0007 != 0 {
+		return fmt.Errorf("invalid TBL extension word: $%04X", ext)
+	}
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", src, dst.Text), srcMeta, dst)
+	return nil
+}
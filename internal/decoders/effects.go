@@ -0,0 +1,296 @@
+package decoders
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// access describes how an instruction uses an operand.
+type access uint8
+
+const (
+	accessRead access = 1 << iota
+	accessWrite
+	// accessAddress marks an <ea> whose address is computed but not
+	// accessed, as for LEA, PEA, JMP and JSR.
+	accessAddress
+
+	accessNone      access = 0
+	accessReadWrite        = accessRead | accessWrite
+)
+
+// stackPointer is A7, pushed and popped by subroutine calls and returns.
+var stackPointer = Register{Kind: RegisterKindAddress, Number: 7}
+
+// fpuMonadic holds the FPU operations computed from the source alone, such
+// as FSQRT or FMOVE; they write the destination without reading it.
+var fpuMonadic = func() map[string]bool {
+	monadic := map[string]bool{}
+	for opmode, name := range fpuOpmodes {
+		if !isFPUDyadic(opmode) {
+			monadic[name] = true
+		}
+	}
+	return monadic
+}()
+
+// registerEffects returns the registers an instruction reads and writes:
+// operand registers by the role the instruction gives them, the base and
+// index registers of memory operands, the An of (An)+ and -(An), the
+// registers of MOVEM lists and the implicit A7 of calls, returns, PEA and
+// LINK. Writing the low byte or word of a data register keeps the rest, so
+// such a write also counts as a read. Condition codes set as a side effect
+// and exception processing are not included.
+func registerEffects(base, suffix string, operands []Operand) (reads, writes []Register) {
+	width := writeWidth(base, suffix)
+	accesses := operandAccesses(base, operands)
+	read := func(reg Register) {
+		reads = addRegister(reads, reg)
+	}
+	use := func(reg Register, acc access) {
+		if acc&accessRead != 0 || (acc&accessWrite != 0 && reg.Kind == RegisterKindData && width < 4) {
+			read(reg)
+		}
+		if acc&accessWrite != 0 {
+			writes = addRegister(writes, reg)
+		}
+	}
+
+	for i, operand := range operands {
+		acc := accesses[i]
+		switch operand.Kind {
+		case OperandKindRegister:
+			use(*operand.Register, acc)
+		case OperandKindRegisterList:
+			for _, name := range operand.RegisterList {
+				use(registerFromName(name), acc)
+			}
+		case OperandKindRegisterPair:
+			pair := operand.RegisterPair
+			if pair.Indirect {
+				read(pair.First)
+				read(pair.Second)
+				break
+			}
+			first, second := registerPairAccess(base, acc)
+			use(pair.First, first)
+			use(pair.Second, second)
+		case OperandKindEffectiveAddr:
+			ea := operand.EffectiveAddress
+			switch ea.Kind {
+			case EAKindDataRegisterDirect, EAKindAddressRegisterDirect:
+				use(*ea.Base, acc)
+			case EAKindPostIncrement, EAKindPreDecrement:
+				use(*ea.Base, accessReadWrite)
+			default:
+				if ea.Base != nil {
+					read(*ea.Base)
+				}
+			}
+			if ea.Index != nil {
+				read(ea.Index.Register)
+			}
+			if bf := operand.Bitfield; bf != nil {
+				if bf.OffsetRegister != nil {
+					read(*bf.OffsetRegister)
+				}
+				if bf.WidthRegister != nil {
+					read(*bf.WidthRegister)
+				}
+			}
+			var kFactor uint8
+			if _, err := fmt.Sscanf(operand.Suffix, "{D%d}", &kFactor); err == nil {
+				read(Register{Kind: RegisterKindData, Number: kFactor})
+			}
+		}
+	}
+
+	switch base {
+	case "BSR", "JSR", "PEA", "RTS", "RTR", "RTE", "RTD", "LINK", "CALLM", "RTM":
+		use(stackPointer, accessReadWrite)
+	case "UNLK":
+		use(stackPointer, accessWrite)
+	}
+	switch base {
+	case "RTE", "STOP", "LPSTOP":
+		use(Register{Kind: RegisterKindSystem, Name: "SR"}, accessWrite)
+	case "RTR":
+		use(Register{Kind: RegisterKindSystem, Name: "CCR"}, accessWrite)
+	}
+
+	slices.SortFunc(reads, compareRegisters)
+	slices.SortFunc(writes, compareRegisters)
+	return reads, writes
+}
+
+// operandAccesses returns how the instruction uses each operand. Unlisted
+// instructions read their sources and read and write the last operand, as
+// ADD, SUB and the shifts do.
+func operandAccesses(base string, operands []Operand) []access {
+	r, w, rw, a, n := accessRead, accessWrite, accessReadWrite, accessAddress, accessNone
+	var accesses []access
+	switch base {
+	case "MOVE", "MOVEA", "MOVEQ", "MOV3Q", "MVS", "MVZ", "MOVEP", "MOVES", "MOVEC", "MOVE16",
+		"MOVEM", "FMOVEM", "FMOVECR", "PMOVE", "PMOVEFD", "BFEXTU", "BFEXTS", "BFFFO", "FSINCOS":
+		accesses = []access{r, w}
+	case "CMP", "CMPA", "CMPI", "CMPM", "CMP2", "CHK", "CHK2", "BTST", "TST", "BFTST", "FCMP", "FTST":
+		accesses = []access{r, r}
+	case "CLR", "RTM":
+		accesses = []access{w}
+	case "LEA":
+		accesses = []access{a, w}
+	case "PEA", "JMP", "JSR", "PLPAR", "PLPAW":
+		accesses = []access{a}
+	case "PFLUSH", "PFLUSHN", "PFLUSHS":
+		// PFLUSH (An) of the 68040, or fc, #mask[, <ea>] of the 68851/68030
+		accesses = []access{a}
+		if len(operands) > 1 {
+			accesses = []access{r, r, a}
+		}
+	case "PTESTR", "PTESTW":
+		// PTESTR (An) of the 68040, or fc, <ea>, #level[, An] of the 68851/68030
+		accesses = []access{a}
+		if len(operands) > 1 {
+			accesses = []access{r, a, n, w}
+		}
+	case "PLOADR", "PLOADW":
+		accesses = []access{r, a}
+	case "CINVL", "CINVP", "CPUSHL", "CPUSHP", "CALLM":
+		accesses = []access{n, a}
+	case "EXG":
+		accesses = []access{rw, rw}
+	case "LINK":
+		accesses = []access{rw, n}
+	case "CAS", "CAS2":
+		accesses = []access{rw, r, rw}
+	case "PACK", "UNPK":
+		accesses = []access{r, rw, n}
+	case "MOVCLR":
+		accesses = []access{rw, w}
+	case "MAC", "MSAC":
+		return macAccesses(operands)
+	}
+	switch {
+	case accesses != nil:
+	case isConditional(base, "S", conditionNames[:]), isConditional(base, "FS", fpuConditionNames[:]):
+		accesses = []access{w}
+	case isConditional(base, "DB", conditionNames[:]), base == "DBRA", isConditional(base, "FDB", fpuConditionNames[:]):
+		accesses = []access{rw, n}
+	case fpuMonadic[base] && len(operands) == 2:
+		accesses = []access{r, w}
+	default:
+		accesses = make([]access, len(operands))
+		for i := range accesses {
+			accesses[i] = r
+		}
+		if len(accesses) > 0 {
+			accesses[len(accesses)-1] = rw
+		}
+	}
+	for len(accesses) < len(operands) {
+		accesses = append(accesses, n)
+	}
+	return accesses
+}
+
+// macAccesses handles MAC Ry, Rx[, <<][, <ea>, Rw], ACCx: the factors and a
+// loaded <ea> are read, Rw receives the load and the accumulator is updated.
+func macAccesses(operands []Operand) []access {
+	accesses := make([]access, len(operands))
+	for i := range operands {
+		switch {
+		case i == len(operands)-1:
+			accesses[i] = accessReadWrite
+		case i > 0 && operands[i-1].Kind == OperandKindEffectiveAddr:
+			accesses[i] = accessWrite
+		default:
+			accesses[i] = accessRead
+		}
+	}
+	return accesses
+}
+
+// registerPairAccess splits the access of a register pair. MULx.L Dh:Dl reads
+// Dl and writes both, DIVxL.L Dr:Dq reads Dq and writes both, the 64-bit
+// DIVx.L Dr:Dq reads and writes both and REMx.L Dw:Dx reads Dx and writes Dw.
+func registerPairAccess(base string, acc access) (first, second access) {
+	switch base {
+	case "MULS", "MULU", "DIVSL", "DIVUL":
+		return accessWrite, accessReadWrite
+	case "DIVS", "DIVU":
+		return accessReadWrite, accessReadWrite
+	case "REMS", "REMU":
+		return accessWrite, accessRead
+	}
+	return acc, acc
+}
+
+// writeWidth returns how many bytes of a data register a write replaces: the
+// operation size, except for MOVEM, MVS and MVZ, which extend to the full
+// register, and 4 when the instruction has no size. Scc and the BCD
+// instructions are byte operations without a suffix.
+func writeWidth(base, suffix string) int {
+	switch {
+	case base == "MOVEM", base == "MVS", base == "MVZ", base == "MAC", base == "MSAC":
+		return 4
+	case suffix == "B":
+		return 1
+	case suffix == "W":
+		return 2
+	case suffix == "" && (base == "ABCD" || base == "SBCD" || base == "NBCD" || base == "TAS" ||
+		isConditional(base, "S", conditionNames[:]) || isConditional(base, "FS", fpuConditionNames[:])):
+		return 1
+	}
+	return 4
+}
+
+// isConditional reports whether base is prefix followed by a condition.
+func isConditional(base, prefix string, conditions []string) bool {
+	condition, ok := strings.CutPrefix(base, prefix)
+	return ok && slices.Contains(conditions, condition)
+}
+
+// registerFromName parses the register names of MOVEM and FMOVEM lists.
+func registerFromName(name string) Register {
+	switch {
+	case len(name) == 2 && name[0] == 'D':
+		return Register{Kind: RegisterKindData, Number: name[1] - '0'}
+	case len(name) == 2 && name[0] == 'A':
+		return Register{Kind: RegisterKindAddress, Number: name[1] - '0'}
+	case len(name) == 3 && strings.HasPrefix(name, "FP"):
+		return Register{Kind: RegisterKindFP, Number: name[2] - '0'}
+	}
+	return Register{Kind: RegisterKindFPControl, Name: name}
+}
+
+// addRegister adds reg to a set. The PC and cache selectors are no registers
+// data flow can track.
+func addRegister(set []Register, reg Register) []Register {
+	if reg.Kind == RegisterKindPC || reg.Kind == RegisterKindCache || slices.Contains(set, reg) {
+		return set
+	}
+	return append(set, reg)
+}
+
+// registerKindOrder sorts register sets: data and address registers first,
+// then FPU and the named registers.
+var registerKindOrder = map[RegisterKind]int{
+	RegisterKindData:      0,
+	RegisterKindAddress:   1,
+	RegisterKindFP:        2,
+	RegisterKindFPControl: 3,
+	RegisterKindSystem:    4,
+	RegisterKindControl:   5,
+	RegisterKindMMU:       6,
+	RegisterKindMAC:       7,
+}
+
+func compareRegisters(a, b Register) int {
+	return cmp.Or(
+		cmp.Compare(registerKindOrder[a.Kind], registerKindOrder[b.Kind]),
+		cmp.Compare(a.Number, b.Number),
+		cmp.Compare(a.Name, b.Name),
+	)
+}
@@ -0,0 +1,133 @@
+package decoders
+
+import "strings"
+
+// Feature is a set of instruction-set capabilities. Opcode patterns, decoded
+// instructions and addressing forms name the feature they need; a CPU model
+// enables a combination of them.
+type Feature uint32
+
+const (
+	// Feature68010 covers MOVEC, MOVES, RTD, BKPT and MOVE from CCR.
+	Feature68010 Feature = 1 << iota
+	// Feature68020 covers the 68020 additions shared with CPU32: CHK2/CMP2,
+	// 32-bit MUL/DIV, EXTB.L, LINK.L, CHK.L, TRAPcc, Bcc.L, scaled indexing and
+	// the extended TST/CMPI addressing modes.
+	Feature68020
+	// Feature68020Full covers what the 68020 has beyond CPU32: bit fields,
+	// CAS/CAS2, PACK/UNPK, the full extension-word addressing modes and the
+	// CACR control register.
+	Feature68020Full
+	// FeatureCALLM covers CALLM/RTM, which only the 68020 implements.
+	FeatureCALLM
+	// FeatureFPU covers the 68881/68882 and on-chip 68040/68060 FPU.
+	FeatureFPU
+	// FeaturePMMU covers the PMOVE, PLOAD, PFLUSH and PTEST forms shared by
+	// the 68851 and the 68030 on-chip MMU.
+	FeaturePMMU
+	// Feature68851 covers the 68851 DRP, CAL, VAL, SCC, AC and PCSR registers
+	// and PFLUSHS.
+	Feature68851
+	// Feature68030MMU covers the 68030 TT0/TT1 registers and PMOVEFD.
+	Feature68030MMU
+	// Feature68040 covers MOVE16, CINV/CPUSH, the 68040 PFLUSH forms and the
+	// 68040 MOVEC registers, all shared by the 68060.
+	Feature68040
+	// FeaturePTEST040 covers the 68040 PTEST and the MMUSR register it
+	// reports to, both dropped again by the 68060.
+	FeaturePTEST040
+	// FeatureCAAR covers the CAAR control register of the 68020 and 68030.
+	FeatureCAAR
+	// FeatureMSP covers the MSP and ISP control registers, which the 68060
+	// dropped along with the master stack.
+	FeatureMSP
+	// Feature68060 covers PLPA and the 68060 MOVEC registers.
+	Feature68060
+	// FeatureCPU32 covers the CPU32 TBLS/TBLU/TBLSN/TBLUN and LPSTOP.
+	FeatureCPU32
+	// Feature680x0 covers the instructions, sizes and addressing combinations
+	// ColdFire dropped. Only ColdFireRequirement reports it.
+	Feature680x0
+	// FeatureColdFire covers REMS/REMU and enables the ColdFireRequirement
+	// checks.
+	FeatureColdFire
+	// FeatureColdFireISAB covers MOV3Q, MVS, MVZ, SATS, TAS, Bcc.L, CMP.B/.W
+	// and MOVE.B/.W #imm,(d16,An).
+	FeatureColdFireISAB
+	// FeatureColdFireISAC covers BITREV, BYTEREV and FF1.
+	FeatureColdFireISAC
+	// FeatureMAC covers the MAC/EMAC multiply-accumulate unit in the A-line.
+	FeatureMAC
+)
+
+// FeatureAll enables every pattern in the dispatch table.
+const FeatureAll Feature = FeatureMAC<<1 - 1
+
+var featureNames = []struct {
+	feature Feature
+	name    string
+}{
+	{Feature68010, "68010"},
+	{Feature68020, "68020"},
+	{Feature68020Full, "68020 full ISA"},
+	{FeatureCALLM, "68020 CALLM/RTM"},
+	{FeatureFPU, "FPU"},
+	{FeaturePMMU, "68851/68030 PMMU"},
+	{Feature68851, "68851"},
+	{Feature68030MMU, "68030 MMU"},
+	{Feature68040, "68040"},
+	{FeaturePTEST040, "68040 PTEST/MMUSR"},
+	{FeatureCAAR, "68020/68030 CAAR"},
+	{FeatureMSP, "68020-68040 MSP/ISP"},
+	{Feature68060, "68060"},
+	{FeatureCPU32, "CPU32"},
+	{Feature680x0, "680x0"},
+	{FeatureColdFire, "ColdFire"},
+	{FeatureColdFireISAB, "ColdFire ISA_B"},
+	{FeatureColdFireISAC, "ColdFire ISA_C"},
+	{FeatureMAC, "ColdFire MAC"},
+}
+
+// String names the features in the set, e.g. "68020" or "FPU".
+func (f Feature) String() string {
+	var names []string
+	for _, entry := range featureNames {
+		if f&entry.feature != 0 {
+			names = append(names, entry.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// LookupDecoder returns the decoder for opcode when it is available with the
+// given features. Otherwise it returns the feature the first matching pattern
+// requires; Optional patterns (coprocessor and ISA-specific alternatives) are
+// skipped so the opcode can still reach a later pattern such as LINEF.
+func LookupDecoder(opcode uint16, features Feature) (OpcodeDecoder, Feature) {
+	var missing Feature
+	for _, pattern := range opcodeBuckets[opcode>>12] {
+		if opcode&pattern.Mask != pattern.Value {
+			continue
+		}
+		if features&pattern.Requires == pattern.Requires {
+			return pattern.Decoder, 0
+		}
+		if missing == 0 {
+			missing = pattern.Requires
+		}
+		if !pattern.Optional {
+			break
+		}
+	}
+	return nil, missing
+}
+
+func (p OpcodePattern) requires(feature Feature) OpcodePattern {
+	p.Requires = feature
+	return p
+}
+
+func (p OpcodePattern) optional() OpcodePattern {
+	p.Optional = true
+	return p
+}
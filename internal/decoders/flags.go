@@ -0,0 +1,205 @@
+package decoders
+
+import "strings"
+
+// FlagEffect is how an instruction changes one condition code.
+type FlagEffect string
+
+const (
+	FlagUnchanged FlagEffect = ""
+	// FlagResult is set or cleared according to the result.
+	FlagResult    FlagEffect = "result"
+	FlagCleared   FlagEffect = "cleared"
+	FlagSet       FlagEffect = "set"
+	FlagUndefined FlagEffect = "undefined"
+)
+
+// Flags is a set of condition codes in their CCR bit positions.
+type Flags uint8
+
+const (
+	FlagC Flags = 1 << iota
+	FlagV
+	FlagZ
+	FlagN
+	FlagX
+
+	allFlags = FlagX | FlagN | FlagZ | FlagV | FlagC
+)
+
+// ConditionCodes describes how an instruction affects X, N, Z, V and C.
+type ConditionCodes struct {
+	X, N, Z, V, C FlagEffect
+	// Uses holds the flags the instruction reads, such as X for ADDX or Z
+	// for BEQ.
+	Uses Flags
+}
+
+// ccrEffects lists the condition code effects of the integer instructions
+// in the XNZVC notation of the Programmer's Reference Manual: * result,
+// 0 cleared, 1 set, U undefined, - unchanged. Unlisted instructions leave
+// the condition codes alone.
+var ccrEffects = map[string]string{
+	"ABCD": "*U*U*", "SBCD": "*U*U*", "NBCD": "*U*U*",
+	"ADD": "*****", "ADDI": "*****", "ADDQ": "*****", "ADDX": "*****",
+	"SUB": "*****", "SUBI": "*****", "SUBQ": "*****", "SUBX": "*****",
+	"NEG": "*****", "NEGX": "*****",
+	"AND": "-**00", "ANDI": "-**00", "OR": "-**00", "ORI": "-**00",
+	"EOR": "-**00", "EORI": "-**00", "NOT": "-**00",
+	"MOVE": "-**00", "MOVEQ": "-**00", "MOV3Q": "-**00", "MVS": "-**00", "MVZ": "-0*00",
+	"CLR": "-0100", "TST": "-**00", "TAS": "-**00",
+	"EXT": "-**00", "EXTB": "-**00", "SWAP": "-**00", "SATS": "-**00", "FF1": "-**00",
+	"MULS": "-**00", "MULU": "-**00",
+	"DIVS": "-***0", "DIVU": "-***0", "DIVSL": "-***0", "DIVUL": "-***0", "REMS": "-***0", "REMU": "-***0",
+	"CMP": "-****", "CMPA": "-****", "CMPI": "-****", "CMPM": "-****",
+	"CMP2": "-U*U*", "CHK2": "-U*U*", "CHK": "-*UUU",
+	"CAS": "-****", "CAS2": "-****",
+	"ASL": "*****", "ASR": "*****", "LSL": "***0*", "LSR": "***0*",
+	"ROXL": "***0*", "ROXR": "***0*", "ROL": "-**0*", "ROR": "-**0*",
+	"BTST": "--*--", "BCHG": "--*--", "BCLR": "--*--", "BSET": "--*--",
+	"BFTST": "-**00", "BFCHG": "-**00", "BFCLR": "-**00", "BFSET": "-**00",
+	"BFEXTU": "-**00", "BFEXTS": "-**00", "BFFFO": "-**00", "BFINS": "-**00",
+	"TBLS": "-***0", "TBLU": "-***0", "TBLSN": "-***0", "TBLUN": "-***0",
+	"RTE": "*****", "RTR": "*****", "RTM": "*****",
+}
+
+// ccrUses lists the flags instructions read besides a condition. The BCD
+// and extended instructions add X and only clear Z, so a multi-precision
+// chain keeps Z from earlier words.
+var ccrUses = map[string]Flags{
+	"ABCD": FlagX | FlagZ, "SBCD": FlagX | FlagZ, "NBCD": FlagX | FlagZ,
+	"ADDX": FlagX | FlagZ, "SUBX": FlagX | FlagZ, "NEGX": FlagX | FlagZ,
+	"ROXL": FlagX, "ROXR": FlagX,
+	"TRAPV": FlagV,
+}
+
+// conditionFlags maps the conditions to the flags they test.
+var conditionFlags = map[string]Flags{
+	"T": 0, "F": 0, "RA": 0,
+	"HI": FlagC | FlagZ, "LS": FlagC | FlagZ,
+	"HS": FlagC, "LO": FlagC,
+	"NE": FlagZ, "EQ": FlagZ,
+	"VC": FlagV, "VS": FlagV,
+	"PL": FlagN, "MI": FlagN,
+	"GE": FlagN | FlagV, "LT": FlagN | FlagV,
+	"GT": FlagN | FlagZ | FlagV, "LE": FlagN | FlagZ | FlagV,
+}
+
+// conditionCodes returns the condition code effects of an instruction.
+// Instructions loading the status register from an immediate, such as
+// ANDI #$FE, CCR or STOP #$2700, set and clear the flags it names.
+func conditionCodes(base, suffix string, operands []Operand) ConditionCodes {
+	effects := ccrEffects[base]
+	switch {
+	case (base == "ADDQ" || base == "SUBQ") && len(operands) == 2 && isAddressRegisterDirect(operands[1]):
+		effects = ""
+	case (base == "MULS" || base == "MULU") && suffix == "L":
+		effects = "-***0"
+	}
+
+	var status *Operand // SR or CCR written by the instruction
+	var statusSource bool
+	for i := range operands {
+		if reg := operands[i].Register; reg != nil && (reg.Kind == RegisterKindSystem || reg.Kind == RegisterKindMAC) {
+			if reg.Name == "SR" || reg.Name == "CCR" {
+				if i == len(operands)-1 {
+					status = &operands[i]
+				} else {
+					statusSource = true
+				}
+			}
+			if base == "MOVE" {
+				effects = ""
+			}
+		}
+	}
+
+	cc := parseFlagEffects(effects)
+	switch {
+	case (base == "STOP" || base == "LPSTOP") && len(operands) == 1:
+		cc = loadedFlags(operands[0])
+	case status != nil && base == "MOVE":
+		cc = loadedFlags(operands[0])
+	case status != nil && operands[0].Immediate != nil:
+		cc = logicalStatusFlags(base, operands[0].Immediate.Value)
+	}
+	cc.Uses |= ccrUses[base]
+	if statusSource {
+		cc.Uses |= allFlags
+	}
+	for _, prefix := range []string{"B", "DB", "S", "TRAP"} {
+		if condition, ok := strings.CutPrefix(base, prefix); ok && base != "BSR" {
+			cc.Uses |= conditionFlags[condition]
+		}
+	}
+	return cc
+}
+
+// parseFlagEffects reads an XNZVC string of ccrEffects.
+func parseFlagEffects(effects string) ConditionCodes {
+	if effects == "" {
+		return ConditionCodes{}
+	}
+	var parsed [5]FlagEffect
+	for i, c := range effects {
+		switch c {
+		case '*':
+			parsed[i] = FlagResult
+		case '0':
+			parsed[i] = FlagCleared
+		case '1':
+			parsed[i] = FlagSet
+		case 'U':
+			parsed[i] = FlagUndefined
+		}
+	}
+	return ConditionCodes{X: parsed[0], N: parsed[1], Z: parsed[2], V: parsed[3], C: parsed[4]}
+}
+
+// loadedFlags describes a status register loaded from source: the flags an
+// immediate names are set and the others cleared; any other source sets
+// all of them.
+func loadedFlags(source Operand) ConditionCodes {
+	imm := source.Immediate
+	if source.EffectiveAddress != nil {
+		imm = source.EffectiveAddress.Immediate
+	}
+	if imm == nil {
+		return parseFlagEffects("*****")
+	}
+	return flagsFromBits(func(flag Flags) FlagEffect {
+		if Flags(imm.Value)&flag != 0 {
+			return FlagSet
+		}
+		return FlagCleared
+	})
+}
+
+// logicalStatusFlags describes ANDI, ORI and EORI to CCR or SR: ANDI clears
+// the flags missing in the mask, ORI sets and EORI inverts those in it.
+func logicalStatusFlags(base string, mask uint32) ConditionCodes {
+	cc := flagsFromBits(func(flag Flags) FlagEffect {
+		inMask := Flags(mask)&flag != 0
+		switch {
+		case base == "ANDI" && !inMask:
+			return FlagCleared
+		case base == "ORI" && inMask:
+			return FlagSet
+		case base == "EORI" && inMask:
+			return FlagResult
+		}
+		return FlagUnchanged
+	})
+	if base == "EORI" {
+		cc.Uses = Flags(mask) & allFlags
+	}
+	return cc
+}
+
+func flagsFromBits(effect func(Flags) FlagEffect) ConditionCodes {
+	return ConditionCodes{X: effect(FlagX), N: effect(FlagN), Z: effect(FlagZ), V: effect(FlagV), C: effect(FlagC)}
+}
+
+func isAddressRegisterDirect(operand Operand) bool {
+	return operand.EffectiveAddress != nil && operand.EffectiveAddress.Kind == EAKindAddressRegisterDirect
+}
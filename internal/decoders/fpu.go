@@ -0,0 +1,550 @@
+package decoders
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// fpuConditionNames lists the 68881/68882 conditional predicates in encoding
+// order (low six bits of the condition word).
+var fpuConditionNames = [...]string{
+	"F", "EQ", "OGT", "OGE", "OLT", "OLE", "OGL", "OR",
+	"UN", "UEQ", "UGT", "UGE", "ULT", "ULE", "NE", "T",
+	"SF", "SEQ", "GT", "GE", "LT", "LE", "GL", "GLE",
+	"NGLE", "NGL", "NLE", "NLT", "NGE", "NGT", "SNE", "ST",
+}
+
+// fpuFormat is an FPU data format selected by the source/destination specifier.
+type fpuFormat struct {
+	suffix string
+	size   int
+}
+
+// fpuFormats is indexed by the 3-bit format specifier. Specifier 7 is the
+// packed format with a dynamic k-factor and only valid for FMOVE to memory.
+var fpuFormats = [8]fpuFormat{
+	{"L", 4}, {"S", 4}, {"X", 12}, {"P", 12}, {"W", 2}, {"D", 8}, {"B", 1}, {"P", 12},
+}
+
+// fpuOpmodes maps the 7-bit opmode of the general FPU command word to its
+// mnemonic. The 0x40-0x6C range holds the single/double rounding variants
+// added by the 68040.
+var fpuOpmodes = map[uint16]string{
+	0x00: "FMOVE", 0x01: "FINT", 0x02: "FSINH", 0x03: "FINTRZ",
+	0x04: "FSQRT", 0x06: "FLOGNP1", 0x08: "FETOXM1", 0x09: "FTANH",
+	0x0A: "FATAN", 0x0C: "FASIN", 0x0D: "FATANH", 0x0E: "FSIN",
+	0x0F: "FTAN", 0x10: "FETOX", 0x11: "FTWOTOX", 0x12: "FTENTOX",
+	0x14: "FLOGN", 0x15: "FLOG10", 0x16: "FLOG2", 0x18: "FABS",
+	0x19: "FCOSH", 0x1A: "FNEG", 0x1C: "FACOS", 0x1D: "FCOS",
+	0x1E: "FGETEXP", 0x1F: "FGETMAN", 0x20: "FDIV", 0x21: "FMOD",
+	0x22: "FADD", 0x23: "FMUL", 0x24: "FSGLDIV", 0x25: "FREM",
+	0x26: "FSCALE", 0x27: "FSGLMUL", 0x28: "FSUB", 0x38: "FCMP",
+	0x3A: "FTST",
+	0x40: "FSMOVE", 0x41: "FSSQRT", 0x44: "FDMOVE", 0x45: "FDSQRT",
+	0x58: "FSABS", 0x5A: "FSNEG", 0x5C: "FDABS", 0x5E: "FDNEG",
+	0x60: "FSDIV", 0x62: "FSADD", 0x63: "FSMUL", 0x64: "FDDIV",
+	0x66: "FDADD", 0x67: "FDMUL", 0x68: "FSSUB", 0x6C: "FDSUB",
+}
+
+// fpuControlRegisters lists FPCR, FPSR and FPIAR in register-select bit order
+// (bits 12, 11 and 10 of the command word).
+var fpuControlRegisters = [3]string{"FPCR", "FPSR", "FPIAR"}
+
+// isFPUDyadic reports whether an opmode combines source and destination.
+// Monadic operations with identical source and destination register are
+// rendered with a single operand.
+func isFPUDyadic(opmode uint16) bool {
+	return (opmode >= 0x20 && opmode <= 0x28) || opmode == 0x38 || opmode >= 0x60
+}
+
+func fpRegisterOperand(number uint8) Operand {
+	return registerOperand(RegisterKindFP, number)
+}
+
+// decodeFPUGeneral - FPU general instructions (cpID 1, type 000)
+// Format: 1111 0010 00 mmm rrr + command word (ccc R/M sss ddd ooooooo)
+// ccc selects register-to-register/memory arithmetic, FMOVE to memory,
+// FMOVE(M) of the control registers or FMOVEM of the data registers.
+func decodeFPUGeneral(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, "FPU command word"); err != nil {
+		return err
+	}
+	command := binary.BigEndian.Uint16(data[2:4])
+	switch command >> 13 {
+	case 0, 2:
+		return decodeFPUArithmetic(data, opcode, command, inst)
+	case 3:
+		return decodeFMOVEToMemory(data, opcode, command, inst)
+	case 4, 5:
+		return decodeFMOVEControl(data, opcode, command, inst)
+	case 6, 7:
+		return decodeFMOVEM(data, opcode, command, inst)
+	default:
+		return fmt.Errorf("unknown FPU command word: $%04X", command)
+	}
+}
+
+func decodeFPUArithmetic(data []byte, opcode, command uint16, inst *Instruction) error {
+	memorySource := command&0x4000 != 0
+	spec := (command >> 10) & 0x7
+	dstReg := uint8((command >> 7) & 0x7)
+	opmode := command & 0x7F
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+
+	if memorySource && spec == 7 {
+		if mode != 0 || reg != 0 {
+			return fmt.Errorf("invalid FMOVECR encoding: $%04X", opcode)
+		}
+		romOffset := uint32(opmode)
+		immText := fmt.Sprintf("#$%02X", romOffset)
+		setInstruction(data, inst, 4, "FMOVECR.X", fmt.Sprintf("%s, FP%d", immText, dstReg), immediateOperand(immText, romOffset, 1), fpRegisterOperand(dstReg))
+		return nil
+	}
+
+	base := fpuOpmodes[opmode]
+	if opmode >= 0x30 && opmode <= 0x37 {
+		base = "FSINCOS"
+	}
+	if base == "" {
+		return fmt.Errorf("unknown FPU opmode: $%02X", opmode)
+	}
+
+	var src Operand
+	offset := 4
+	mnemonic := base + ".X"
+	if memorySource {
+		format := fpuFormats[spec]
+		mnemonic = base + "." + format.suffix
+		if err := checkFPUEA("FPU", mnemonic, eaSource, mode, reg, format.size); err != nil {
+			return err
+		}
+		var err error
+		src, offset, err = decodeFPUEA(data, offset, mode, reg, format)
+		if err != nil {
+			return err
+		}
+	} else {
+		if opcode&0x3F != 0 {
+			return fmt.Errorf("invalid FPU register-to-register encoding: $%04X", opcode)
+		}
+		src = fpRegisterOperand(uint8(spec))
+	}
+
+	dst := fpRegisterOperand(dstReg)
+	switch {
+	case base == "FTST":
+		setInstruction(data, inst, offset, mnemonic, src.Text, src)
+	case base == "FSINCOS":
+		pair := registerPairOperand(Register{Kind: RegisterKindFP, Number: uint8(opmode & 0x7)}, Register{Kind: RegisterKindFP, Number: dstReg}, false)
+		setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", src.Text, pair.Text), src, pair)
+	case !memorySource && !isFPUDyadic(opmode) && !strings.HasSuffix(base, "MOVE") && uint8(spec) == dstReg:
+		setInstruction(data, inst, offset, mnemonic, dst.Text, dst)
+	default:
+		setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", src.Text, dst.Text), src, dst)
+	}
+	return nil
+}
+
+// decodeFMOVEToMemory handles FMOVE FPn, <ea>. The packed format carries a
+// k-factor, either a signed 7-bit static value or a data register.
+func decodeFMOVEToMemory(data []byte, opcode, command uint16, inst *Instruction) error {
+	spec := (command >> 10) & 0x7
+	format := fpuFormats[spec]
+	srcReg := uint8((command >> 7) & 0x7)
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	mnemonic := "FMOVE." + format.suffix
+	if err := checkFPUEA("FPU", mnemonic, eaDestination, mode, reg, format.size); err != nil {
+		return err
+	}
+	dstStr, offset, dstMeta, err := decodeEAWithSize(data, 4, mode, reg, 4)
+	if err != nil {
+		return err
+	}
+	switch spec {
+	case 3:
+		kFactor := int8(command<<1) >> 1
+		dstMeta.Suffix = fmt.Sprintf("{#%d}", kFactor)
+	case 7:
+		dstMeta.Suffix = fmt.Sprintf("{D%d}", (command>>4)&0x7)
+	}
+	dstMeta.Text = dstStr + dstMeta.Suffix
+	src := fpRegisterOperand(srcReg)
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", src.Text, dstMeta.Text), src, dstMeta)
+	return nil
+}
+
+// decodeFMOVEControl handles FMOVE/FMOVEM between <ea> and FPCR/FPSR/FPIAR.
+// A single selected register is rendered as FMOVE.L, several as FMOVEM.L.
+func decodeFMOVEControl(data []byte, opcode, command uint16, inst *Instruction) error {
+	var names []string
+	for i, name := range fpuControlRegisters {
+		if command&(0x1000>>uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 || command&0x03FF != 0 {
+		return fmt.Errorf("invalid FPU control register list: $%04X", command)
+	}
+	toMemory := command&0x2000 != 0
+	role := eaSource
+	if toMemory {
+		role = eaDestination
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+
+	mnemonic, rule := "FMOVE.L", "FMOVE FPcr"
+	var list Operand
+	if len(names) == 1 {
+		list = namedRegisterOperand(RegisterKindFPControl, names[0])
+	} else {
+		mnemonic, rule = "FMOVEM.L", "FMOVEM FPcr"
+		list = registerListOperand(strings.Join(names, "/"), names)
+	}
+	if err := checkFPUEA(rule, mnemonic, role, mode, reg, 4); err != nil {
+		return err
+	}
+	if mode == 1 && names[0] != "FPIAR" {
+		return &IllegalEncodingError{Mnemonic: mnemonic, Role: role.String(), Mode: mode, Register: reg, Kind: eaKind(mode, reg)}
+	}
+	eaStr, offset, eaMeta, err := decodeEAWithSize(data, 4, mode, reg, 4)
+	if err != nil {
+		return err
+	}
+	if toMemory {
+		setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", list.Text, eaStr), list, eaMeta)
+		return nil
+	}
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", eaStr, list.Text), eaMeta, list)
+	return nil
+}
+
+// decodeFMOVEM handles FMOVEM.X of the floating-point data registers.
+// Command word: 11d mm 000 llllllll; mm selects a static or dynamic (Dn in
+// bits 6-4) list in predecrement or postincrement/control order.
+func decodeFMOVEM(data []byte, opcode, command uint16, inst *Instruction) error {
+	toMemory := command&0x2000 != 0
+	role := eaSource
+	if toMemory {
+		role = eaDestination
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkFPUEA("FMOVEM", "FMOVEM.X", role, mode, reg, 12); err != nil {
+		return err
+	}
+	predecrement := command&0x1000 == 0
+	if predecrement != (mode == 4) || command&0x0700 != 0 {
+		return fmt.Errorf("invalid FMOVEM mode for addressing mode %d: $%04X", mode, command)
+	}
+
+	var list Operand
+	if command&0x0800 != 0 {
+		list = registerOperand(RegisterKindData, uint8((command>>4)&0x7))
+	} else {
+		var names []string
+		for i := 0; i < 8; i++ {
+			bit := uint16(0x80) >> uint(i)
+			if predecrement {
+				bit = 1 << uint(i)
+			}
+			if command&bit != 0 {
+				names = append(names, fmt.Sprintf("FP%d", i))
+			}
+		}
+		list = registerListOperand(formatRegisterRange(names), names)
+	}
+
+	eaStr, offset, eaMeta, err := decodeEA(data, 4, mode, reg)
+	if err != nil {
+		return err
+	}
+	if toMemory {
+		setInstruction(data, inst, offset, "FMOVEM.X", fmt.Sprintf("%s, %s", list.Text, eaStr), list, eaMeta)
+		return nil
+	}
+	setInstruction(data, inst, offset, "FMOVEM.X", fmt.Sprintf("%s, %s", eaStr, list.Text), eaMeta, list)
+	return nil
+}
+
+// decodeFScc - Set on FPU Condition
+// Format: 1111 0010 01 mmm rrr + condition word
+func decodeFScc(data []byte, opcode uint16, inst *Instruction) error {
+	condition, err := readFPUCondition(data, 2)
+	if err != nil {
+		return err
+	}
+	mnemonic := "FS" + condition
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkFPUEA("FScc", mnemonic, eaDestination, mode, reg, 1); err != nil {
+		return err
+	}
+	operand, offset, meta, err := decodeEAWithSize(data, 4, mode, reg, 1)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, mnemonic, operand, meta)
+	return nil
+}
+
+// decodeFDBcc - Test FPU Condition, Decrement and Branch
+// Format: 1111 0010 0100 1rrr + condition word + 16-bit displacement
+// The displacement is relative to the address of the displacement word.
+func decodeFDBcc(data []byte, opcode uint16, inst *Instruction) error {
+	condition, err := readFPUCondition(data, 2)
+	if err != nil {
+		return err
+	}
+	mnemonic := "FDB" + condition
+	if err := requireLength(data, 6, mnemonic+" displacement"); err != nil {
+		return err
+	}
+	reg := uint8(opcode & 0x7)
+	displacement := int16(binary.BigEndian.Uint16(data[4:6]))
+	target := uint32(int32(inst.Address) + 4 + int32(displacement))
+	targetText := formatBranchTarget(target)
+	setInstruction(data, inst, 6, mnemonic, fmt.Sprintf("D%d, %s", reg, targetText), registerOperand(RegisterKindData, reg), branchOperand(targetText, target))
+	return nil
+}
+
+// decodeFTRAPcc - Trap on FPU Condition
+// Format: 1111 0010 0111 1ooo + condition word (ooo: 010=word, 011=long, 100=none)
+func decodeFTRAPcc(data []byte, opcode uint16, inst *Instruction) error {
+	condition, err := readFPUCondition(data, 2)
+	if err != nil {
+		return err
+	}
+	mnemonic := "FTRAP" + condition
+	size := 2
+	switch opcode & 0x7 {
+	case 2:
+		mnemonic += ".W"
+	case 3:
+		mnemonic += ".L"
+		size = 4
+	default:
+		setInstruction(data, inst, 4, mnemonic, "")
+		return nil
+	}
+	immediate, offset, err := readImmediate(data, 4, size, mnemonic)
+	if err != nil {
+		return err
+	}
+	immText := fmt.Sprintf("#%s", formatImmediate(immediate, size))
+	setInstruction(data, inst, offset, mnemonic, immText, immediateOperand(immText, immediate, size))
+	return nil
+}
+
+// decodeFBcc - Branch on FPU Condition
+// Format: 1111 0010 1s cccccc + 16-bit (s=0) or 32-bit (s=1) displacement
+// FBF.W with a zero displacement is the canonical FNOP encoding.
+func decodeFBcc(data []byte, opcode uint16, inst *Instruction) error {
+	condition := opcode & 0x3F
+	if int(condition) >= len(fpuConditionNames) {
+		return fmt.Errorf("unknown FPU condition: $%02X", condition)
+	}
+	mnemonic := "FB" + fpuConditionNames[condition]
+	var displacement int32
+	offset := 2
+	if opcode&0x0040 == 0 {
+		if err := requireLength(data, 4, mnemonic+".W displacement"); err != nil {
+			return err
+		}
+		displacement = int32(int16(binary.BigEndian.Uint16(data[2:4])))
+		offset = 4
+		mnemonic += ".W"
+		if opcode == 0xF280 && displacement == 0 {
+			setInstruction(data, inst, offset, "FNOP", "")
+			return nil
+		}
+	} else {
+		if err := requireLength(data, 6, mnemonic+".L displacement"); err != nil {
+			return err
+		}
+		displacement = int32(binary.BigEndian.Uint32(data[2:6]))
+		offset = 6
+		mnemonic += ".L"
+	}
+	target := uint32(int32(inst.Address) + 2 + displacement)
+	targetText := formatBranchTarget(target)
+	setInstruction(data, inst, offset, mnemonic, targetText, branchOperand(targetText, target))
+	return nil
+}
+
+// decodeFSAVE - Save FPU Internal State (privileged)
+// Format: 1111 0011 00 mmm rrr
+func decodeFSAVE(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeFPUState("FSAVE", eaDestination, data, opcode, inst)
+}
+
+// decodeFRESTORE - Restore FPU Internal State (privileged)
+// Format: 1111 0011 01 mmm rrr
+func decodeFRESTORE(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeFPUState("FRESTORE", eaSource, data, opcode, inst)
+}
+
+func decodeFPUState(mnemonic string, role eaRole, data []byte, opcode uint16, inst *Instruction) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA(mnemonic, role, mode, reg, 0); err != nil {
+		return err
+	}
+	operand, offset, meta, err := decodeEA(data, 2, mode, reg)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, mnemonic, operand, meta)
+	return nil
+}
+
+func readFPUCondition(data []byte, offset int) (string, error) {
+	if err := requireLength(data, offset+2, "FPU condition word"); err != nil {
+		return "", err
+	}
+	condition := binary.BigEndian.Uint16(data[offset : offset+2])
+	if int(condition) >= len(fpuConditionNames) {
+		return "", fmt.Errorf("unknown FPU condition: $%04X", condition)
+	}
+	return fpuConditionNames[condition], nil
+}
+
+// checkFPUEA applies the addressing rule shared by a group of FPU instructions
+// and reports the concrete mnemonic. Data register direct only holds the
+// byte, word, long and single formats.
+func checkFPUEA(rule, mnemonic string, role eaRole, mode, reg uint8, size int) error {
+	err := checkEA(rule, role, mode, reg, size)
+	if illegal, ok := err.(*IllegalEncodingError); ok {
+		illegal.Mnemonic = mnemonic
+		return illegal
+	}
+	if err == nil && mode == 0 && size > 4 {
+		return &IllegalEncodingError{Mnemonic: mnemonic, Role: role.String(), Mode: mode, Register: reg, Kind: eaKind(mode, reg)}
+	}
+	return err
+}
+
+// decodeFPUEA decodes an FPU source operand. Immediates in the single, double,
+// extended and packed formats are rendered as decimal floating-point values.
+func decodeFPUEA(data []byte, offset int, mode, reg uint8, format fpuFormat) (Operand, int, error) {
+	if mode != 7 || reg != 4 || format.size <= 2 || format.suffix == "L" {
+		size := format.size
+		if size > 4 {
+			size = 4
+		}
+		_, next, meta, err := decodeEAWithSize(data, offset, mode, reg, size)
+		return meta, next, err
+	}
+
+	if err := requireLength(data, offset+format.size, "FPU ."+format.suffix+" immediate"); err != nil {
+		return Operand{}, offset, err
+	}
+	raw := data[offset : offset+format.size]
+	value, text := decodeFloatImmediate(raw, format.suffix)
+	first := binary.BigEndian.Uint32(raw[:4])
+	immText := "#" + text
+	return effectiveAddressOperand(immText, EffectiveAddress{
+		Kind:     EAKindImmediate,
+		Mode:     mode,
+		Register: reg,
+		Immediate: &ImmediateValue{
+			Value:  first,
+			Signed: int32(first),
+			Size:   uint8(format.size),
+			Float:  &value,
+		},
+	}), offset + format.size, nil
+}
+
+// decodeFloatImmediate converts the raw bytes of a .S, .D, .X or .P immediate
+// to a float64 and its decimal rendering. Non-finite values are rendered as
+// raw hexadecimal because assemblers have no common literal syntax for them.
+func decodeFloatImmediate(raw []byte, suffix string) (float64, string) {
+	var value float64
+	bits := 64
+	switch suffix {
+	case "S":
+		value = float64(math.Float32frombits(binary.BigEndian.Uint32(raw)))
+		bits = 32
+	case "D":
+		value = math.Float64frombits(binary.BigEndian.Uint64(raw))
+	case "X":
+		value = extendedToFloat64(raw)
+	case "P":
+		text := packedDecimalText(raw)
+		value, _ = strconv.ParseFloat(text, 64)
+		return value, text
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return value, "$" + strings.ToUpper(fmt.Sprintf("%x", raw))
+	}
+	text := strconv.FormatFloat(value, 'g', -1, bits)
+	if !strings.ContainsAny(text, ".e") {
+		text += ".0"
+	}
+	return value, text
+}
+
+// extendedToFloat64 converts the 96-bit extended precision format (sign and
+// 15-bit exponent, 16 zero bits, 64-bit mantissa with explicit integer bit).
+func extendedToFloat64(raw []byte) float64 {
+	signExp := binary.BigEndian.Uint16(raw[0:2])
+	mantissa := binary.BigEndian.Uint64(raw[4:12])
+	exponent := int(signExp & 0x7FFF)
+	sign := 1.0
+	if signExp&0x8000 != 0 {
+		sign = -1
+	}
+	if exponent == 0x7FFF {
+		if mantissa<<1 == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	}
+	if exponent == 0 {
+		exponent = 1 // denormal
+	}
+	return sign * math.Ldexp(float64(mantissa), exponent-16383-63)
+}
+
+// packedDecimalText renders the 96-bit packed decimal real format: mantissa
+// and exponent signs, a three-digit exponent, one integer digit and sixteen
+// fraction digits, all BCD.
+func packedDecimalText(raw []byte) string {
+	digit := func(nibble int) byte {
+		b := raw[nibble/2]
+		if nibble%2 == 0 {
+			b >>= 4
+		}
+		return '0' + b&0xF
+	}
+	var text strings.Builder
+	if raw[0]&0x80 != 0 {
+		text.WriteByte('-')
+	}
+	text.WriteByte(digit(7))
+	fraction := make([]byte, 0, 16)
+	for nibble := 8; nibble < 24; nibble++ {
+		fraction = append(fraction, digit(nibble))
+	}
+	if trimmed := strings.TrimRight(string(fraction), "0"); trimmed != "" {
+		text.WriteString("." + trimmed)
+	}
+	text.WriteByte('E')
+	if raw[0]&0x40 != 0 {
+		text.WriteByte('-')
+	} else {
+		text.WriteByte('+')
+	}
+	exponent := strings.TrimLeft(string([]byte{digit(1), digit(2), digit(3)}), "0")
+	if exponent == "" {
+		exponent = "0"
+	}
+	text.WriteString(exponent)
+	return text.String()
+}
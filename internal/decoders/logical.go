@@ -26,24 +26,67 @@ func decodeEORI(data []byte, opcode uint16, inst *Instruction) error {
 	return decodeLogicalI("EORI", data, opcode, inst)
 }
 
+// decodeLogicalToCCR - ORI/ANDI/EORI to CCR
+// Format: 0000 0xx0 0011 1100 + immediate word (only the low byte is used)
+func decodeLogicalToCCR(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeLogicalToStatus(data, opcode, inst, "CCR", 1)
+}
+
+// decodeLogicalToSR - ORI/ANDI/EORI to SR (privileged)
+// Format: 0000 0xx0 0111 1100 + immediate word
+func decodeLogicalToSR(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeLogicalToStatus(data, opcode, inst, "SR", 2)
+}
+
+func decodeLogicalToStatus(data []byte, opcode uint16, inst *Instruction, register string, immSize int) error {
+	var mn string
+	switch opcode & 0xFF00 {
+	case valORI:
+		mn = "ORI"
+	case valANDI:
+		mn = "ANDI"
+	default:
+		mn = "EORI"
+	}
+	immediate, offset, err := readImmediate(data, 2, 2, mn)
+	if err != nil {
+		return err
+	}
+	canonical := true
+	if immSize == 1 {
+		immediate, immSize, canonical = byteImmediate("B", immediate, immSize)
+	}
+	immText := fmt.Sprintf("#%s", formatImmediate(immediate, immSize))
+	immMeta := immediateOperand(immText, immediate, immSize)
+	immMeta.nonCanonical = !canonical
+	setInstruction(data, inst, offset, mn, fmt.Sprintf("%s, %s", immText, register), immMeta, namedRegisterOperand(RegisterKindSystem, register))
+	return nil
+}
+
 func decodeLogicalI(mn string, data []byte, opcode uint16, inst *Instruction) error {
-	sizeStr, immSize, err := immediateSpec((opcode>>6)&0x3, false, mn)
+	sizeStr, immSize, err := immediateSpec((opcode>>6)&0x3, true, mn)
 	if err != nil {
 		return err
 	}
 	dstMode := uint8((opcode >> 3) & 0x7)
 	dstReg := uint8(opcode & 0x7)
+	if err := checkEA(mn, eaDestination, dstMode, dstReg, immSize); err != nil {
+		return err
+	}
 
 	immediate, offset, err := readImmediate(data, 2, immSize, mn)
 	if err != nil {
 		return err
 	}
+	immediate, immSize, canonical := byteImmediate(sizeStr, immediate, immSize)
 	dstOperand, offset, dstMeta, err := decodeEA(data, offset, dstMode, dstReg)
 	if err != nil {
 		return err
 	}
 	immText := fmt.Sprintf("#%s", formatImmediate(immediate, immSize))
-	setInstruction(data, inst, offset, mn+"."+sizeStr, fmt.Sprintf("%s, %s", immText, dstOperand), immediateOperand(immText, immediate, immSize), dstMeta)
+	immMeta := immediateOperand(immText, immediate, immSize)
+	immMeta.nonCanonical = !canonical
+	setInstruction(data, inst, offset, mn+"."+sizeStr, fmt.Sprintf("%s, %s", immText, dstOperand), immMeta, dstMeta)
 	return nil
 }
 
@@ -0,0 +1,161 @@
+package decoders
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// macShiftNames is indexed by the scale-factor field of the MAC extension word.
+var macShiftNames = [4]string{"", "<<", "", ">>"}
+
+// macRegisterNames is indexed by bits 10-9 of the MAC register moves when
+// bit 11 is set.
+var macRegisterNames = [4]string{"MACSR", "ACCEXT01", "MASK", "ACCEXT23"}
+
+// decodeMAC - Multiply Accumulate / Multiply Subtract (ColdFire MAC/EMAC)
+// Format: 1010 xxx 0 aX 00 Y yyy + 0000 s ff m u v 0 A 0000        (register)
+//
+//	1010 www 0 aW mmm rrr  + Xxxx s ff m u v k A Yyyy        (with load)
+//
+// a/A are the low/high bits of the accumulator, X/Y/W select An, s=1 long,
+// ff is the product shift, m=1 MSAC, u/v pick the upper word of Rx/Ry and k
+// applies the MASK register to the load address.
+func decodeMAC(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, "MAC extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	mnemonic := "MAC"
+	if ext&0x0100 != 0 {
+		mnemonic = "MSAC"
+	}
+	long := ext&0x0800 != 0
+	sizeStr := "W"
+	if long {
+		sizeStr = "L"
+	}
+	shift := macShiftNames[(ext>>9)&0x3]
+	if (ext>>9)&0x3 == 2 {
+		return fmt.Errorf("reserved MAC scale factor in $%04X", ext)
+	}
+	acc := macAccumulator(uint8((opcode>>7)&0x1 | (ext>>3)&0x2))
+
+	var rx, ry Operand
+	loadForm := opcode&0x0030 != 0
+	if loadForm {
+		rx = macGeneralRegister(uint8(ext>>12), long, ext&0x0080 != 0)
+		ry = macGeneralRegister(uint8(ext&0xF), long, ext&0x0040 != 0)
+	} else {
+		rx = macGeneralRegister(uint8((opcode>>9)&0x7|(opcode>>3)&0x8), long, ext&0x0080 != 0)
+		ry = macGeneralRegister(uint8(opcode&0xF), long, ext&0x0040 != 0)
+	}
+
+	operands := []Operand{ry, rx}
+	if shift != "" {
+		operands = append(operands, Operand{Text: shift, Kind: OperandKindShift})
+	}
+	offset := 4
+	if loadForm {
+		mode := uint8((opcode >> 3) & 0x7)
+		reg := uint8(opcode & 0x7)
+		if err := checkEA("MAC load", eaSource, mode, reg, 4); err != nil {
+			return err
+		}
+		src, next, srcMeta, err := decodeEAWithSize(data, 4, mode, reg, 4)
+		if err != nil {
+			return err
+		}
+		offset = next
+		if ext&0x0020 != 0 {
+			srcMeta.Suffix = "&"
+			srcMeta.Text = src + srcMeta.Suffix
+		}
+		rw := macGeneralRegister(uint8((opcode>>9)&0x7|(opcode>>3)&0x8), true, false)
+		operands = append(operands, srcMeta, rw)
+	}
+	operands = append(operands, acc)
+
+	texts := make([]string, len(operands))
+	for i, operand := range operands {
+		texts[i] = operand.Text
+	}
+
+	setInstruction(data, inst, offset, mnemonic+"."+sizeStr, strings.Join(texts, ", "), operands...)
+	return nil
+}
+
+// decodeMACMove - MAC register moves (ColdFire MAC/EMAC)
+// Format: 1010 Rrr 1 oo mmm rrr; R=0 selects ACCrr, R=1 MACSR, ACCEXT01,
+// MASK or ACCEXT23. oo: 00 <ea>,reg; 10 reg,Rx; 11 MOVCLR ACC,Rx or
+// MACSR,CCR. MOVE.L ACCy,ACCx uses oo=00 with mode 010.
+func decodeMACMove(data []byte, opcode uint16, inst *Instruction) error {
+	selector := uint8((opcode >> 9) & 0x3)
+	var macReg Operand
+	if opcode&0x0800 == 0 {
+		macReg = macAccumulator(selector)
+	} else {
+		macReg = namedRegisterOperand(RegisterKindMAC, macRegisterNames[selector])
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+
+	switch (opcode >> 6) & 0x3 {
+	case 0:
+		if opcode&0x0800 == 0 && mode == 2 && reg < 4 {
+			src := macAccumulator(reg)
+			setInstruction(data, inst, 2, "MOVE.L", fmt.Sprintf("%s, %s", src.Text, macReg.Text), src, macReg)
+			return nil
+		}
+		if err := checkEA("MOVE MAC", eaSource, mode, reg, 4); err != nil {
+			return err
+		}
+		src, offset, srcMeta, err := decodeEAWithSize(data, 2, mode, reg, 4)
+		if err != nil {
+			return err
+		}
+		setInstruction(data, inst, offset, "MOVE.L", fmt.Sprintf("%s, %s", src, macReg.Text), srcMeta, macReg)
+		return nil
+	case 2:
+		if opcode&0x0030 != 0 {
+			return fmt.Errorf("invalid MAC register move: $%04X", opcode)
+		}
+		dst := macGeneralRegister(uint8(opcode&0xF), true, false)
+		setInstruction(data, inst, 2, "MOVE.L", fmt.Sprintf("%s, %s", macReg.Text, dst.Text), macReg, dst)
+		return nil
+	case 3:
+		if opcode&0x0800 == 0 && opcode&0x0030 == 0 {
+			dst := macGeneralRegister(uint8(opcode&0xF), true, false)
+			setInstruction(data, inst, 2, "MOVCLR.L", fmt.Sprintf("%s, %s", macReg.Text, dst.Text), macReg, dst)
+			return nil
+		}
+		if opcode == 0xA9C0 {
+			ccr := namedRegisterOperand(RegisterKindSystem, "CCR")
+			setInstruction(data, inst, 2, "MOVE.L", fmt.Sprintf("%s, %s", macReg.Text, ccr.Text), macReg, ccr)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid MAC register move: $%04X", opcode)
+}
+
+func macAccumulator(number uint8) Operand {
+	return namedRegisterOperand(RegisterKindMAC, fmt.Sprintf("ACC%d", number))
+}
+
+// macGeneralRegister decodes a 4-bit Dn/An field; word operations select the
+// upper or lower half with a .U/.L suffix.
+func macGeneralRegister(field uint8, long, upper bool) Operand {
+	kind := RegisterKindData
+	if field&0x8 != 0 {
+		kind = RegisterKindAddress
+	}
+	operand := registerOperand(kind, field&0x7)
+	if !long {
+		operand.Suffix = ".L"
+		if upper {
+			operand.Suffix = ".U"
+		}
+		operand.Text += operand.Suffix
+	}
+	return operand
+}
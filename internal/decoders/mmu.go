@@ -0,0 +1,324 @@
+package decoders
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// mmuRegister is a PMOVE register and the MMU feature it needs beyond the
+// registers shared by the 68851 and the 68030.
+type mmuRegister struct {
+	name     string
+	requires Feature
+}
+
+// pmoveRegisters maps the format bits (15-13) and register field (12-10) of a
+// PMOVE command word to the MMU register. Format 000 holds the 68030
+// transparent translation registers, 010 the translation control and root
+// pointers (DRP..AC are 68851 only) and 011 the status registers.
+var pmoveRegisters = map[uint16]mmuRegister{
+	0x0800: {"TT0", Feature68030MMU}, 0x0C00: {"TT1", Feature68030MMU},
+	0x4000: {"TC", 0}, 0x4400: {"DRP", Feature68851}, 0x4800: {"SRP", 0}, 0x4C00: {"CRP", 0},
+	0x5000: {"CAL", Feature68851}, 0x5400: {"VAL", Feature68851}, 0x5800: {"SCC", Feature68851}, 0x5C00: {"AC", Feature68851},
+	0x6000: {"MMUSR", 0}, 0x6400: {"PCSR", Feature68851},
+}
+
+// cacheNames is indexed by the cache field of CINV/CPUSH.
+var cacheNames = [4]string{"NC", "DC", "IC", "BC"}
+
+// decodePMMU - 68851/68030 PMMU instructions (cpID 0, type 000)
+// Format: 1111 0000 00 mmm rrr + command word
+// Command word bits 15-13: 000/010/011 PMOVE, 001 PLOAD/PFLUSH, 100 PTEST.
+func decodePMMU(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, "PMMU command word"); err != nil {
+		return err
+	}
+	command := binary.BigEndian.Uint16(data[2:4])
+	switch command >> 13 {
+	case 0, 2, 3:
+		return decodePMOVE(data, opcode, command, inst)
+	case 1:
+		if (command>>10)&0x7 == 0 {
+			return decodePLOAD(data, opcode, command, inst)
+		}
+		return decodePFLUSH(data, opcode, command, inst)
+	case 4:
+		return decodePTEST(data, opcode, command, inst)
+	default:
+		return fmt.Errorf("unknown PMMU command word: $%04X", command)
+	}
+}
+
+// decodePMOVE handles PMOVE <ea>, MRn (R/W=0) and PMOVE MRn, <ea> (R/W=1).
+// The FD bit suppresses the ATC flush and is rendered as PMOVEFD.
+func decodePMOVE(data []byte, opcode, command uint16, inst *Instruction) error {
+	mmuReg, ok := pmoveRegisters[command&0xFC00]
+	if !ok || command&0x00FF != 0 {
+		return fmt.Errorf("unknown PMOVE register: $%04X", command)
+	}
+	inst.Requires |= mmuReg.requires
+	toMemory := command&0x0200 != 0
+	mnemonic := "PMOVE"
+	if command&0x0100 != 0 {
+		if toMemory {
+			return fmt.Errorf("invalid PMOVEFD direction: $%04X", command)
+		}
+		mnemonic = "PMOVEFD"
+		inst.Requires |= Feature68030MMU
+	}
+	role := eaSource
+	if toMemory {
+		role = eaDestination
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("PMOVE", role, mode, reg, 0); err != nil {
+		return err
+	}
+	eaStr, offset, eaMeta, err := decodeEA(data, 4, mode, reg)
+	if err != nil {
+		return err
+	}
+	register := namedRegisterOperand(RegisterKindMMU, mmuReg.name)
+	if toMemory {
+		setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", register.Text, eaStr), register, eaMeta)
+		return nil
+	}
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", eaStr, register.Text), eaMeta, register)
+	return nil
+}
+
+// decodePLOAD - Load an Entry into the ATC
+// Command word: 0010 00R0 000f ffff (R=1: PLOADR, R=0: PLOADW)
+func decodePLOAD(data []byte, opcode, command uint16, inst *Instruction) error {
+	mnemonic := "PLOADW"
+	if command&0x0200 != 0 {
+		mnemonic = "PLOADR"
+	}
+	fc, err := functionCodeOperand(command)
+	if err != nil {
+		return err
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("PLOAD", eaDestination, mode, reg, 0); err != nil {
+		return err
+	}
+	eaStr, offset, eaMeta, err := decodeEA(data, 4, mode, reg)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", fc.Text, eaStr), fc, eaMeta)
+	return nil
+}
+
+// decodePFLUSH - Flush Entries in the ATC
+// Command word: 001 mmm 0 kkkk fffff; mode 001 flushes all entries, 100 by
+// function code and mask, 110 additionally by effective address. The 68851
+// PFLUSHS variants (101/111) also flush shared entries.
+func decodePFLUSH(data []byte, opcode, command uint16, inst *Instruction) error {
+	flushMode := (command >> 10) & 0x7
+	if flushMode == 1 {
+		if command&0x03FF != 0 || opcode&0x3F != 0 {
+			return fmt.Errorf("invalid PFLUSHA encoding: $%04X", command)
+		}
+		setInstruction(data, inst, 4, "PFLUSHA", "")
+		return nil
+	}
+	mnemonic := map[uint16]string{4: "PFLUSH", 5: "PFLUSHS", 6: "PFLUSH", 7: "PFLUSHS"}[flushMode]
+	if mnemonic == "" || command&0x0200 != 0 {
+		return fmt.Errorf("unknown PFLUSH mode: $%04X", command)
+	}
+	if mnemonic == "PFLUSHS" {
+		inst.Requires |= Feature68851
+	}
+	fc, err := functionCodeOperand(command)
+	if err != nil {
+		return err
+	}
+	mask := uint32((command >> 5) & 0xF)
+	maskText := fmt.Sprintf("#%d", mask)
+	maskMeta := immediateOperand(maskText, mask, 1)
+	if flushMode&0x2 == 0 {
+		if opcode&0x3F != 0 {
+			return fmt.Errorf("invalid %s encoding: $%04X", mnemonic, opcode)
+		}
+		setInstruction(data, inst, 4, mnemonic, fmt.Sprintf("%s, %s", fc.Text, maskText), fc, maskMeta)
+		return nil
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("PFLUSH", eaDestination, mode, reg, 0); err != nil {
+		return err
+	}
+	eaStr, offset, eaMeta, err := decodeEA(data, 4, mode, reg)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s, %s", fc.Text, maskText, eaStr), fc, maskMeta, eaMeta)
+	return nil
+}
+
+// decodePTEST - Test a Logical Address
+// Command word: 100 lll R A rrr fffff; R=1 PTESTR, A=1 returns the last
+// descriptor address in An.
+func decodePTEST(data []byte, opcode, command uint16, inst *Instruction) error {
+	mnemonic := "PTESTW"
+	if command&0x0200 != 0 {
+		mnemonic = "PTESTR"
+	}
+	fc, err := functionCodeOperand(command)
+	if err != nil {
+		return err
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("PTEST", eaDestination, mode, reg, 0); err != nil {
+		return err
+	}
+	eaStr, offset, eaMeta, err := decodeEA(data, 4, mode, reg)
+	if err != nil {
+		return err
+	}
+	level := uint32((command >> 10) & 0x7)
+	levelText := fmt.Sprintf("#%d", level)
+	operands := []Operand{fc, eaMeta, immediateOperand(levelText, level, 1)}
+	text := fmt.Sprintf("%s, %s, %s", fc.Text, eaStr, levelText)
+	if command&0x0100 != 0 {
+		addrReg := registerOperand(RegisterKindAddress, uint8((command>>5)&0x7))
+		operands = append(operands, addrReg)
+		text += ", " + addrReg.Text
+	} else if command&0x00E0 != 0 {
+		return fmt.Errorf("invalid PTEST address register field: $%04X", command)
+	}
+	setInstruction(data, inst, offset, mnemonic, text, operands...)
+	return nil
+}
+
+// functionCodeOperand decodes the 5-bit function code field of PLOAD, PFLUSH
+// and PTEST: 00000 SFC, 00001 DFC, 01rrr Dn, 1dddd immediate.
+func functionCodeOperand(command uint16) (Operand, error) {
+	fc := command & 0x1F
+	switch {
+	case fc == 0:
+		return namedRegisterOperand(RegisterKindControl, "SFC"), nil
+	case fc == 1:
+		return namedRegisterOperand(RegisterKindControl, "DFC"), nil
+	case fc&0x18 == 0x08:
+		return registerOperand(RegisterKindData, uint8(fc&0x7)), nil
+	case fc&0x10 != 0:
+		value := uint32(fc & 0xF)
+		text := fmt.Sprintf("#%d", value)
+		return immediateOperand(text, value, 1), nil
+	default:
+		return Operand{}, fmt.Errorf("invalid function code field: $%02X", fc)
+	}
+}
+
+// decodeCacheOp - CINV/CPUSH (68040+, privileged)
+// Format: 1111 0100 cc p ss rrr; cc: 01=DC, 10=IC, 11=BC; p=1 CPUSH;
+// ss: 01 line, 10 page, 11 all.
+func decodeCacheOp(data []byte, opcode uint16, inst *Instruction) error {
+	mnemonic := "CINV"
+	if opcode&0x0020 != 0 {
+		mnemonic = "CPUSH"
+	}
+	scope := (opcode >> 3) & 0x3
+	if scope == 0 {
+		return fmt.Errorf("invalid %s scope: $%04X", mnemonic, opcode)
+	}
+	mnemonic += [4]string{"", "L", "P", "A"}[scope]
+	cache := namedRegisterOperand(RegisterKindCache, cacheNames[(opcode>>6)&0x3])
+	if scope == 3 {
+		if opcode&0x7 != 0 {
+			return fmt.Errorf("invalid %s encoding: $%04X", mnemonic, opcode)
+		}
+		setInstruction(data, inst, 2, mnemonic, cache.Text, cache)
+		return nil
+	}
+	addr := addressIndirectOperand(uint8(opcode & 0x7))
+	setInstruction(data, inst, 2, mnemonic, fmt.Sprintf("%s, %s", cache.Text, addr.Text), cache, addr)
+	return nil
+}
+
+// decodePFLUSH040 - Flush ATC Entries (68040/68060)
+// Format: 1111 0101 000 oo rrr; oo: 00 PFLUSHN (An), 01 PFLUSH (An),
+// 10 PFLUSHAN, 11 PFLUSHA.
+func decodePFLUSH040(data []byte, opcode uint16, inst *Instruction) error {
+	mnemonic := [4]string{"PFLUSHN", "PFLUSH", "PFLUSHAN", "PFLUSHA"}[(opcode>>3)&0x3]
+	if opcode&0x0010 != 0 {
+		if opcode&0x7 != 0 {
+			return fmt.Errorf("invalid %s encoding: $%04X", mnemonic, opcode)
+		}
+		setInstruction(data, inst, 2, mnemonic, "")
+		return nil
+	}
+	addr := addressIndirectOperand(uint8(opcode & 0x7))
+	setInstruction(data, inst, 2, mnemonic, addr.Text, addr)
+	return nil
+}
+
+// decodePTEST040 - Test a Logical Address (68040)
+// Format: 1111 0101 01r0 1rrr (r=1: PTESTR, r=0: PTESTW)
+func decodePTEST040(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeAddressRegisterSystemOp("PTEST", 0x0020, data, opcode, inst)
+}
+
+// decodePLPA - Load Physical Address (68060)
+// Format: 1111 0101 1r00 1rrr (r=1: PLPAR, r=0: PLPAW)
+func decodePLPA(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeAddressRegisterSystemOp("PLPA", 0x0040, data, opcode, inst)
+}
+
+func decodeAddressRegisterSystemOp(base string, readBit uint16, data []byte, opcode uint16, inst *Instruction) error {
+	mnemonic := base + "W"
+	if opcode&readBit != 0 {
+		mnemonic = base + "R"
+	}
+	addr := addressIndirectOperand(uint8(opcode & 0x7))
+	setInstruction(data, inst, 2, mnemonic, addr.Text, addr)
+	return nil
+}
+
+// decodeMOVE16 - Move 16-Byte Block (68040+)
+// Format: 1111 0110 0010 0xxx + 1yyy 0000 0000 0000 for (Ax)+, (Ay)+ and
+// 1111 0110 0000 oyyy + 32-bit address for the absolute forms
+// (oo: 00 (Ay)+,(xxx).L; 01 (xxx).L,(Ay)+; 10 (Ay),(xxx).L; 11 (xxx).L,(Ay)).
+func decodeMOVE16(data []byte, opcode uint16, inst *Instruction) error {
+	reg := uint8(opcode & 0x7)
+	if opcode&0x0020 != 0 {
+		if err := requireLength(data, 4, "MOVE16 extension word"); err != nil {
+			return err
+		}
+		ext := binary.BigEndian.Uint16(data[2:4])
+		if ext&0x8FFF != 0x8000 {
+			return fmt.Errorf("invalid MOVE16 extension word: $%04X", ext)
+		}
+		src, _, srcMeta, _ := decodeAddressingMode(nil, 3, reg, 0)
+		dst, _, dstMeta, _ := decodeAddressingMode(nil, 3, uint8((ext>>12)&0x7), 0)
+		setInstruction(data, inst, 4, "MOVE16", fmt.Sprintf("%s, %s", src, dst), srcMeta, dstMeta)
+		return nil
+	}
+
+	regMode := uint8(3)
+	if opcode&0x0010 != 0 {
+		regMode = 2
+	}
+	regStr, _, regMeta, _ := decodeAddressingMode(nil, regMode, reg, 0)
+	absStr, offset, absMeta, err := decodeEA(data, 2, 7, 1)
+	if err != nil {
+		return err
+	}
+	if opcode&0x0008 == 0 {
+		setInstruction(data, inst, offset, "MOVE16", fmt.Sprintf("%s, %s", regStr, absStr), regMeta, absMeta)
+		return nil
+	}
+	setInstruction(data, inst, offset, "MOVE16", fmt.Sprintf("%s, %s", absStr, regStr), absMeta, regMeta)
+	return nil
+}
+
+func addressIndirectOperand(reg uint8) Operand {
+	text, _, meta, _ := decodeAddressingMode(nil, 2, reg, 0)
+	meta.Text = text
+	return meta
+}
@@ -3,13 +3,14 @@ package decoders
 import (
 	"encoding/binary"
 	"fmt"
+	"math/bits"
 )
 
 func decodeMOVEQ(data []byte, opcode uint16, inst *Instruction) error {
 	dstReg := uint8((opcode >> 9) & 0x7)
 	immediate := int8(opcode & 0xFF)
 	immText := fmt.Sprintf("#%s", formatImmediateForMOVEQ(int32(immediate)))
-	setInstruction(data, inst, 2, "MOVEQ", fmt.Sprintf("%s, D%d", immText, dstReg), immediateOperand(immText, uint32(uint8(immediate)), 1), registerOperand(RegisterKindData, dstReg))
+	setInstruction(data, inst, 2, "MOVEQ", fmt.Sprintf("%s, D%d", immText, dstReg), signedImmediateOperand(immText, uint32(uint8(immediate)), 1), registerOperand(RegisterKindData, dstReg))
 	return nil
 }
 
@@ -46,6 +47,22 @@ func decodeMOVE(data []byte, opcode uint16, inst *Instruction) error {
 
 	offset := 2
 
+	if dstMode == 1 {
+		if sizeField == 1 {
+			return fmt.Errorf("MOVEA does not support byte size")
+		}
+		if err := checkEA("MOVEA", eaSource, srcMode, srcReg, sizeBytes); err != nil {
+			return err
+		}
+	} else {
+		if err := checkEA("MOVE", eaSource, srcMode, srcReg, sizeBytes); err != nil {
+			return err
+		}
+		if err := checkEA("MOVE", eaDestination, dstMode, dstReg, sizeBytes); err != nil {
+			return err
+		}
+	}
+
 	// Decode source addressing mode
 	srcStr, offset, srcMeta, err := decodeEAWithSize(data, offset, srcMode, srcReg, sizeBytes)
 	if err != nil {
@@ -53,9 +70,6 @@ func decodeMOVE(data []byte, opcode uint16, inst *Instruction) error {
 	}
 
 	if dstMode == 1 {
-		if sizeField == 1 {
-			return fmt.Errorf("MOVEA does not support byte size")
-		}
 		setInstruction(data, inst, offset, "MOVEA."+sizeStr, fmt.Sprintf("%s, A%d", srcStr, dstReg), srcMeta, registerOperand(RegisterKindAddress, dstReg))
 		return nil
 	}
@@ -72,9 +86,6 @@ func decodeMOVE(data []byte, opcode uint16, inst *Instruction) error {
 }
 
 func decodeMOVEM(data []byte, opcode uint16, inst *Instruction) error {
-	if err := requireLength(data, 4, "MOVEM register list"); err != nil {
-		return err
-	}
 	direction := (opcode >> 10) & 0x1
 	sizeStr := "W"
 	if opcode&0x0040 != 0 {
@@ -82,16 +93,24 @@ func decodeMOVEM(data []byte, opcode uint16, inst *Instruction) error {
 	}
 	mode := uint8((opcode >> 3) & 0x7)
 	reg := uint8(opcode & 0x7)
+	role := eaDestination
+	if direction != 0 {
+		role = eaSource
+	}
+	if err := checkEA("MOVEM", role, mode, reg, 0); err != nil {
+		return err
+	}
+	if err := requireLength(data, 4, "MOVEM register list"); err != nil {
+		return err
+	}
 	regListMask := binary.BigEndian.Uint16(data[2:4])
-	offset := 4
-	var addrModeStr string
-	var addrModeMeta Operand
-	var err error
-	if mode != 0 || reg != 0 {
-		addrModeStr, offset, addrModeMeta, err = decodeEA(data, offset, mode, reg)
-		if err != nil {
-			return err
-		}
+	if mode == 4 {
+		// -(An) stores the mask reversed: bit 0 is A7, bit 15 is D0
+		regListMask = bits.Reverse16(regListMask)
+	}
+	addrModeStr, offset, addrModeMeta, err := decodeEA(data, 4, mode, reg)
+	if err != nil {
+		return err
 	}
 	regListText, registers := formatRegisterList(regListMask)
 	regListMeta := registerListOperand(regListText, registers)
@@ -102,3 +121,212 @@ func decodeMOVEM(data []byte, opcode uint16, inst *Instruction) error {
 	setInstruction(data, inst, offset, "MOVEM."+sizeStr, fmt.Sprintf("%s, %s", addrModeStr, regListText), addrModeMeta, regListMeta)
 	return nil
 }
+
+// decodeMOVEFromSR - Move from Status Register
+// Format: 0100 0000 11 mmm rrr
+func decodeMOVEFromSR(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeMOVEFromStatus(data, opcode, inst, "SR")
+}
+
+// decodeMOVEFromCCR - Move from Condition Code Register (68010+)
+// Format: 0100 0010 11 mmm rrr
+func decodeMOVEFromCCR(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeMOVEFromStatus(data, opcode, inst, "CCR")
+}
+
+func decodeMOVEFromStatus(data []byte, opcode uint16, inst *Instruction, register string) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("MOVE "+register, eaDestination, mode, reg, 2); err != nil {
+		return err
+	}
+	dstStr, offset, dstMeta, err := decodeEA(data, 2, mode, reg)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, "MOVE.W", register+", "+dstStr, namedRegisterOperand(RegisterKindSystem, register), dstMeta)
+	return nil
+}
+
+// decodeMOVEToCCR - Move to Condition Code Register
+// Format: 0100 0100 11 mmm rrr
+func decodeMOVEToCCR(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeMOVEToStatus(data, opcode, inst, "CCR")
+}
+
+// decodeMOVEToSR - Move to Status Register (privileged)
+// Format: 0100 0110 11 mmm rrr
+func decodeMOVEToSR(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeMOVEToStatus(data, opcode, inst, "SR")
+}
+
+func decodeMOVEToStatus(data []byte, opcode uint16, inst *Instruction, register string) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("MOVE "+register, eaSource, mode, reg, 2); err != nil {
+		return err
+	}
+	srcStr, offset, srcMeta, err := decodeEA(data, 2, mode, reg)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, "MOVE.W", srcStr+", "+register, srcMeta, namedRegisterOperand(RegisterKindSystem, register))
+	return nil
+}
+
+// decodeMOVEUSP - Move User Stack Pointer (privileged)
+// Format: 0100 1110 0110 drrr (d=0: An → USP, d=1: USP → An)
+func decodeMOVEUSP(data []byte, opcode uint16, inst *Instruction) error {
+	reg := uint8(opcode & 0x7)
+	usp := namedRegisterOperand(RegisterKindSystem, "USP")
+	an := registerOperand(RegisterKindAddress, reg)
+	if opcode&0x0008 == 0 {
+		setInstruction(data, inst, 2, "MOVE", fmt.Sprintf("A%d, USP", reg), an, usp)
+		return nil
+	}
+	setInstruction(data, inst, 2, "MOVE", fmt.Sprintf("USP, A%d", reg), usp, an)
+	return nil
+}
+
+// decodeMOVEP - Move Peripheral Data
+// Format: 0000 ddd 1 oo 001 aaa + 16-bit displacement
+// oo: 00=Word Mem→Reg, 01=Long Mem→Reg, 10=Word Reg→Mem, 11=Long Reg→Mem
+func decodeMOVEP(data []byte, opcode uint16, inst *Instruction) error {
+	dataReg := uint8((opcode >> 9) & 0x7)
+	addrReg := uint8(opcode & 0x7)
+	opmode := (opcode >> 6) & 0x3
+	sizeStr := "W"
+	if opmode&0x1 != 0 {
+		sizeStr = "L"
+	}
+	memStr, offset, memMeta, err := decodeEA(data, 2, 5, addrReg)
+	if err != nil {
+		return err
+	}
+	regMeta := registerOperand(RegisterKindData, dataReg)
+	if opmode&0x2 == 0 {
+		setInstruction(data, inst, offset, "MOVEP."+sizeStr, fmt.Sprintf("%s, D%d", memStr, dataReg), memMeta, regMeta)
+		return nil
+	}
+	setInstruction(data, inst, offset, "MOVEP."+sizeStr, fmt.Sprintf("D%d, %s", dataReg, memStr), regMeta, memMeta)
+	return nil
+}
+
+// decodeEXG - Exchange Registers
+// Format: 1100 xxx1 ooooo yyy
+// ooooo: 01000=Dx,Dy  01001=Ax,Ay  10001=Dx,Ay
+func decodeEXG(data []byte, opcode uint16, inst *Instruction) error {
+	rx := uint8((opcode >> 9) & 0x7)
+	ry := uint8(opcode & 0x7)
+	var first, second Operand
+	switch (opcode >> 3) & 0x1F {
+	case 0x08:
+		first, second = registerOperand(RegisterKindData, rx), registerOperand(RegisterKindData, ry)
+	case 0x09:
+		first, second = registerOperand(RegisterKindAddress, rx), registerOperand(RegisterKindAddress, ry)
+	case 0x11:
+		first, second = registerOperand(RegisterKindData, rx), registerOperand(RegisterKindAddress, ry)
+	default:
+		return fmt.Errorf("unknown EXG opmode: %d", (opcode>>3)&0x1F)
+	}
+	setInstruction(data, inst, 2, "EXG", fmt.Sprintf("%s, %s", first.Text, second.Text), first, second)
+	return nil
+}
+
+// controlRegister is a MOVEC control register and the feature of the models
+// that implement it.
+type controlRegister struct {
+	name     string
+	requires Feature
+}
+
+// controlRegisters maps the 12-bit MOVEC control register field to its register.
+var controlRegisters = map[uint16]controlRegister{
+	0x000: {"SFC", 0},
+	0x001: {"DFC", 0},
+	0x002: {"CACR", Feature68020Full},
+	0x003: {"TC", Feature68040},
+	0x004: {"ITT0", Feature68040},
+	0x005: {"ITT1", Feature68040},
+	0x006: {"DTT0", Feature68040},
+	0x007: {"DTT1", Feature68040},
+	0x008: {"BUSCR", Feature68060},
+	0x800: {"USP", 0},
+	0x801: {"VBR", 0},
+	0x802: {"CAAR", FeatureCAAR},
+	0x803: {"MSP", FeatureMSP},
+	0x804: {"ISP", FeatureMSP},
+	0x805: {"MMUSR", FeaturePTEST040},
+	0x806: {"URP", Feature68040},
+	0x807: {"SRP", Feature68040},
+	0x808: {"PCR", Feature68060},
+}
+
+// generalRegisterOperand decodes the A/D bit and register number used by the
+// extension words of MOVEC, MOVES and later 68020 instructions.
+func generalRegisterOperand(ext uint16) Operand {
+	reg := uint8((ext >> 12) & 0x7)
+	if ext&0x8000 != 0 {
+		return registerOperand(RegisterKindAddress, reg)
+	}
+	return registerOperand(RegisterKindData, reg)
+}
+
+// decodeMOVEC - Move Control Register (68010+, privileged)
+// Format: 0100 1110 0111 101d + extension word (A/D, reg, 12-bit control register)
+// d=0: Rc → Rn, d=1: Rn → Rc
+func decodeMOVEC(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, "MOVEC extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	ctrl, ok := controlRegisters[ext&0x0FFF]
+	if !ok {
+		return fmt.Errorf("unknown MOVEC control register: $%03X", ext&0x0FFF)
+	}
+	inst.Requires |= ctrl.requires
+	general := generalRegisterOperand(ext)
+	control := namedRegisterOperand(RegisterKindControl, ctrl.name)
+	if opcode&0x1 == 0 {
+		setInstruction(data, inst, 4, "MOVEC", fmt.Sprintf("%s, %s", control.Text, general.Text), control, general)
+		return nil
+	}
+	setInstruction(data, inst, 4, "MOVEC", fmt.Sprintf("%s, %s", general.Text, control.Text), general, control)
+	return nil
+}
+
+// decodeMOVES - Move Address Space (68010+, privileged)
+// Format: 0000 1110 ss mmm rrr + extension word (A/D, reg, dr)
+// dr=0: <ea> → Rn, dr=1: Rn → <ea>
+func decodeMOVES(data []byte, opcode uint16, inst *Instruction) error {
+	sizeBits := (opcode >> 6) & 0x3
+	sizeBytes, err := operandSize(sizeBits, "MOVES")
+	if err != nil {
+		return err
+	}
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := requireLength(data, 4, "MOVES extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	role := eaSource
+	if ext&0x0800 != 0 {
+		role = eaDestination
+	}
+	if err := checkEA("MOVES", role, mode, reg, sizeBytes); err != nil {
+		return err
+	}
+	eaStr, offset, eaMeta, err := decodeEAWithSize(data, 4, mode, reg, sizeBytes)
+	if err != nil {
+		return err
+	}
+	general := generalRegisterOperand(ext)
+	mnemonic := "MOVES." + getSizeString(sizeBits)
+	if role == eaSource {
+		setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", eaStr, general.Text), eaMeta, general)
+		return nil
+	}
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", general.Text, eaStr), general, eaMeta)
+	return nil
+}
@@ -1,6 +1,9 @@
 package decoders
 
-import "fmt"
+import (
+	"encoding/binary"
+	"fmt"
+)
 
 func decodeMULU(data []byte, opcode uint16, inst *Instruction) error {
 	return decodeMulDiv("MULU", data, opcode, inst)
@@ -22,6 +25,9 @@ func decodeMulDiv(mn string, data []byte, opcode uint16, inst *Instruction) erro
 	dstReg := uint8((opcode >> 9) & 0x7)
 	srcMode := uint8((opcode >> 3) & 0x7)
 	srcReg := uint8(opcode & 0x7)
+	if err := checkEA(mn, eaSource, srcMode, srcReg, 2); err != nil {
+		return err
+	}
 	srcStr, offset, srcMeta, err := decodeEA(data, 2, srcMode, srcReg)
 	if err != nil {
 		return err
@@ -29,3 +35,54 @@ func decodeMulDiv(mn string, data []byte, opcode uint16, inst *Instruction) erro
 	setInstruction(data, inst, offset, mn, fmt.Sprintf("%s, D%d", srcStr, dstReg), srcMeta, registerOperand(RegisterKindData, dstReg))
 	return nil
 }
+
+// decodeMULL - 32-bit Multiply (68020+)
+// Format: 0100 1100 00 mmm rrr + extension word (0 lll s z 0000000 hhh)
+// s selects MULS.L, z a 64-bit product in Dh:Dl.
+func decodeMULL(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeLongMulDiv("MUL", data, opcode, inst)
+}
+
+// decodeDIVL - 32-bit Divide (68020+)
+// Format: 0100 1100 01 mmm rrr + extension word (0 qqq s z 0000000 rrr)
+// z selects a 64-bit dividend in Dr:Dq; otherwise distinct Dr and Dq give
+// DIVUL.L/DIVSL.L, which keep the remainder.
+func decodeDIVL(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeLongMulDiv("DIV", data, opcode, inst)
+}
+
+func decodeLongMulDiv(op string, data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, op+"x.L extension word"); err != nil {
+		return err
+	}
+	ext := binary.BigEndian.Uint16(data[2:4])
+	base := op + "U"
+	if ext&0x0800 != 0 {
+		base = op + "S"
+	}
+	srcMode := uint8((opcode >> 3) & 0x7)
+	srcReg := uint8(opcode & 0x7)
+	if err := checkEA(base, eaSource, srcMode, srcReg, 4); err != nil {
+		return err
+	}
+	srcStr, offset, srcMeta, err := decodeEAWithSize(data, 4, srcMode, srcReg, 4)
+	if err != nil {
+		return err
+	}
+
+	low := uint8((ext >> 12) & 0x7)
+	high := uint8(ext & 0x7)
+	mnemonic := base + ".L"
+	var dst Operand
+	switch {
+	case ext&0x0400 != 0:
+		dst = registerPairOperand(Register{Kind: RegisterKindData, Number: high}, Register{Kind: RegisterKindData, Number: low}, false)
+	case op == "DIV" && high != low:
+		mnemonic = base + "L.L"
+		dst = registerPairOperand(Register{Kind: RegisterKindData, Number: high}, Register{Kind: RegisterKindData, Number: low}, false)
+	default:
+		dst = registerOperand(RegisterKindData, low)
+	}
+	setInstruction(data, inst, offset, mnemonic, fmt.Sprintf("%s, %s", srcStr, dst.Text), srcMeta, dst)
+	return nil
+}
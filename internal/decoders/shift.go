@@ -26,43 +26,46 @@ func getDirectionStr(direction uint16) string {
 	return "L"
 }
 
+// decodeShiftRotate decodes the register and memory forms of ASd, LSd, ROXd
+// and ROd.
+// Register: 1110 ccc d ss i tt rrr (count/register c, i=1: count in Dc)
+// Memory:   1110 0tt d 11 mmm rrr (always word sized)
 func decodeShiftRotate(data []byte, opcode uint16, inst *Instruction) error {
 	direction := (opcode >> 8) & 0x1
 	size := (opcode >> 6) & 0x3
 	reg := uint8(opcode & 0x7)
-	sizeStr := []string{"B", "W", "L", "?"}[size]
-	rotType := (opcode >> 9) & 0x7
-	countIsReg := (opcode >> 5) & 0x1
 	dirStr := getDirectionStr(direction)
 
-	if rotType <= 3 {
-		// Register shift: extract shift count
-		mnemonicBase := getMnemonicBase(uint16(rotType))
-		var countStr string
-		if countIsReg == 0 {
-			count := (opcode >> 9) & 0x7
-			if count == 0 {
-				count = 8
-			}
-			countStr = fmt.Sprintf("#%d", count)
-			setInstruction(data, inst, 2, fmt.Sprintf("%s%s.%s", mnemonicBase, dirStr, sizeStr), fmt.Sprintf("%s, D%d", countStr, reg), immediateOperand(countStr, uint32(count), 1), registerOperand(RegisterKindData, reg))
-		} else {
-			countReg := (opcode >> 9) & 0x7
-			countStr = fmt.Sprintf("D%d", countReg)
-			setInstruction(data, inst, 2, fmt.Sprintf("%s%s.%s", mnemonicBase, dirStr, sizeStr), fmt.Sprintf("%s, D%d", countStr, reg), registerOperand(RegisterKindData, uint8(countReg)), registerOperand(RegisterKindData, reg))
+	if size != 3 {
+		// Register shift: the type is in bits 4-3, the count in bits 11-9
+		mnemonic := fmt.Sprintf("%s%s.%s", getMnemonicBase((opcode>>3)&0x3), dirStr, getSizeString(size))
+		count := uint8((opcode >> 9) & 0x7)
+		if (opcode>>5)&0x1 == 1 {
+			countStr := fmt.Sprintf("D%d", count)
+			setInstruction(data, inst, 2, mnemonic, fmt.Sprintf("%s, D%d", countStr, reg), registerOperand(RegisterKindData, count), registerOperand(RegisterKindData, reg))
+			return nil
 		}
-	} else {
-		// Memory shift: extract addressing mode
-		memMode := uint8((opcode >> 3) & 0x7)
-		memReg := uint8(opcode & 0x7)
-		memShiftType := (opcode >> 6) & 0x3
-		mnemonicBase := getMnemonicBase(memShiftType)
-		mnemonic := fmt.Sprintf("%s%s.W", mnemonicBase, dirStr)
-		operand, extraWords, meta, err := decodeAddressingMode(data[2:], memMode, memReg, 2)
-		if err != nil {
-			return err
+		if count == 0 {
+			count = 8
 		}
-		setInstruction(data, inst, 2+extraWords*2, mnemonic, operand, meta)
+		countStr := fmt.Sprintf("#%d", count)
+		setInstruction(data, inst, 2, mnemonic, fmt.Sprintf("%s, D%d", countStr, reg), immediateOperand(countStr, uint32(count), 1), registerOperand(RegisterKindData, reg))
+		return nil
 	}
+
+	// Memory shift: shifts a word by one bit, the type is in bits 10-9
+	if opcode&0x0800 != 0 {
+		return fmt.Errorf("invalid memory shift opcode: $%04X", opcode)
+	}
+	memMode := uint8((opcode >> 3) & 0x7)
+	mnemonicBase := getMnemonicBase((opcode >> 9) & 0x3)
+	if err := checkEA(mnemonicBase+dirStr, eaDestination, memMode, reg, 2); err != nil {
+		return err
+	}
+	operand, offset, meta, err := decodeEA(data, 2, memMode, reg)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, mnemonicBase+dirStr+".W", operand, meta)
 	return nil
 }
@@ -1,5 +1,7 @@
 package decoders
 
+import "fmt"
+
 func decodeCLR(data []byte, opcode uint16, inst *Instruction) error {
 	return decodeSingleOp(data, opcode, inst, "CLR")
 }
@@ -16,15 +18,78 @@ func decodeNOT(data []byte, opcode uint16, inst *Instruction) error {
 	return decodeSingleOp(data, opcode, inst, "NOT")
 }
 
+// decodeTST - Test an Operand
+// The 68020 and CPU32 also accept An (word/long), PC-relative and immediate
+// operands; those forms are recorded as requiring Feature68020.
 func decodeTST(data []byte, opcode uint16, inst *Instruction) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	size, err := operandSize((opcode>>6)&0x3, "TST")
+	if err != nil {
+		return err
+	}
+	if checkEA("TST", eaDestination, mode, reg, size) != nil && checkEA("TST 68020", eaDestination, mode, reg, size) == nil {
+		inst.Requires |= Feature68020
+		return decodeSingleOpRule(data, opcode, inst, "TST", "TST 68020")
+	}
 	return decodeSingleOp(data, opcode, inst, "TST")
 }
 
+// decodeTAS - Test and Set (byte operand)
+// Format: 0100 1010 11 mmm rrr
+func decodeTAS(data []byte, opcode uint16, inst *Instruction) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("TAS", eaDestination, mode, reg, 1); err != nil {
+		return err
+	}
+	operand, offset, meta, err := decodeEAWithSize(data, 2, mode, reg, 1)
+	if err != nil {
+		return err
+	}
+	setInstruction(data, inst, offset, "TAS", operand, meta)
+	return nil
+}
+
+// decodeEXT - Sign-Extend
+// Format: 0100 100 ooo 000 rrr (ooo: 010=Byte→Word, 011=Word→Long)
+func decodeEXT(data []byte, opcode uint16, inst *Instruction) error {
+	reg := uint8(opcode & 0x7)
+	sizeStr := "W"
+	if (opcode>>6)&0x7 == 3 {
+		sizeStr = "L"
+	}
+	setInstruction(data, inst, 2, "EXT."+sizeStr, fmt.Sprintf("D%d", reg), registerOperand(RegisterKindData, reg))
+	return nil
+}
+
+// decodeEXTB - Sign-Extend Byte to Long (68020+)
+// Format: 0100 1001 1100 0rrr
+func decodeEXTB(data []byte, opcode uint16, inst *Instruction) error {
+	reg := uint8(opcode & 0x7)
+	setInstruction(data, inst, 2, "EXTB.L", fmt.Sprintf("D%d", reg), registerOperand(RegisterKindData, reg))
+	return nil
+}
+
 func decodeSingleOp(data []byte, opcode uint16, inst *Instruction, mnemonic string) error {
-	sizeStr := getSizeString((opcode >> 6) & 0x3)
+	return decodeSingleOpRule(data, opcode, inst, mnemonic, mnemonic)
+}
+
+// decodeSingleOpRule decodes a sized single-operand instruction whose
+// addressing modes are checked against the eaRules entry named rule.
+func decodeSingleOpRule(data []byte, opcode uint16, inst *Instruction, mnemonic, rule string) error {
+	sizeBits := (opcode >> 6) & 0x3
+	sizeStr := getSizeString(sizeBits)
+	sizeBytes, err := operandSize(sizeBits, mnemonic)
+	if err != nil {
+		return err
+	}
 	mode := uint8((opcode >> 3) & 0x7)
 	reg := uint8(opcode & 0x7)
-	operand, offset, meta, err := decodeEA(data, 2, mode, reg)
+	if err := checkEA(rule, eaDestination, mode, reg, sizeBytes); err != nil {
+		return err
+	}
+	operand, offset, meta, err := decodeEAWithSize(data, 2, mode, reg, sizeBytes)
 	if err != nil {
 		return err
 	}
@@ -9,6 +9,9 @@ func decodeLEA(data []byte, opcode uint16, inst *Instruction) error {
 	regX := uint8((opcode >> 9) & 0x7)
 	mode := uint8((opcode >> 3) & 0x7)
 	reg := uint8(opcode & 0x7)
+	if err := checkEA("LEA", eaSource, mode, reg, 0); err != nil {
+		return err
+	}
 	operand, offset, meta, err := decodeEA(data, 2, mode, reg)
 	if err != nil {
 		return err
@@ -20,6 +23,9 @@ func decodeLEA(data []byte, opcode uint16, inst *Instruction) error {
 func decodePEA(data []byte, opcode uint16, inst *Instruction) error {
 	mode := uint8((opcode >> 3) & 0x7)
 	reg := uint8(opcode & 0x7)
+	if err := checkEA("PEA", eaSource, mode, reg, 0); err != nil {
+		return err
+	}
 	operand, offset, meta, err := decodeEA(data, 2, mode, reg)
 	if err != nil {
 		return err
@@ -57,6 +63,133 @@ func decodeTRAPV(data []byte, opcode uint16, inst *Instruction) error {
 	return nil
 }
 
+// decodeLINEA - Line 1010 emulator trap
+// Format: 1010 nnnn nnnn nnnn (12-bit trap number)
+func decodeLINEA(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeLineTrap("LINEA", data, opcode, inst)
+}
+
+// decodeLINEF - Line 1111 emulator trap
+// Format: 1111 nnnn nnnn nnnn (12-bit trap number)
+func decodeLINEF(data []byte, opcode uint16, inst *Instruction) error {
+	return decodeLineTrap("LINEF", data, opcode, inst)
+}
+
+func decodeLineTrap(mnemonic string, data []byte, opcode uint16, inst *Instruction) error {
+	trap := uint32(opcode & 0x0FFF)
+	immText := fmt.Sprintf("#%s", formatImmediate(trap, 2))
+	setInstruction(data, inst, 2, mnemonic, immText, immediateOperand(immText, trap, 2))
+	return nil
+}
+
+// decodeLINK - Link and Allocate
+// Format: 0100 1110 0101 0rrr + 16-bit displacement
+func decodeLINK(data []byte, opcode uint16, inst *Instruction) error {
+	reg := uint8(opcode & 0x7)
+	if err := requireLength(data, 4, "LINK displacement"); err != nil {
+		return err
+	}
+	displacement := binary.BigEndian.Uint16(data[2:4])
+	immText := fmt.Sprintf("#%s", formatImmediateForMOVEQ(int32(int16(displacement))))
+	setInstruction(data, inst, 4, "LINK", fmt.Sprintf("A%d, %s", reg, immText), registerOperand(RegisterKindAddress, reg), signedImmediateOperand(immText, uint32(displacement), 2))
+	return nil
+}
+
+// decodeLINKL - Link and Allocate with 32-bit displacement (68020+)
+// Format: 0100 1000 0000 1rrr + 32-bit displacement
+func decodeLINKL(data []byte, opcode uint16, inst *Instruction) error {
+	reg := uint8(opcode & 0x7)
+	if err := requireLength(data, 6, "LINK.L displacement"); err != nil {
+		return err
+	}
+	displacement := binary.BigEndian.Uint32(data[2:6])
+	immText := fmt.Sprintf("#%s", formatImmediateForMOVEQ(int32(displacement)))
+	setInstruction(data, inst, 6, "LINK.L", fmt.Sprintf("A%d, %s", reg, immText), registerOperand(RegisterKindAddress, reg), signedImmediateOperand(immText, displacement, 4))
+	return nil
+}
+
+// decodeUNLK - Unlink
+// Format: 0100 1110 0101 1rrr
+func decodeUNLK(data []byte, opcode uint16, inst *Instruction) error {
+	reg := uint8(opcode & 0x7)
+	setInstruction(data, inst, 2, "UNLK", fmt.Sprintf("A%d", reg), registerOperand(RegisterKindAddress, reg))
+	return nil
+}
+
+// decodeRTD - Return and Deallocate (68010+)
+// Format: 0100 1110 0111 0100 + 16-bit displacement
+func decodeRTD(data []byte, opcode uint16, inst *Instruction) error {
+	if err := requireLength(data, 4, "RTD displacement"); err != nil {
+		return err
+	}
+	displacement := binary.BigEndian.Uint16(data[2:4])
+	immText := fmt.Sprintf("#%s", formatImmediateForMOVEQ(int32(int16(displacement))))
+	setInstruction(data, inst, 4, "RTD", immText, signedImmediateOperand(immText, uint32(displacement), 2))
+	return nil
+}
+
+// decodeBKPT - Breakpoint (68010+)
+// Format: 0100 1000 0100 1vvv
+func decodeBKPT(data []byte, opcode uint16, inst *Instruction) error {
+	vector := uint32(opcode & 0x7)
+	immText := fmt.Sprintf("#%d", vector)
+	setInstruction(data, inst, 2, "BKPT", immText, immediateOperand(immText, vector, 1))
+	return nil
+}
+
+// decodeCALLM - Call Module (68020 only)
+// Format: 0000 0110 11 mmm rrr + argument count word
+func decodeCALLM(data []byte, opcode uint16, inst *Instruction) error {
+	mode := uint8((opcode >> 3) & 0x7)
+	reg := uint8(opcode & 0x7)
+	if err := checkEA("CALLM", eaSource, mode, reg, 0); err != nil {
+		return err
+	}
+	if err := requireLength(data, 4, "CALLM argument count"); err != nil {
+		return err
+	}
+	count := uint32(data[3])
+	operand, offset, meta, err := decodeEA(data, 4, mode, reg)
+	if err != nil {
+		return err
+	}
+	immText := fmt.Sprintf("#%s", formatImmediate(count, 1))
+	setInstruction(data, inst, offset, "CALLM", fmt.Sprintf("%s, %s", immText, operand), immediateOperand(immText, count, 1), meta)
+	return nil
+}
+
+// decodeRTM - Return from Module (68020 only)
+// Format: 0000 0110 1100 drrr
+func decodeRTM(data []byte, opcode uint16, inst *Instruction) error {
+	reg := uint8(opcode & 0x7)
+	operand := registerOperand(RegisterKindData, reg)
+	if opcode&0x8 != 0 {
+		operand = registerOperand(RegisterKindAddress, reg)
+	}
+	setInstruction(data, inst, 2, "RTM", operand.Text, operand)
+	return nil
+}
+
+func decodeRESET(data []byte, opcode uint16, inst *Instruction) error {
+	setInstruction(data, inst, 2, "RESET", "")
+	return nil
+}
+
+func decodeRTE(data []byte, opcode uint16, inst *Instruction) error {
+	setInstruction(data, inst, 2, "RTE", "")
+	return nil
+}
+
+func decodeRTR(data []byte, opcode uint16, inst *Instruction) error {
+	setInstruction(data, inst, 2, "RTR", "")
+	return nil
+}
+
+func decodeILLEGAL(data []byte, opcode uint16, inst *Instruction) error {
+	setInstruction(data, inst, 2, "ILLEGAL", "")
+	return nil
+}
+
 func formatRegisterList(regListMask uint16) (string, []string) {
 	registers := []string{}
 	for i := 0; i < 8; i++ {
@@ -86,9 +219,9 @@ func formatRegisterRange(registers []string) string {
 		end := start
 		j := i + 1
 		for j < len(registers) {
-			prevNum := extractRegNum(registers[j-1])
-			currNum := extractRegNum(registers[j])
-			if prevNum >= 0 && currNum >= 0 && currNum == prevNum+1 {
+			prevPrefix, prevNum := splitRegisterName(registers[j-1])
+			currPrefix, currNum := splitRegisterName(registers[j])
+			if prevNum >= 0 && currNum >= 0 && prevPrefix == currPrefix && currNum == prevNum+1 {
 				end = registers[j]
 				j++
 			} else {
@@ -105,13 +238,15 @@ func formatRegisterRange(registers []string) string {
 	return result
 }
 
-func extractRegNum(regName string) int {
+// splitRegisterName splits a register name such as "D3" or "FP7" into its
+// prefix and number. The number is -1 when the name does not end in 0-7.
+func splitRegisterName(regName string) (string, int) {
 	if len(regName) < 2 {
-		return -1
+		return regName, -1
 	}
-	num := regName[1] - '0'
+	num := regName[len(regName)-1] - '0'
 	if num <= 7 {
-		return int(num)
+		return regName[:len(regName)-1], int(num)
 	}
-	return -1
+	return regName, -1
 }
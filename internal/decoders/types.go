@@ -9,6 +9,15 @@ const (
 	maskFFF0  = 0xFFF0
 	maskFFF8  = 0xFFF8 // SWAP instruction mask
 	maskF1F0  = 0xF1F0
+	maskF0F8  = 0xF0F8
+	maskF0C0  = 0xF0C0
+	maskF1F8  = 0xF1F8
+	maskF138  = 0xF138
+	maskF0FF  = 0xF0FF
+	maskF8C0  = 0xF8C0
+	maskFFE0  = 0xFFE0
+	maskFFD8  = 0xFFD8
+	maskFFB8  = 0xFFB8
 	maskF1C0  = 0xF1C0
 	maskF100  = 0xF100
 	maskF000  = 0xF000
@@ -24,6 +33,79 @@ const (
 	valTRAPV = 0x4E76
 	valTRAP  = 0x4E40
 
+	// system control
+	valRESET      = 0x4E70
+	valRTE        = 0x4E73
+	valRTR        = 0x4E77
+	valILLEGAL    = 0x4AFC
+	valMOVEUSP    = 0x4E60
+	valMOVEFromSR = 0x40C0
+	valMOVEToCCR  = 0x44C0
+	valMOVEToSR   = 0x46C0
+	valORIToCCR   = 0x003C
+	valORIToSR    = 0x007C
+	valANDIToCCR  = 0x023C
+	valANDIToSR   = 0x027C
+	valEORIToCCR  = 0x0A3C
+	valEORIToSR   = 0x0A7C
+
+	// 68000 data instructions
+	valEXGData    = 0xC140
+	valEXGAddr    = 0xC148
+	valEXGDataAdr = 0xC188
+	valEXTW       = 0x4880
+	valEXTL       = 0x48C0
+	valCHK        = 0x4180
+	valLINK       = 0x4E50
+	valUNLK       = 0x4E58
+	valTAS        = 0x4AC0
+	valNBCD       = 0x4800
+	valMOVEP      = 0x0108
+
+	// 68010 additions
+	valMOVEFromCCR = 0x42C0
+	valMOVECToReg  = 0x4E7A
+	valMOVECToCtrl = 0x4E7B
+	valMOVES       = 0x0E00
+	valRTD         = 0x4E74
+	valBKPT        = 0x4848
+
+	// 68020 integer additions
+	valBitfield = 0xE8C0
+	valCASB     = 0x0AC0
+	valCASW     = 0x0CC0
+	valCASL     = 0x0EC0
+	valCAS2W    = 0x0CFC
+	valCAS2L    = 0x0EFC
+	valCHK2B    = 0x00C0
+	valCHK2W    = 0x02C0
+	valCHK2L    = 0x04C0
+	valCALLM    = 0x06C0
+	valRTM      = 0x06C0
+	valPACK     = 0x8140
+	valUNPK     = 0x8180
+	valMULL     = 0x4C00
+	valDIVL     = 0x4C40
+	valTRAPccW  = 0x50FA
+	valTRAPccL  = 0x50FB
+	valTRAPcc   = 0x50FC
+	valEXTB     = 0x49C0
+	valLINKL    = 0x4808
+	valCHKL     = 0x4100
+
+	// extended arithmetic and compare/EOR split (0x9/0xB/0xD lines)
+	valADDXB = 0xD100
+	valADDXW = 0xD140
+	valADDXL = 0xD180
+	valSUBXB = 0x9100
+	valSUBXW = 0x9140
+	valSUBXL = 0x9180
+	valCMPMB = 0xB108
+	valCMPMW = 0xB148
+	valCMPML = 0xB188
+	valCMPA  = 0xB0C0
+	valEOR   = 0xB100
+
 	valMOVEMReg = 0x4880
 	valMOVEMMem = 0x4C80
 
@@ -39,16 +121,22 @@ const (
 	valLEA = 0x41C0
 	valPEA = 0x4840
 
+	// quick arithmetic and conditional line (0x5xxx)
+	valDBcc = 0x50C8
+	valScc  = 0x50C0
+	valADDQ = 0x5000
+	valSUBQ = 0x5100
+
 	valMULU = 0xC0C0
 	valMULS = 0xC1C0
 	valDIVU = 0x80C0
 	valDIVS = 0x81C0
 
-	// bit op values (register form)
-	valBTSTReg = 0x0500
-	valBCHGReg = 0x0540
-	valBCLRReg = 0x0580
-	valBSETReg = 0x05C0
+	// bit op values (register form, any bit-number register)
+	valBTSTReg = 0x0100
+	valBCHGReg = 0x0140
+	valBCLRReg = 0x0180
+	valBSETReg = 0x01C0
 
 	// bit op values (immediate form)
 	valBTSTImm = 0x0800
@@ -80,6 +168,44 @@ const (
 	valAND   = 0xC000
 	valADD   = 0xD000
 	valSHIFT = 0xE000
+	valLINEA = 0xA000
+	valLINEF = 0xF000
+
+	// 68881/68882 FPU (coprocessor ID 1)
+	valFPUGeneral = 0xF200
+	valFScc       = 0xF240
+	valFDBcc      = 0xF248
+	valFTRAPccW   = 0xF27A
+	valFTRAPccL   = 0xF27B
+	valFTRAPcc    = 0xF27C
+	valFBccW      = 0xF280
+	valFBccL      = 0xF2C0
+	valFSAVE      = 0xF300
+	valFRESTORE   = 0xF340
+
+	// PMMU (coprocessor ID 0) and 68040/68060 system instructions
+	valPMMU        = 0xF000
+	valCacheOp     = 0xF400
+	valPFLUSH040   = 0xF500
+	valPTEST040    = 0xF548
+	valPLPA        = 0xF588
+	valMOVE16PostI = 0xF620
+	valMOVE16Abs   = 0xF600
+
+	// CPU32 table lookup and LPSTOP
+	valCPU32 = 0xF800
+
+	// ColdFire ISA_B/ISA_C and MAC/EMAC
+	valMOV3Q      = 0xA140
+	valMVS        = 0x7100
+	valSATS       = 0x4C80
+	valBITREV     = 0x00C0
+	valBYTEREV    = 0x02C0
+	valFF1        = 0x04C0
+	valMAC        = 0xA000
+	valMACToReg   = 0xA100
+	valMACFromReg = 0xA180
+	valMOVCLR     = 0xA1C0
 )
 
 // Instruction represents a single disassembled instruction.
@@ -94,6 +220,9 @@ type Instruction struct {
 	// ExtensionWords holds any decoded words that follow the opcode word.
 	ExtensionWords []uint16
 	Metadata       Metadata
+	// Requires collects the features needed by this particular encoding
+	// beyond its opcode pattern, e.g. Bcc.L or a scaled index register.
+	Requires Feature
 }
 
 type Metadata struct {
@@ -103,6 +232,18 @@ type Metadata struct {
 	Operands        []Operand
 	BranchTarget    *uint32
 	ImmediateValues []ImmediateValue
+	// NonCanonical marks encodings an assembler would not produce from the
+	// instruction text, such as a set high byte in a .B immediate word or a
+	// 68020 full extension word where the brief format fits.
+	NonCanonical bool
+	// Reads and Writes are the registers the instruction reads and writes,
+	// including implicit ones such as A7 for BSR and the An of (An)+.
+	// Both are sorted and free of duplicates.
+	Reads  []Register
+	Writes []Register
+	// ConditionCodes is how the instruction affects and uses X, N, Z, V
+	// and C.
+	ConditionCodes ConditionCodes
 }
 
 type OperandKind string
@@ -113,6 +254,10 @@ const (
 	OperandKindEffectiveAddr OperandKind = "effective_address"
 	OperandKindRegisterList  OperandKind = "register_list"
 	OperandKindBranchTarget  OperandKind = "branch_target"
+	OperandKindRegisterPair  OperandKind = "register_pair"
+	// OperandKindShift is the << or >> product shift of the ColdFire MAC
+	// instructions, carried in Text.
+	OperandKindShift OperandKind = "shift"
 )
 
 type RegisterKind string
@@ -121,17 +266,42 @@ const (
 	RegisterKindData    RegisterKind = "data"
 	RegisterKindAddress RegisterKind = "address"
 	RegisterKindPC      RegisterKind = "pc"
+	RegisterKindSystem  RegisterKind = "system"
+	RegisterKindControl RegisterKind = "control"
+	// RegisterKindFP is a floating-point data register FP0-FP7.
+	RegisterKindFP RegisterKind = "fp"
+	// RegisterKindFPControl is one of the FPU control registers FPCR, FPSR
+	// and FPIAR, identified by Register.Name.
+	RegisterKindFPControl RegisterKind = "fp_control"
+	// RegisterKindMMU is a PMMU register such as TC, TT0, SRP, CRP or MMUSR,
+	// identified by Register.Name.
+	RegisterKindMMU RegisterKind = "mmu"
+	// RegisterKindCache selects the caches of CINV/CPUSH: NC, DC, IC or BC.
+	RegisterKindCache RegisterKind = "cache"
+	// RegisterKindMAC is a ColdFire MAC/EMAC register: ACC0-ACC3, MACSR,
+	// MASK, ACCEXT01 or ACCEXT23, identified by Register.Name.
+	RegisterKindMAC RegisterKind = "mac"
 )
 
+// Register identifies a CPU register. Numbered registers (Dn, An) use Number;
+// named registers such as SR, CCR and USP carry their name in Name.
 type Register struct {
 	Kind   RegisterKind
 	Number uint8
+	Name   string
 }
 
+// ImmediateValue is an immediate operand. For FPU immediates (.S, .D, .X and
+// .P data) Float holds the decoded value and Value the first 32 bits of the
+// raw encoding.
 type ImmediateValue struct {
 	Value  uint32
 	Signed int32
 	Size   uint8
+	Float  *float64
+	// SignExtended marks data the instruction sign-extends, such as the
+	// MOVEQ data or the LINK displacement; Signed is its value.
+	SignExtended bool
 }
 
 type EffectiveAddressKind string
@@ -156,16 +326,52 @@ type IndexRegister struct {
 	Size     string
 }
 
+// MemoryIndirection describes the 68020 memory-indirect forms of the indexed
+// addressing modes.
+type MemoryIndirection string
+
+const (
+	IndirectionNone        MemoryIndirection = ""
+	IndirectionPreIndexed  MemoryIndirection = "pre_indexed"
+	IndirectionPostIndexed MemoryIndirection = "post_indexed"
+)
+
+// EffectiveAddress describes a decoded addressing mode. For the indexed modes a
+// nil Base or Index means the register is suppressed (68020 full format),
+// Displacement is the base displacement and OuterDisplacement the displacement
+// added after a memory-indirect fetch. Scale is 1, 2, 4 or 8 when an index
+// register is present.
 type EffectiveAddress struct {
-	Kind            EffectiveAddressKind
-	Mode            uint8
-	Register        uint8
-	Base            *Register
-	Displacement    *int32
-	AbsoluteAddress *uint32
-	ResolvedAddress *uint32
-	Immediate       *ImmediateValue
-	Index           *IndexRegister
+	Kind              EffectiveAddressKind
+	Mode              uint8
+	Register          uint8
+	Base              *Register
+	Displacement      *int32
+	AbsoluteAddress   *uint32
+	ResolvedAddress   *uint32
+	Immediate         *ImmediateValue
+	Index             *IndexRegister
+	Scale             uint8
+	OuterDisplacement *int32
+	Indirection       MemoryIndirection
+}
+
+// Bitfield holds the {offset:width} specifier of the 68020 bit-field
+// instructions. OffsetRegister/WidthRegister are set when the value comes from
+// a data register; otherwise Offset (0-31) and Width (1-32) are immediates.
+type Bitfield struct {
+	Offset         uint8
+	OffsetRegister *Register
+	Width          uint8
+	WidthRegister  *Register
+}
+
+// RegisterPair is a colon-separated register pair such as Dh:Dl for MULU.L or
+// (Rn1):(Rn2) for CAS2, where Indirect marks the memory-indirect form.
+type RegisterPair struct {
+	First    Register
+	Second   Register
+	Indirect bool
 }
 
 type Operand struct {
@@ -176,6 +382,16 @@ type Operand struct {
 	EffectiveAddress *EffectiveAddress
 	RegisterList     []string
 	BranchTarget     *uint32
+	Bitfield         *Bitfield
+	RegisterPair     *RegisterPair
+	// Suffix is syntax attached to the operand and included in Text: the
+	// MAC .U/.L register half or & mask flag, or the FMOVE.P k-factor such
+	// as {#-2} or {D1}.
+	Suffix string
+
+	requires     Feature // addressing form needs more than the 68000 (e.g. scaled index)
+	pcOffset     int     // offset of the extension word PC-relative modes are relative to
+	nonCanonical bool    // encoding an assembler would not reproduce from Text
 }
 
 // OpcodeDecoder is the type for decoder functions
@@ -183,9 +399,11 @@ type OpcodeDecoder func(data []byte, opcode uint16, inst *Instruction) error
 
 // OpcodePattern defines a pattern for opcode recognition
 type OpcodePattern struct {
-	Mask    uint16        // Bit mask for recognition
-	Value   uint16        // Expected value after masking
-	Decoder OpcodeDecoder // Decoder function
+	Mask     uint16        // Bit mask for recognition
+	Value    uint16        // Expected value after masking
+	Decoder  OpcodeDecoder // Decoder function
+	Requires Feature       // Features the CPU must provide (0 = every model)
+	Optional bool          // When unavailable, dispatch continues with the next pattern
 }
 
 func exact(value uint16, decoder OpcodeDecoder) OpcodePattern {
@@ -200,20 +418,41 @@ func masked(mask, value uint16, decoder OpcodeDecoder) OpcodePattern {
 // Each bucket keeps the original precedence for that 4K region of the opcode space.
 var opcodeBuckets = [16][]OpcodePattern{
 	0x0: {
-		masked(maskBitOp, valBTSTReg, decodeBTST), // BTST (register)
-		masked(maskBitOp, valBTSTImm, decodeBTST), // BTST (immediate)
-		masked(maskBitOp, valBCHGReg, decodeBCHG), // BCHG (register)
-		masked(maskBitOp, valBCHGImm, decodeBCHG), // BCHG (immediate)
-		masked(maskBitOp, valBCLRReg, decodeBCLR), // BCLR (register)
-		masked(maskBitOp, valBCLRImm, decodeBCLR), // BCLR (immediate)
-		masked(maskBitOp, valBSETReg, decodeBSET), // BSET (register)
-		masked(maskBitOp, valBSETImm, decodeBSET), // BSET (immediate)
-		masked(maskFF00, valADDI, decodeADDI),     // ADDI
-		masked(maskFF00, valSUBI, decodeSUBI),     // SUBI
-		masked(maskFF00, valANDI, decodeANDI),     // ANDI
-		masked(maskFF00, valORI, decodeORI),       // ORI
-		masked(maskFF00, valEORI, decodeEORI),     // EORI
-		masked(maskFF00, valCMPI, decodeCMPI),     // CMPI
+		exact(valORIToCCR, decodeLogicalToCCR),                                                     // ORI to CCR
+		exact(valORIToSR, decodeLogicalToSR),                                                       // ORI to SR
+		exact(valANDIToCCR, decodeLogicalToCCR),                                                    // ANDI to CCR
+		exact(valANDIToSR, decodeLogicalToSR),                                                      // ANDI to SR
+		exact(valEORIToCCR, decodeLogicalToCCR),                                                    // EORI to CCR
+		exact(valEORIToSR, decodeLogicalToSR),                                                      // EORI to SR
+		exact(valCAS2W, decodeCAS2).requires(Feature68020Full),                                     // CAS2.W (before CAS/CMPI)
+		exact(valCAS2L, decodeCAS2).requires(Feature68020Full),                                     // CAS2.L (before CAS/MOVES)
+		masked(maskFFC0, valCASB, decodeCAS).requires(Feature68020Full),                            // CAS.B (before EORI)
+		masked(maskFFC0, valCASW, decodeCAS).requires(Feature68020Full),                            // CAS.W (before CMPI)
+		masked(maskFFC0, valCASL, decodeCAS).requires(Feature68020Full),                            // CAS.L (before MOVES)
+		masked(maskFFF8, valBITREV, decodeColdFireBitOp).requires(FeatureColdFireISAC).optional(),  // BITREV (before CHK2)
+		masked(maskFFF8, valBYTEREV, decodeColdFireBitOp).requires(FeatureColdFireISAC).optional(), // BYTEREV (before CHK2)
+		masked(maskFFF8, valFF1, decodeColdFireBitOp).requires(FeatureColdFireISAC).optional(),     // FF1 (before CHK2)
+		masked(maskFFC0, valCHK2B, decodeCHK2CMP2).requires(Feature68020),                          // CHK2/CMP2.B (before ORI)
+		masked(maskFFC0, valCHK2W, decodeCHK2CMP2).requires(Feature68020),                          // CHK2/CMP2.W (before ANDI)
+		masked(maskFFC0, valCHK2L, decodeCHK2CMP2).requires(Feature68020),                          // CHK2/CMP2.L (before SUBI)
+		masked(maskFFF0, valRTM, decodeRTM).requires(FeatureCALLM),                                 // RTM (before CALLM)
+		masked(maskFFC0, valCALLM, decodeCALLM).requires(FeatureCALLM),                             // CALLM (before ADDI)
+		masked(maskF138, valMOVEP, decodeMOVEP),                                                    // MOVEP
+		masked(maskF1C0, valBTSTReg, decodeBTST),                                                   // BTST (register)
+		masked(maskBitOp, valBTSTImm, decodeBTST),                                                  // BTST (immediate)
+		masked(maskF1C0, valBCHGReg, decodeBCHG),                                                   // BCHG (register)
+		masked(maskBitOp, valBCHGImm, decodeBCHG),                                                  // BCHG (immediate)
+		masked(maskF1C0, valBCLRReg, decodeBCLR),                                                   // BCLR (register)
+		masked(maskBitOp, valBCLRImm, decodeBCLR),                                                  // BCLR (immediate)
+		masked(maskF1C0, valBSETReg, decodeBSET),                                                   // BSET (register)
+		masked(maskBitOp, valBSETImm, decodeBSET),                                                  // BSET (immediate)
+		masked(maskFF00, valADDI, decodeADDI),                                                      // ADDI
+		masked(maskFF00, valSUBI, decodeSUBI),                                                      // SUBI
+		masked(maskFF00, valANDI, decodeANDI),                                                      // ANDI
+		masked(maskFF00, valORI, decodeORI),                                                        // ORI
+		masked(maskFF00, valEORI, decodeEORI),                                                      // EORI
+		masked(maskFF00, valCMPI, decodeCMPI),                                                      // CMPI
+		masked(maskFF00, valMOVES, decodeMOVES).requires(Feature68010),                             // MOVES
 	},
 	0x1: {
 		masked(maskF000, valMOVE_B, decodeMOVE), // MOVE.B
@@ -229,49 +468,133 @@ var opcodeBuckets = [16][]OpcodePattern{
 		exact(valRTS, decodeRTS),
 		exact(valSTOP, decodeSTOP),
 		exact(valTRAPV, decodeTRAPV),
+		exact(valRESET, decodeRESET),
+		exact(valRTE, decodeRTE),
+		exact(valRTR, decodeRTR),
+		exact(valILLEGAL, decodeILLEGAL),
+		exact(valRTD, decodeRTD).requires(Feature68010),
+		exact(valMOVECToReg, decodeMOVEC).requires(Feature68010),
+		exact(valMOVECToCtrl, decodeColdFireMOVEC).requires(FeatureColdFire).optional(), // MOVEC (ColdFire registers)
+		exact(valMOVECToCtrl, decodeMOVEC).requires(Feature68010),
 		masked(maskFFF0, valTRAP, decodeTRAP),
-		masked(maskFB80, valMOVEMReg, decodeMOVEM), // MOVEM Reg→Mem
-		masked(maskFB80, valMOVEMMem, decodeMOVEM), // MOVEM Mem→Reg
-		masked(maskFF00, valCLR, decodeCLR),        // CLR
-		masked(maskFF00, valNEG, decodeNEG),        // NEG
-		masked(maskFF00, valNEGX, decodeNEGX),      // NEGX
-		masked(maskFF00, valNOT, decodeNOT),        // NOT
-		masked(maskFF00, valTST, decodeTST),        // TST
-		masked(maskFFC0, valJSR, decodeJSR),        // JSR
-		masked(maskFFC0, valJMP, decodeJMP),        // JMP
-		masked(maskF1C0, valLEA, decodeLEA),        // LEA
-		masked(maskFFF8, valPEA, decodeSWAP),       // SWAP
-		masked(maskFFC0, valPEA, decodePEA),        // PEA
+		masked(maskFFF8, valLINK, decodeLINK),                                              // LINK
+		masked(maskFFF8, valLINKL, decodeLINKL).requires(Feature68020),                     // LINK.L (before NBCD)
+		masked(maskFFF8, valUNLK, decodeUNLK),                                              // UNLK
+		masked(maskFFF8, valEXTW, decodeEXT),                                               // EXT.W (before MOVEM)
+		masked(maskFFF8, valEXTL, decodeEXT),                                               // EXT.L (before MOVEM)
+		masked(maskFFF8, valEXTB, decodeEXTB).requires(Feature68020),                       // EXTB.L (before LEA)
+		masked(maskFFC0, valMULL, decodeMULL).requires(Feature68020),                       // MULU.L/MULS.L
+		masked(maskFFC0, valDIVL, decodeColdFireDIVL).requires(FeatureColdFire).optional(), // DIVx.L/REMx.L (ColdFire)
+		masked(maskFFC0, valDIVL, decodeDIVL).requires(Feature68020),                       // DIVU.L/DIVS.L/DIVUL.L/DIVSL.L
+		masked(maskFFF0, valMOVEUSP, decodeMOVEUSP),                                        // MOVE USP
+		masked(maskFFC0, valMOVEFromSR, decodeMOVEFromSR),                                  // MOVE from SR
+		masked(maskFFC0, valMOVEFromCCR, decodeMOVEFromCCR).requires(Feature68010),         // MOVE from CCR
+		masked(maskFFC0, valMOVEToCCR, decodeMOVEToCCR),                                    // MOVE to CCR
+		masked(maskFFC0, valMOVEToSR, decodeMOVEToSR),                                      // MOVE to SR
+		masked(maskFFF8, valSATS, decodeSATS).requires(FeatureColdFireISAB).optional(),     // SATS (before MOVEM)
+		masked(maskFB80, valMOVEMReg, decodeMOVEM),                                         // MOVEM Reg→Mem
+		masked(maskFB80, valMOVEMMem, decodeMOVEM),                                         // MOVEM Mem→Reg
+		masked(maskFF00, valCLR, decodeCLR),                                                // CLR
+		masked(maskFF00, valNEG, decodeNEG),                                                // NEG
+		masked(maskFF00, valNEGX, decodeNEGX),                                              // NEGX
+		masked(maskFF00, valNOT, decodeNOT),                                                // NOT
+		masked(maskFFC0, valTAS, decodeTAS),                                                // TAS
+		masked(maskFF00, valTST, decodeTST),                                                // TST
+		masked(maskFFC0, valNBCD, decodeNBCD),                                              // NBCD
+		masked(maskF1C0, valCHK, decodeCHK),                                                // CHK
+		masked(maskF1C0, valCHKL, decodeCHK).requires(Feature68020),                        // CHK.L
+		masked(maskFFC0, valJSR, decodeJSR),                                                // JSR
+		masked(maskFFC0, valJMP, decodeJMP),                                                // JMP
+		masked(maskF1C0, valLEA, decodeLEA),                                                // LEA
+		masked(maskFFF8, valPEA, decodeSWAP),                                               // SWAP
+		masked(maskFFF8, valBKPT, decodeBKPT).requires(Feature68010),                       // BKPT
+		masked(maskFFC0, valPEA, decodePEA),                                                // PEA
+	},
+	0x5: {
+		masked(maskF0F8, valDBcc, decodeDBcc),                             // DBcc/DBRA
+		masked(maskF0FF, valTRAPccW, decodeTRAPcc).requires(Feature68020), // TRAPcc.W (before Scc)
+		masked(maskF0FF, valTRAPccL, decodeTRAPcc).requires(Feature68020), // TRAPcc.L (before Scc)
+		masked(maskF0FF, valTRAPcc, decodeTRAPcc).requires(Feature68020),  // TRAPcc (before Scc)
+		masked(maskF0C0, valScc, decodeScc),                               // Scc
+		masked(maskF100, valADDQ, decodeADDQ),                             // ADDQ
+		masked(maskF100, valSUBQ, decodeSUBQ),                             // SUBQ
 	},
 	0x6: {
 		masked(maskF000, valBxx, decodeBxx), // BRA/BSR/Bcc
 	},
 	0x7: {
-		masked(maskF100, valMOVEQ, decodeMOVEQ), // MOVEQ
+		masked(maskF100, valMVS, decodeMVSMVZ).requires(FeatureColdFireISAB).optional(), // MVS/MVZ
+		masked(maskF100, valMOVEQ, decodeMOVEQ),                                         // MOVEQ
 	},
 	0x8: {
-		masked(maskF1F0, valSBCD, decodeSBCD), // SBCD
-		masked(maskF1C0, valDIVU, decodeDIVU), // DIVU
-		masked(maskF1C0, valDIVS, decodeDIVS), // DIVS
-		masked(maskF000, valOR, decodeOR),     // OR
+		masked(maskF1F0, valSBCD, decodeSBCD),                            // SBCD
+		masked(maskF1F0, valPACK, decodePACK).requires(Feature68020Full), // PACK
+		masked(maskF1F0, valUNPK, decodeUNPK).requires(Feature68020Full), // UNPK
+		masked(maskF1C0, valDIVU, decodeDIVU),                            // DIVU
+		masked(maskF1C0, valDIVS, decodeDIVS),                            // DIVS
+		masked(maskF000, valOR, decodeOR),                                // OR
 	},
 	0x9: {
-		masked(maskF000, valSUB, decodeSUB), // SUB
+		masked(maskF1F0, valSUBXB, decodeSUBX), // SUBX.B
+		masked(maskF1F0, valSUBXW, decodeSUBX), // SUBX.W
+		masked(maskF1F0, valSUBXL, decodeSUBX), // SUBX.L
+		masked(maskF000, valSUB, decodeSUB),    // SUB/SUBA
+	},
+	0xA: {
+		masked(maskF1C0, valMOV3Q, decodeMOV3Q).requires(FeatureColdFireISAB).optional(), // MOV3Q
+		masked(maskF100, valMAC, decodeMAC).requires(FeatureMAC).optional(),              // MAC/MSAC (register and with load)
+		masked(maskF1C0, valMACToReg, decodeMACMove).requires(FeatureMAC).optional(),     // MOVE.L <ea>,ACC/MACSR/MASK
+		masked(maskF1C0, valMACFromReg, decodeMACMove).requires(FeatureMAC).optional(),   // MOVE.L ACC/MACSR/MASK,Rx
+		masked(maskF1C0, valMOVCLR, decodeMACMove).requires(FeatureMAC).optional(),       // MOVCLR.L, MOVE.L MACSR,CCR
+		masked(maskF000, valLINEA, decodeLINEA),                                          // Line-A trap
 	},
 	0xB: {
-		masked(maskF000, valCMP, decodeCMP), // CMP/CMPA/CMPM/EOR
+		masked(maskF1F8, valCMPMB, decodeCMPM), // CMPM.B
+		masked(maskF1F8, valCMPMW, decodeCMPM), // CMPM.W
+		masked(maskF1F8, valCMPML, decodeCMPM), // CMPM.L
+		masked(maskF0C0, valCMPA, decodeCMPA),  // CMPA.W/CMPA.L
+		masked(maskF100, valEOR, decodeEOR),    // EOR
+		masked(maskF000, valCMP, decodeCMP),    // CMP
 	},
 	0xC: {
-		masked(maskF1F0, valABCD, decodeABCD), // ABCD
-		masked(maskF1C0, valMULU, decodeMULU), // MULU
-		masked(maskF1C0, valMULS, decodeMULS), // MULS
-		masked(maskF000, valAND, decodeAND),   // AND
+		masked(maskF1F0, valABCD, decodeABCD),      // ABCD
+		masked(maskF1F8, valEXGData, decodeEXG),    // EXG Dx, Dy
+		masked(maskF1F8, valEXGAddr, decodeEXG),    // EXG Ax, Ay
+		masked(maskF1F8, valEXGDataAdr, decodeEXG), // EXG Dx, Ay
+		masked(maskF1C0, valMULU, decodeMULU),      // MULU
+		masked(maskF1C0, valMULS, decodeMULS),      // MULS
+		masked(maskF000, valAND, decodeAND),        // AND
 	},
 	0xD: {
-		masked(maskF000, valADD, decodeADD), // ADD
+		masked(maskF1F0, valADDXB, decodeADDX), // ADDX.B
+		masked(maskF1F0, valADDXW, decodeADDX), // ADDX.W
+		masked(maskF1F0, valADDXL, decodeADDX), // ADDX.L
+		masked(maskF000, valADD, decodeADD),    // ADD/ADDA
 	},
 	0xE: {
-		masked(maskF000, valSHIFT, decodeShiftRotate), // All ASL/ASR/LSL/LSR/ROL/ROR/ROXL/ROXR
+		masked(maskF8C0, valBitfield, decodeBitfield).requires(Feature68020Full), // BFTST..BFINS (before shifts)
+		masked(maskF000, valSHIFT, decodeShiftRotate),                            // All ASL/ASR/LSL/LSR/ROL/ROR/ROXL/ROXR
+	},
+	0xF: {
+		masked(maskFFC0, valPMMU, decodePMMU).requires(FeaturePMMU).optional(),             // PMOVE, PLOAD, PFLUSH, PTEST
+		masked(maskFFC0, valFPUGeneral, decodeFPUGeneral).requires(FeatureFPU).optional(),  // FPU arithmetic, FMOVE, FMOVEM
+		masked(maskFFF8, valFDBcc, decodeFDBcc).requires(FeatureFPU).optional(),            // FDBcc (before FScc)
+		exact(valFTRAPccW, decodeFTRAPcc).requires(FeatureFPU).optional(),                  // FTRAPcc.W (before FScc)
+		exact(valFTRAPccL, decodeFTRAPcc).requires(FeatureFPU).optional(),                  // FTRAPcc.L (before FScc)
+		exact(valFTRAPcc, decodeFTRAPcc).requires(FeatureFPU).optional(),                   // FTRAPcc (before FScc)
+		masked(maskFFC0, valFScc, decodeFScc).requires(FeatureFPU).optional(),              // FScc
+		masked(maskFFC0, valFBccW, decodeFBcc).requires(FeatureFPU).optional(),             // FBcc.W/FNOP
+		masked(maskFFC0, valFBccL, decodeFBcc).requires(FeatureFPU).optional(),             // FBcc.L
+		masked(maskFFC0, valFSAVE, decodeFSAVE).requires(FeatureFPU).optional(),            // FSAVE
+		masked(maskFFC0, valFRESTORE, decodeFRESTORE).requires(FeatureFPU).optional(),      // FRESTORE
+		masked(maskFF00, valCacheOp, decodeCacheOp).requires(Feature68040).optional(),      // CINV/CPUSH
+		masked(maskFFE0, valPFLUSH040, decodePFLUSH040).requires(Feature68040).optional(),  // PFLUSH/PFLUSHN/PFLUSHA/PFLUSHAN (68040)
+		masked(maskFFD8, valPTEST040, decodePTEST040).requires(FeaturePTEST040).optional(), // PTESTR/PTESTW (68040)
+		masked(maskFFB8, valPLPA, decodePLPA).requires(Feature68060).optional(),            // PLPAR/PLPAW (68060)
+		masked(maskFFF8, valMOVE16PostI, decodeMOVE16).requires(Feature68040).optional(),   // MOVE16 (Ax)+, (Ay)+
+		masked(maskFFE0, valMOVE16Abs, decodeMOVE16).requires(Feature68040).optional(),     // MOVE16 absolute forms
+		masked(maskFFC0, valCPU32, decodeCPU32).requires(FeatureCPU32).optional(),          // TBLS/TBLU/TBLSN/TBLUN, LPSTOP
+		masked(maskF000, valLINEF, decodeLINEF),                                            // Line-F trap
 	},
 }
 
@@ -310,8 +633,15 @@ func populateMetadata(inst *Instruction, mnemonic string, operands []Operand) {
 		SizeSuffix:   suffix,
 		Operands:     cloneOperands(operands),
 	}
-
-	for _, operand := range operands {
+	inst.Metadata.Reads, inst.Metadata.Writes = registerEffects(base, suffix, operands)
+	inst.Metadata.ConditionCodes = conditionCodes(base, suffix, operands)
+
+	for i, operand := range operands {
+		inst.Requires |= operand.requires
+		inst.Metadata.NonCanonical = inst.Metadata.NonCanonical || operand.nonCanonical
+		if target, ok := pcRelativeTarget(inst.Address, operand); ok {
+			inst.Metadata.Operands[i].EffectiveAddress.ResolvedAddress = &target
+		}
 		if operand.BranchTarget != nil && inst.Metadata.BranchTarget == nil {
 			target := *operand.BranchTarget
 			inst.Metadata.BranchTarget = &target
@@ -325,6 +655,24 @@ func populateMetadata(inst *Instruction, mnemonic string, operands []Operand) {
 	}
 }
 
+// pcRelativeTarget computes the address a (d16,PC) or (d8,PC,Xn) operand
+// refers to: the address of its extension word plus the base displacement.
+// Memory-indirect forms and a suppressed PC have no static target.
+func pcRelativeTarget(address uint32, operand Operand) (uint32, bool) {
+	ea := operand.EffectiveAddress
+	if ea == nil || (ea.Kind != EAKindPCDisplacement && ea.Kind != EAKindPCIndex) {
+		return 0, false
+	}
+	if ea.Base == nil || ea.Indirection != IndirectionNone {
+		return 0, false
+	}
+	target := address + uint32(operand.pcOffset)
+	if ea.Displacement != nil {
+		target += uint32(*ea.Displacement)
+	}
+	return target, true
+}
+
 func cloneOperands(src []Operand) []Operand {
 	if len(src) == 0 {
 		return nil
@@ -343,8 +691,7 @@ func cloneOperand(operand Operand) Operand {
 		cloned.Register = &reg
 	}
 	if operand.Immediate != nil {
-		imm := *operand.Immediate
-		cloned.Immediate = &imm
+		cloned.Immediate = cloneImmediate(operand.Immediate)
 	}
 	if operand.EffectiveAddress != nil {
 		ea := *operand.EffectiveAddress
@@ -365,13 +712,16 @@ func cloneOperand(operand Operand) Operand {
 			ea.ResolvedAddress = &addr
 		}
 		if operand.EffectiveAddress.Immediate != nil {
-			imm := *operand.EffectiveAddress.Immediate
-			ea.Immediate = &imm
+			ea.Immediate = cloneImmediate(operand.EffectiveAddress.Immediate)
 		}
 		if operand.EffectiveAddress.Index != nil {
 			idx := *operand.EffectiveAddress.Index
 			ea.Index = &idx
 		}
+		if operand.EffectiveAddress.OuterDisplacement != nil {
+			disp := *operand.EffectiveAddress.OuterDisplacement
+			ea.OuterDisplacement = &disp
+		}
 		cloned.EffectiveAddress = &ea
 	}
 	if operand.RegisterList != nil {
@@ -381,5 +731,30 @@ func cloneOperand(operand Operand) Operand {
 		target := *operand.BranchTarget
 		cloned.BranchTarget = &target
 	}
+	if operand.Bitfield != nil {
+		bf := *operand.Bitfield
+		if operand.Bitfield.OffsetRegister != nil {
+			reg := *operand.Bitfield.OffsetRegister
+			bf.OffsetRegister = &reg
+		}
+		if operand.Bitfield.WidthRegister != nil {
+			reg := *operand.Bitfield.WidthRegister
+			bf.WidthRegister = &reg
+		}
+		cloned.Bitfield = &bf
+	}
+	if operand.RegisterPair != nil {
+		pair := *operand.RegisterPair
+		cloned.RegisterPair = &pair
+	}
 	return cloned
 }
+
+func cloneImmediate(imm *ImmediateValue) *ImmediateValue {
+	cloned := *imm
+	if imm.Float != nil {
+		value := *imm.Float
+		cloned.Float = &value
+	}
+	return &cloned
+}
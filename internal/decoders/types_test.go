@@ -1,6 +1,7 @@
 package decoders
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -16,6 +17,36 @@ func TestFindDecoderMatchesOpcodeTable(t *testing.T) {
 	}
 }
 
+// TestEveryOpcodeHasAddressingRule decodes every opcode with extension words
+// covering bits 15-13, the FPU class and direction, and bit 11, which picks
+// CHK2 over CMP2 and the signed MULx.L, DIVx.L and REMx forms, so that every
+// rule key the decoders build at run time is looked up.
+func TestEveryOpcodeHasAddressingRule(t *testing.T) {
+	data := make([]byte, 16)
+	for opcode := 0; opcode <= 0xFFFF; opcode++ {
+		op := uint16(opcode)
+		decoder := FindDecoder(op)
+		if decoder == nil {
+			continue
+		}
+		data[0], data[1] = byte(op>>8), byte(op)
+		for _, selector := range []byte{0x00, 0x08, 0x20, 0x40, 0x60, 0x80, 0xA0, 0xC0, 0xE0} {
+			data[2] = selector
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("decoder for opcode %04X %02X00 panicked: %v", op, data[2], r)
+					}
+				}()
+				err := decoder(data, op, &Instruction{Opcode: op, Size: 2, Bytes: data[:2]})
+				if errors.Is(err, errNoAddressingRule) {
+					t.Fatalf("opcode %04X %02X00: %v", op, data[2], err)
+				}
+			}()
+		}
+	}
+}
+
 func findDecoderLinear(opcode uint16) OpcodeDecoder {
 	for _, pattern := range OpcodeTable {
 		if (opcode & pattern.Mask) == pattern.Value {
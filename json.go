@@ -0,0 +1,573 @@
+package m68kdasm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// JSONSchemaVersion is the version of the JSON schema of Instruction. It is
+// written as "schema_version" with every instruction and only changes when
+// fields are renamed or removed or their meaning changes; new optional fields
+// keep the version.
+//
+// An instruction is an object with these fields:
+//
+//	schema_version   number, JSONSchemaVersion
+//	address          hex string "0x00001000"
+//	opcode           hex string "0x4E75"
+//	size             number of bytes
+//	bytes            hex string "4e75" of the raw bytes
+//	extension_words  array of hex strings "0x1234", omitted if empty
+//	mnemonic         string as rendered, e.g. "MOVE.L" or "movel"
+//	operands         string as rendered, omitted if empty
+//	assembly         mnemonic and operands, ignored when decoding
+//	comment          string, omitted if empty
+//	metadata         DecodeMetadata
+//
+// DecodeMetadata has mnemonic, mnemonic_base, size_suffix, operands,
+// branch_target, immediate_values, non_canonical, unavailable_reason, reads,
+// writes and condition_codes. condition_codes has the FlagEffect of x, n,
+// z, v and c, omitted when unchanged, and uses as XNZVC letters such as
+// "NZV"; it is omitted when the instruction neither changes nor uses them.
+// Operand, Register, ImmediateValue, EffectiveAddress, IndexRegister,
+// Bitfield and RegisterPair use the snake_case of their field names. Kinds
+// are the string values of the Kind constants. Addresses (branch_target,
+// absolute_address, resolved_address) are hex strings; displacements and
+// immediate values are numbers. A float immediate is a number, or the string
+// "NaN", "+Inf" or "-Inf". Pointer fields that are nil and empty values are
+// omitted.
+const JSONSchemaVersion = 1
+
+// MarshalJSON encodes the instruction in the JSONSchemaVersion schema.
+func (i Instruction) MarshalJSON() ([]byte, error) {
+	words := make([]jsonWord, len(i.ExtensionWords))
+	for n, word := range i.ExtensionWords {
+		words[n] = jsonWord(word)
+	}
+	return json.Marshal(jsonInstruction{
+		SchemaVersion:  JSONSchemaVersion,
+		Address:        jsonAddress(i.Address),
+		Opcode:         jsonWord(i.Opcode),
+		Size:           i.Size,
+		Bytes:          hex.EncodeToString(i.Bytes),
+		ExtensionWords: words,
+		Mnemonic:       i.Mnemonic,
+		Operands:       i.Operands,
+		Assembly:       i.Assembly(),
+		Comment:        i.Comment,
+		Metadata:       i.Metadata,
+	})
+}
+
+// UnmarshalJSON rebuilds an instruction encoded by MarshalJSON. It rejects
+// other schema versions.
+func (i *Instruction) UnmarshalJSON(data []byte) error {
+	var wire jsonInstruction
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	if wire.SchemaVersion != JSONSchemaVersion {
+		return fmt.Errorf("unsupported instruction schema version %d (want %d)", wire.SchemaVersion, JSONSchemaVersion)
+	}
+	raw, err := hex.DecodeString(wire.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid instruction bytes %q: %w", wire.Bytes, err)
+	}
+	var words []uint16
+	for _, word := range wire.ExtensionWords {
+		words = append(words, uint16(word))
+	}
+	*i = Instruction{
+		Address:        uint32(wire.Address),
+		Opcode:         uint16(wire.Opcode),
+		Mnemonic:       wire.Mnemonic,
+		Operands:       wire.Operands,
+		Size:           wire.Size,
+		Bytes:          raw,
+		ExtensionWords: words,
+		Metadata:       wire.Metadata,
+		Comment:        wire.Comment,
+	}
+	return nil
+}
+
+// MarshalJSON encodes the metadata as the "metadata" object of the
+// instruction schema.
+func (m DecodeMetadata) MarshalJSON() ([]byte, error) {
+	wire := jsonMetadata{
+		Mnemonic:          m.Mnemonic,
+		MnemonicBase:      m.MnemonicBase,
+		SizeSuffix:        m.SizeSuffix,
+		BranchTarget:      addressPtr(m.BranchTarget),
+		ImmediateValues:   make([]jsonImmediate, len(m.ImmediateValues)),
+		NonCanonical:      m.NonCanonical,
+		UnavailableReason: m.UnavailableReason,
+		Reads:             toJSONRegisters(m.Reads),
+		Writes:            toJSONRegisters(m.Writes),
+	}
+	if m.ConditionCodes != (ConditionCodes{}) {
+		cc := m.ConditionCodes
+		wire.ConditionCodes = &jsonConditionCodes{X: cc.X, N: cc.N, Z: cc.Z, V: cc.V, C: cc.C, Uses: cc.Uses.String()}
+	}
+	for n, imm := range m.ImmediateValues {
+		wire.ImmediateValues[n] = toJSONImmediate(imm)
+	}
+	for _, operand := range m.Operands {
+		wire.Operands = append(wire.Operands, toJSONOperand(operand))
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON decodes the "metadata" object of the instruction schema.
+func (m *DecodeMetadata) UnmarshalJSON(data []byte) error {
+	var wire jsonMetadata
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*m = DecodeMetadata{
+		Mnemonic:          wire.Mnemonic,
+		MnemonicBase:      wire.MnemonicBase,
+		SizeSuffix:        wire.SizeSuffix,
+		BranchTarget:      fromAddressPtr(wire.BranchTarget),
+		NonCanonical:      wire.NonCanonical,
+		UnavailableReason: wire.UnavailableReason,
+		Operands:          make([]Operand, len(wire.Operands)),
+		ImmediateValues:   make([]ImmediateValue, len(wire.ImmediateValues)),
+		Reads:             fromJSONRegisters(wire.Reads),
+		Writes:            fromJSONRegisters(wire.Writes),
+	}
+	if cc := wire.ConditionCodes; cc != nil {
+		uses, err := parseFlags(cc.Uses)
+		if err != nil {
+			return err
+		}
+		m.ConditionCodes = ConditionCodes{X: cc.X, N: cc.N, Z: cc.Z, V: cc.V, C: cc.C, Uses: uses}
+	}
+	for n, operand := range wire.Operands {
+		m.Operands[n] = fromJSONOperand(operand)
+	}
+	for n, imm := range wire.ImmediateValues {
+		m.ImmediateValues[n] = fromJSONImmediate(imm)
+	}
+	return nil
+}
+
+// JSONLinesEncoder writes instructions as JSON Lines, one object per line.
+type JSONLinesEncoder struct {
+	enc *json.Encoder
+}
+
+// NewJSONLinesEncoder returns an encoder writing to w.
+func NewJSONLinesEncoder(w io.Writer) *JSONLinesEncoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONLinesEncoder{enc: enc}
+}
+
+// Encode writes one instruction and a newline.
+func (e *JSONLinesEncoder) Encode(inst Instruction) error {
+	return e.enc.Encode(inst)
+}
+
+// WriteJSONLines decodes data, loaded at start, and writes each instruction
+// as soon as it is decoded, so large ranges are never held in memory as a
+// whole. Decoding stops at the first error, after the instructions before it
+// have been written.
+func WriteJSONLines(w io.Writer, data []byte, start uint32, opts DecodeOptions) error {
+	enc := NewJSONLinesEncoder(w)
+	for offset := 0; offset < len(data); {
+		inst, err := DecodeWithOptions(data[offset:], start+uint32(offset), opts)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(*inst); err != nil {
+			return err
+		}
+		offset += int(inst.Size)
+	}
+	return nil
+}
+
+// JSONLinesDecoder reads instructions written by JSONLinesEncoder.
+type JSONLinesDecoder struct {
+	dec *json.Decoder
+}
+
+// NewJSONLinesDecoder returns a decoder reading from r.
+func NewJSONLinesDecoder(r io.Reader) *JSONLinesDecoder {
+	return &JSONLinesDecoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next instruction. It returns io.EOF after the last one.
+func (d *JSONLinesDecoder) Decode() (Instruction, error) {
+	var inst Instruction
+	err := d.dec.Decode(&inst)
+	return inst, err
+}
+
+type jsonInstruction struct {
+	SchemaVersion  int            `json:"schema_version"`
+	Address        jsonAddress    `json:"address"`
+	Opcode         jsonWord       `json:"opcode"`
+	Size           uint32         `json:"size"`
+	Bytes          string         `json:"bytes"`
+	ExtensionWords []jsonWord     `json:"extension_words,omitempty"`
+	Mnemonic       string         `json:"mnemonic"`
+	Operands       string         `json:"operands,omitempty"`
+	Assembly       string         `json:"assembly,omitempty"`
+	Comment        string         `json:"comment,omitempty"`
+	Metadata       DecodeMetadata `json:"metadata"`
+}
+
+type jsonMetadata struct {
+	Mnemonic          string              `json:"mnemonic"`
+	MnemonicBase      string              `json:"mnemonic_base"`
+	SizeSuffix        string              `json:"size_suffix,omitempty"`
+	Operands          []jsonOperand       `json:"operands,omitempty"`
+	BranchTarget      *jsonAddress        `json:"branch_target,omitempty"`
+	ImmediateValues   []jsonImmediate     `json:"immediate_values,omitempty"`
+	NonCanonical      bool                `json:"non_canonical,omitempty"`
+	UnavailableReason string              `json:"unavailable_reason,omitempty"`
+	Reads             []jsonRegister      `json:"reads,omitempty"`
+	Writes            []jsonRegister      `json:"writes,omitempty"`
+	ConditionCodes    *jsonConditionCodes `json:"condition_codes,omitempty"`
+}
+
+type jsonConditionCodes struct {
+	X    FlagEffect `json:"x,omitempty"`
+	N    FlagEffect `json:"n,omitempty"`
+	Z    FlagEffect `json:"z,omitempty"`
+	V    FlagEffect `json:"v,omitempty"`
+	C    FlagEffect `json:"c,omitempty"`
+	Uses string     `json:"uses,omitempty"`
+}
+
+type jsonOperand struct {
+	Text             string                `json:"text"`
+	Kind             OperandKind           `json:"kind"`
+	Register         *jsonRegister         `json:"register,omitempty"`
+	Immediate        *jsonImmediate        `json:"immediate,omitempty"`
+	EffectiveAddress *jsonEffectiveAddress `json:"effective_address,omitempty"`
+	RegisterList     []string              `json:"register_list,omitempty"`
+	BranchTarget     *jsonAddress          `json:"branch_target,omitempty"`
+	Bitfield         *jsonBitfield         `json:"bitfield,omitempty"`
+	RegisterPair     *jsonRegisterPair     `json:"register_pair,omitempty"`
+	Suffix           string                `json:"suffix,omitempty"`
+}
+
+type jsonRegister struct {
+	Kind   RegisterKind `json:"kind"`
+	Number uint8        `json:"number"`
+	Name   string       `json:"name,omitempty"`
+}
+
+type jsonImmediate struct {
+	Value        uint32     `json:"value"`
+	Signed       int32      `json:"signed"`
+	Size         uint8      `json:"size"`
+	Float        *jsonFloat `json:"float,omitempty"`
+	SignExtended bool       `json:"sign_extended,omitempty"`
+}
+
+type jsonEffectiveAddress struct {
+	Kind              EffectiveAddressKind `json:"kind"`
+	Mode              uint8                `json:"mode"`
+	Register          uint8                `json:"register"`
+	Base              *jsonRegister        `json:"base,omitempty"`
+	Displacement      *int32               `json:"displacement,omitempty"`
+	AbsoluteAddress   *jsonAddress         `json:"absolute_address,omitempty"`
+	ResolvedAddress   *jsonAddress         `json:"resolved_address,omitempty"`
+	Immediate         *jsonImmediate       `json:"immediate,omitempty"`
+	Index             *jsonIndexRegister   `json:"index,omitempty"`
+	Scale             uint8                `json:"scale,omitempty"`
+	OuterDisplacement *int32               `json:"outer_displacement,omitempty"`
+	Indirection       MemoryIndirection    `json:"indirection,omitempty"`
+}
+
+type jsonIndexRegister struct {
+	Register jsonRegister `json:"register"`
+	Size     string       `json:"size"`
+}
+
+type jsonBitfield struct {
+	Offset         uint8         `json:"offset"`
+	OffsetRegister *jsonRegister `json:"offset_register,omitempty"`
+	Width          uint8         `json:"width"`
+	WidthRegister  *jsonRegister `json:"width_register,omitempty"`
+}
+
+type jsonRegisterPair struct {
+	First    jsonRegister `json:"first"`
+	Second   jsonRegister `json:"second"`
+	Indirect bool         `json:"indirect,omitempty"`
+}
+
+func toJSONOperand(operand Operand) jsonOperand {
+	wire := jsonOperand{
+		Text:         operand.Text,
+		Kind:         operand.Kind,
+		Register:     registerPtr(operand.Register),
+		RegisterList: operand.RegisterList,
+		BranchTarget: addressPtr(operand.BranchTarget),
+		Suffix:       operand.Suffix,
+	}
+	if operand.Immediate != nil {
+		imm := toJSONImmediate(*operand.Immediate)
+		wire.Immediate = &imm
+	}
+	if ea := operand.EffectiveAddress; ea != nil {
+		wire.EffectiveAddress = &jsonEffectiveAddress{
+			Kind:              ea.Kind,
+			Mode:              ea.Mode,
+			Register:          ea.Register,
+			Base:              registerPtr(ea.Base),
+			Displacement:      ea.Displacement,
+			AbsoluteAddress:   addressPtr(ea.AbsoluteAddress),
+			ResolvedAddress:   addressPtr(ea.ResolvedAddress),
+			Scale:             ea.Scale,
+			OuterDisplacement: ea.OuterDisplacement,
+			Indirection:       ea.Indirection,
+		}
+		if ea.Immediate != nil {
+			imm := toJSONImmediate(*ea.Immediate)
+			wire.EffectiveAddress.Immediate = &imm
+		}
+		if ea.Index != nil {
+			wire.EffectiveAddress.Index = &jsonIndexRegister{Register: jsonRegister(ea.Index.Register), Size: ea.Index.Size}
+		}
+	}
+	if field := operand.Bitfield; field != nil {
+		wire.Bitfield = &jsonBitfield{
+			Offset:         field.Offset,
+			OffsetRegister: registerPtr(field.OffsetRegister),
+			Width:          field.Width,
+			WidthRegister:  registerPtr(field.WidthRegister),
+		}
+	}
+	if pair := operand.RegisterPair; pair != nil {
+		wire.RegisterPair = &jsonRegisterPair{First: jsonRegister(pair.First), Second: jsonRegister(pair.Second), Indirect: pair.Indirect}
+	}
+	return wire
+}
+
+func fromJSONOperand(wire jsonOperand) Operand {
+	operand := Operand{
+		Text:         wire.Text,
+		Kind:         wire.Kind,
+		Register:     fromRegisterPtr(wire.Register),
+		RegisterList: wire.RegisterList,
+		BranchTarget: fromAddressPtr(wire.BranchTarget),
+		Suffix:       wire.Suffix,
+	}
+	if wire.Immediate != nil {
+		imm := fromJSONImmediate(*wire.Immediate)
+		operand.Immediate = &imm
+	}
+	if ea := wire.EffectiveAddress; ea != nil {
+		operand.EffectiveAddress = &EffectiveAddress{
+			Kind:              ea.Kind,
+			Mode:              ea.Mode,
+			Register:          ea.Register,
+			Base:              fromRegisterPtr(ea.Base),
+			Displacement:      ea.Displacement,
+			AbsoluteAddress:   fromAddressPtr(ea.AbsoluteAddress),
+			ResolvedAddress:   fromAddressPtr(ea.ResolvedAddress),
+			Scale:             ea.Scale,
+			OuterDisplacement: ea.OuterDisplacement,
+			Indirection:       ea.Indirection,
+		}
+		if ea.Immediate != nil {
+			imm := fromJSONImmediate(*ea.Immediate)
+			operand.EffectiveAddress.Immediate = &imm
+		}
+		if ea.Index != nil {
+			operand.EffectiveAddress.Index = &IndexRegister{Register: Register(ea.Index.Register), Size: ea.Index.Size}
+		}
+	}
+	if field := wire.Bitfield; field != nil {
+		operand.Bitfield = &Bitfield{
+			Offset:         field.Offset,
+			OffsetRegister: fromRegisterPtr(field.OffsetRegister),
+			Width:          field.Width,
+			WidthRegister:  fromRegisterPtr(field.WidthRegister),
+		}
+	}
+	if pair := wire.RegisterPair; pair != nil {
+		operand.RegisterPair = &RegisterPair{First: Register(pair.First), Second: Register(pair.Second), Indirect: pair.Indirect}
+	}
+	return operand
+}
+
+func toJSONImmediate(imm ImmediateValue) jsonImmediate {
+	return jsonImmediate{
+		Value:        imm.Value,
+		Signed:       imm.Signed,
+		Size:         imm.Size,
+		Float:        (*jsonFloat)(imm.Float),
+		SignExtended: imm.SignExtended,
+	}
+}
+
+func fromJSONImmediate(wire jsonImmediate) ImmediateValue {
+	return ImmediateValue{
+		Value:        wire.Value,
+		Signed:       wire.Signed,
+		Size:         wire.Size,
+		Float:        (*float64)(wire.Float),
+		SignExtended: wire.SignExtended,
+	}
+}
+
+func registerPtr(reg *Register) *jsonRegister {
+	if reg == nil {
+		return nil
+	}
+	wire := jsonRegister(*reg)
+	return &wire
+}
+
+func fromRegisterPtr(wire *jsonRegister) *Register {
+	if wire == nil {
+		return nil
+	}
+	reg := Register(*wire)
+	return &reg
+}
+
+// parseFlags reads the XNZVC letters written by Flags.String.
+func parseFlags(text string) (Flags, error) {
+	var flags Flags
+	for _, letter := range text {
+		i := strings.IndexRune("XNZVC", letter)
+		if i < 0 {
+			return 0, fmt.Errorf("invalid condition code %q in %q", letter, text)
+		}
+		flags |= FlagX >> uint(i)
+	}
+	return flags, nil
+}
+
+func toJSONRegisters(regs []Register) []jsonRegister {
+	if len(regs) == 0 {
+		return nil
+	}
+	wire := make([]jsonRegister, len(regs))
+	for n, reg := range regs {
+		wire[n] = jsonRegister(reg)
+	}
+	return wire
+}
+
+func fromJSONRegisters(wire []jsonRegister) []Register {
+	if len(wire) == 0 {
+		return nil
+	}
+	regs := make([]Register, len(wire))
+	for n, reg := range wire {
+		regs[n] = Register(reg)
+	}
+	return regs
+}
+
+func addressPtr(address *uint32) *jsonAddress {
+	if address == nil {
+		return nil
+	}
+	wire := jsonAddress(*address)
+	return &wire
+}
+
+func fromAddressPtr(wire *jsonAddress) *uint32 {
+	if wire == nil {
+		return nil
+	}
+	address := uint32(*wire)
+	return &address
+}
+
+// jsonAddress is a 32-bit address written as "0x00001000".
+type jsonAddress uint32
+
+func (a jsonAddress) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"0x%08X"`, uint32(a))), nil
+}
+
+func (a *jsonAddress) UnmarshalJSON(data []byte) error {
+	value, err := parseJSONHex(data, 32)
+	*a = jsonAddress(value)
+	return err
+}
+
+// jsonWord is a 16-bit opcode or extension word written as "0x4E75".
+type jsonWord uint16
+
+func (w jsonWord) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"0x%04X"`, uint16(w))), nil
+}
+
+func (w *jsonWord) UnmarshalJSON(data []byte) error {
+	value, err := parseJSONHex(data, 16)
+	*w = jsonWord(value)
+	return err
+}
+
+func parseJSONHex(data []byte, bits int) (uint64, error) {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return 0, err
+	}
+	digits, ok := strings.CutPrefix(strings.ToLower(text), "0x")
+	if !ok {
+		return 0, fmt.Errorf("invalid hex value %q: missing 0x prefix", text)
+	}
+	value, err := strconv.ParseUint(digits, 16, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hex value %q: %w", text, err)
+	}
+	return value, nil
+}
+
+// jsonFloat is a float immediate; JSON numbers cannot hold NaN and the
+// infinities, so they are written as strings.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	value := float64(f)
+	switch {
+	case math.IsNaN(value):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(value, 1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(value, -1):
+		return []byte(`"-Inf"`), nil
+	}
+	return json.Marshal(value)
+}
+
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		var value float64
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*f = jsonFloat(value)
+		return nil
+	}
+	switch text {
+	case "NaN":
+		*f = jsonFloat(math.NaN())
+	case "+Inf":
+		*f = jsonFloat(math.Inf(1))
+	case "-Inf":
+		*f = jsonFloat(math.Inf(-1))
+	default:
+		return fmt.Errorf("invalid float value %q", text)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package m68kdasm
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// listingBytesPerLine and listingBytesPerChunk match the raw byte column of
+// m68k-elf-objdump: up to three big-endian words per line.
+const (
+	listingBytesPerLine  = 6
+	listingBytesPerChunk = 2
+)
+
+// Listing writes instructions in the layout of m68k-elf-objdump -d:
+//
+//	00001000 <main>:
+//	    1000:	4e56 0000      	link %fp,#0
+//	    1004:	23fc 0000 0001 	movel #1,0x2000
+//	    100a:	0000 2000
+//
+// Raw bytes beyond the first six continue on lines of their own.
+type Listing struct {
+	// Symbolizer names the label lines printed before an instruction.
+	Symbolizer Symbolizer
+	// Comment returns an optional end-of-line comment, printed after "; "
+	// and after Instruction.Comment.
+	Comment func(Instruction) string
+	// AddressDigits is the width of the address column. Zero selects four
+	// digits for addresses below $1000 and eight otherwise, which is what
+	// objdump picks from the end address of a section.
+	AddressDigits int
+
+	w io.Writer
+}
+
+// NewListing returns a Listing writing to w.
+func NewListing(w io.Writer) *Listing {
+	return &Listing{w: w}
+}
+
+// WriteSection writes the header objdump prints before each section.
+func (l *Listing) WriteSection(name string) error {
+	_, err := fmt.Fprintf(l.w, "\nDisassembly of section %s:\n", name)
+	return err
+}
+
+// WriteInstruction writes the label line, if any, and the lines of one
+// instruction.
+func (l *Listing) WriteInstruction(inst Instruction) error {
+	return l.writeInstruction(inst, l.addressDigits(inst.Address))
+}
+
+// WriteInstructions writes a run of instructions with one address width for
+// the whole run.
+func (l *Listing) WriteInstructions(instructions []Instruction) error {
+	if len(instructions) == 0 {
+		return nil
+	}
+	last := instructions[len(instructions)-1]
+	digits := l.addressDigits(last.Address + last.Size)
+	for _, inst := range instructions {
+		if err := l.writeInstruction(inst, digits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSeq writes the instructions of seq as they are produced, e.g. from a
+// decoder reading a ROM. The address width follows each address.
+func (l *Listing) WriteSeq(seq iter.Seq[Instruction]) error {
+	for inst := range seq {
+		if err := l.WriteInstruction(inst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Listing) addressDigits(address uint32) int {
+	if l.AddressDigits > 0 {
+		return l.AddressDigits
+	}
+	if address > 0xFFF {
+		return 8
+	}
+	return 4
+}
+
+func (l *Listing) writeInstruction(inst Instruction, digits int) error {
+	var out strings.Builder
+	if l.Symbolizer != nil {
+		if name, ok := l.Symbolizer.Symbolize(inst.Address); ok {
+			fmt.Fprintf(&out, "\n%08x <%s>:\n", inst.Address, name)
+		}
+	}
+
+	raw := inst.Bytes
+	line := raw[:min(len(raw), listingBytesPerLine)]
+	fmt.Fprintf(&out, "%*x:\t%-*s\t%s", digits, inst.Address, listingBytesPerLine/listingBytesPerChunk*(2*listingBytesPerChunk+1), listingChunks(line), inst.Assembly())
+	comment := inst.Comment
+	if l.Comment != nil {
+		if extra := l.Comment(inst); extra != "" && comment != "" {
+			comment += ", " + extra
+		} else if extra != "" {
+			comment = extra
+		}
+	}
+	if comment != "" {
+		out.WriteString(" ; " + comment)
+	}
+	out.WriteString("\n")
+
+	for offset := len(line); offset < len(raw); offset += listingBytesPerLine {
+		line := raw[offset:min(len(raw), offset+listingBytesPerLine)]
+		fmt.Fprintf(&out, "%*x:\t%s\n", digits, inst.Address+uint32(offset), listingChunks(line))
+	}
+
+	_, err := io.WriteString(l.w, out.String())
+	return err
+}
+
+// listingChunks renders raw bytes as space-terminated lowercase words.
+func listingChunks(raw []byte) string {
+	var out strings.Builder
+	for chunk := range slices.Chunk(raw, listingBytesPerChunk) {
+		for _, b := range chunk {
+			fmt.Fprintf(&out, "%02x", b)
+		}
+		out.WriteString(" ")
+	}
+	return out.String()
+}
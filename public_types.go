@@ -1,11 +1,42 @@
 package m68kdasm
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type DecodeOptions struct {
 	Symbolizer Symbolizer
+	TrapNamer  TrapNamer
+	// CPU restricts decoding to the instruction set of one processor model.
+	// The zero value CPUAny accepts every supported 680x0 instruction.
+	CPU CPU
+	// Syntax selects the assembler dialect of Mnemonic and Operands. The
+	// zero value SyntaxMotorola keeps the Motorola syntax.
+	Syntax Syntax
+	// Formatter, when set, renders Operands instead of the Syntax dialect,
+	// which still selects the mnemonic.
+	Formatter Formatter
+	// PCRelative selects how PC-relative operands show their target. The
+	// zero value PCRelativeDisplacement keeps the raw displacement.
+	PCRelative PCRelativeStyle
 }
 
+// PCRelativeStyle selects the rendering of (d16,PC) and (d8,PC,Xn) operands,
+// whose target is EffectiveAddress.ResolvedAddress.
+type PCRelativeStyle int
+
+const (
+	// PCRelativeDisplacement renders the displacement, e.g. (14,PC).
+	PCRelativeDisplacement PCRelativeStyle = iota
+	// PCRelativeLabel renders targets the Symbolizer names as label(PC) or
+	// label(PC,D0.W); other targets keep the displacement.
+	PCRelativeLabel
+	// PCRelativeComment keeps the displacement and sets Instruction.Comment
+	// to the target, e.g. =$1010.
+	PCRelativeComment
+)
+
 type Symbolizer interface {
 	Symbolize(address uint32) (string, bool)
 }
@@ -16,6 +47,58 @@ func (f SymbolizeFunc) Symbolize(address uint32) (string, bool) {
 	return f(address)
 }
 
+// ContextSymbolizer is a Symbolizer that also names addresses inside a
+// symbol, such as buffer+$10, and can decide per operand. DecodeOptions uses
+// SymbolizeContext instead of Symbolize when the Symbolizer implements it;
+// label lines in Listing and WriteSource keep using Symbolize.
+type ContextSymbolizer interface {
+	Symbolizer
+	// SymbolizeContext names value as symbol+offset. A zero offset renders
+	// the bare name, others name+$10 or name-$4.
+	SymbolizeContext(value uint32, ctx SymbolContext) (name string, offset int32, ok bool)
+}
+
+// SymbolRole says how an operand uses the value being symbolized.
+type SymbolRole string
+
+const (
+	SymbolRoleBranchTarget SymbolRole = "branch_target"
+	SymbolRoleAbsolute     SymbolRole = "absolute"
+	// SymbolRolePCRelative is the target of a (d16,PC) or (d8,PC,Xn) operand,
+	// only asked for with PCRelativeLabel.
+	SymbolRolePCRelative SymbolRole = "pc_relative"
+	// SymbolRoleImmediate is integer immediate data, which a plain
+	// Symbolizer is never asked for.
+	SymbolRoleImmediate SymbolRole = "immediate"
+)
+
+// SymbolContext describes the operand a value comes from.
+type SymbolContext struct {
+	// Instruction is the decoded instruction; its Operands string is not
+	// rendered yet.
+	Instruction *Instruction
+	// Operand is the index into Instruction.Metadata.Operands.
+	Operand int
+	Role    SymbolRole
+	// Size is the access size in bytes: the immediate size, or the
+	// instruction's operation size for addresses. It is 0 for branch
+	// targets and unsized instructions such as LEA and JSR.
+	Size uint8
+}
+
+// TrapNamer supplies platform names for Line-A and Line-F trap opcodes such as
+// Mac toolbox calls. It receives the full opcode word, so tables can use the
+// line nibble as well as the 12-bit trap number.
+type TrapNamer interface {
+	NameTrap(opcode uint16) (string, bool)
+}
+
+type TrapNameFunc func(opcode uint16) (string, bool)
+
+func (f TrapNameFunc) NameTrap(opcode uint16) (string, bool) {
+	return f(opcode)
+}
+
 type ReadFunc func(address uint32, p []byte) (int, error)
 
 type DecodeMetadata struct {
@@ -25,6 +108,28 @@ type DecodeMetadata struct {
 	Operands        []Operand
 	BranchTarget    *uint32
 	ImmediateValues []ImmediateValue
+	// NonCanonical marks encodings an assembler would not produce from the
+	// instruction text, such as a set high byte in a .B immediate word or a
+	// 68020 full extension word where the brief format fits. WriteSource
+	// emits such instructions as data.
+	NonCanonical bool
+	// UnavailableReason is set on the DC.W emitted for an instruction the
+	// selected DecodeOptions.CPU does not implement, e.g.
+	// "not available on 68000 (requires 68020)".
+	UnavailableReason string
+	// Reads and Writes are the registers the instruction reads and writes,
+	// sorted with data registers first and free of duplicates. They include
+	// implicit registers such as A7 for BSR, JSR, RTS, PEA and LINK, the An
+	// updated by (An)+ and -(An) and every register of a MOVEM list. A .B
+	// or .W write to a data register keeps the upper bits and also counts
+	// as a read. The PC and condition codes changed as a side effect are
+	// not included.
+	Reads  []Register
+	Writes []Register
+	// ConditionCodes is how the instruction affects and uses the condition
+	// codes. Writes to SR and CCR from an immediate, such as ANDI #$FE, CCR
+	// or STOP #$2700, set or clear exactly the flags the immediate names.
+	ConditionCodes ConditionCodes
 }
 
 type OperandKind string
@@ -35,6 +140,10 @@ const (
 	OperandKindEffectiveAddr OperandKind = "effective_address"
 	OperandKindRegisterList  OperandKind = "register_list"
 	OperandKindBranchTarget  OperandKind = "branch_target"
+	OperandKindRegisterPair  OperandKind = "register_pair"
+	// OperandKindShift is the << or >> product shift of the ColdFire MAC
+	// instructions, carried in Text.
+	OperandKindShift OperandKind = "shift"
 )
 
 type RegisterKind string
@@ -43,17 +152,88 @@ const (
 	RegisterKindData    RegisterKind = "data"
 	RegisterKindAddress RegisterKind = "address"
 	RegisterKindPC      RegisterKind = "pc"
+	RegisterKindSystem  RegisterKind = "system"
+	RegisterKindControl RegisterKind = "control"
+	// RegisterKindFP is a floating-point data register FP0-FP7.
+	RegisterKindFP RegisterKind = "fp"
+	// RegisterKindFPControl is one of the FPU control registers FPCR, FPSR
+	// and FPIAR, identified by Register.Name.
+	RegisterKindFPControl RegisterKind = "fp_control"
+	// RegisterKindMMU is a PMMU register such as TC, TT0, SRP, CRP or MMUSR,
+	// identified by Register.Name.
+	RegisterKindMMU RegisterKind = "mmu"
+	// RegisterKindCache selects the caches of CINV/CPUSH: NC, DC, IC or BC.
+	RegisterKindCache RegisterKind = "cache"
+	// RegisterKindMAC is a ColdFire MAC/EMAC register: ACC0-ACC3, MACSR,
+	// MASK, ACCEXT01 or ACCEXT23, identified by Register.Name.
+	RegisterKindMAC RegisterKind = "mac"
 )
 
+// Register identifies a CPU register. Numbered registers (Dn, An) use Number;
+// named registers such as SR, CCR and USP carry their name in Name.
 type Register struct {
 	Kind   RegisterKind
 	Number uint8
+	Name   string
+}
+
+// FlagEffect is how an instruction changes one condition code.
+type FlagEffect string
+
+const (
+	FlagUnchanged FlagEffect = ""
+	// FlagResult is set or cleared according to the result.
+	FlagResult    FlagEffect = "result"
+	FlagCleared   FlagEffect = "cleared"
+	FlagSet       FlagEffect = "set"
+	FlagUndefined FlagEffect = "undefined"
+)
+
+// Flags is a set of condition codes in their CCR bit positions.
+type Flags uint8
+
+const (
+	FlagC Flags = 1 << iota
+	FlagV
+	FlagZ
+	FlagN
+	FlagX
+)
+
+// String lists the flags in XNZVC order, e.g. "NZV" for GT.
+func (f Flags) String() string {
+	var b strings.Builder
+	for i, name := range "XNZVC" {
+		if f&(FlagX>>uint(i)) != 0 {
+			b.WriteRune(name)
+		}
+	}
+	return b.String()
+}
+
+// ConditionCodes describes how an instruction affects X, N, Z, V and C, as
+// listed in the condition code tables of the Programmer's Reference Manual.
+// FPU instructions only change the FPSR and leave them unchanged.
+type ConditionCodes struct {
+	X, N, Z, V, C FlagEffect
+	// Uses holds the flags the instruction reads: the flags its condition
+	// tests (Bcc, DBcc, Scc, TRAPcc, TRAPV), X for ADDX, SUBX, NEGX, ROXL,
+	// ROXR and the BCD instructions, Z for those that only clear it, and all
+	// flags for MOVE from SR or CCR.
+	Uses Flags
 }
 
+// ImmediateValue is an immediate operand. For FPU immediates (.S, .D, .X and
+// .P data) Float holds the decoded value and Value the first 32 bits of the
+// raw encoding.
 type ImmediateValue struct {
 	Value  uint32
 	Signed int32
 	Size   uint8
+	Float  *float64
+	// SignExtended marks data the instruction sign-extends, such as the
+	// MOVEQ data or the LINK displacement; Signed is its value.
+	SignExtended bool
 }
 
 type EffectiveAddressKind string
@@ -78,16 +258,52 @@ type IndexRegister struct {
 	Size     string
 }
 
+// MemoryIndirection describes the 68020 memory-indirect forms of the indexed
+// addressing modes.
+type MemoryIndirection string
+
+const (
+	IndirectionNone        MemoryIndirection = ""
+	IndirectionPreIndexed  MemoryIndirection = "pre_indexed"
+	IndirectionPostIndexed MemoryIndirection = "post_indexed"
+)
+
+// EffectiveAddress describes a decoded addressing mode. For the indexed modes a
+// nil Base or Index means the register is suppressed (68020 full format),
+// Displacement is the base displacement and OuterDisplacement the displacement
+// added after a memory-indirect fetch. Scale is 1, 2, 4 or 8 when an index
+// register is present.
 type EffectiveAddress struct {
-	Kind            EffectiveAddressKind
-	Mode            uint8
-	Register        uint8
-	Base            *Register
-	Displacement    *int32
-	AbsoluteAddress *uint32
-	ResolvedAddress *uint32
-	Immediate       *ImmediateValue
-	Index           *IndexRegister
+	Kind              EffectiveAddressKind
+	Mode              uint8
+	Register          uint8
+	Base              *Register
+	Displacement      *int32
+	AbsoluteAddress   *uint32
+	ResolvedAddress   *uint32
+	Immediate         *ImmediateValue
+	Index             *IndexRegister
+	Scale             uint8
+	OuterDisplacement *int32
+	Indirection       MemoryIndirection
+}
+
+// Bitfield holds the {offset:width} specifier of the 68020 bit-field
+// instructions. OffsetRegister/WidthRegister are set when the value comes from
+// a data register; otherwise Offset (0-31) and Width (1-32) are immediates.
+type Bitfield struct {
+	Offset         uint8
+	OffsetRegister *Register
+	Width          uint8
+	WidthRegister  *Register
+}
+
+// RegisterPair is a colon-separated register pair such as Dh:Dl for MULU.L or
+// (Rn1):(Rn2) for CAS2, where Indirect marks the memory-indirect form.
+type RegisterPair struct {
+	First    Register
+	Second   Register
+	Indirect bool
 }
 
 type Operand struct {
@@ -98,6 +314,33 @@ type Operand struct {
 	EffectiveAddress *EffectiveAddress
 	RegisterList     []string
 	BranchTarget     *uint32
+	Bitfield         *Bitfield
+	RegisterPair     *RegisterPair
+	// Suffix is syntax attached to the operand and included in Text: the
+	// MAC .U/.L register half or & mask flag, or the FMOVE.P k-factor such
+	// as {#-2} or {D1}.
+	Suffix string
+}
+
+// IllegalEncodingError reports an opcode that selects an effective address the
+// instruction does not accept, such as LEA D0, A1 or CLR.W #5. Code/data
+// heuristics can use it to reject byte sequences that are not real code.
+type IllegalEncodingError struct {
+	Address  uint32
+	Opcode   uint16
+	Mnemonic string
+	Operand  string
+	Mode     uint8
+	Register uint8
+	Kind     EffectiveAddressKind
+}
+
+func (e *IllegalEncodingError) Error() string {
+	kind := string(e.Kind)
+	if kind == "" {
+		kind = "unassigned"
+	}
+	return fmt.Sprintf("illegal %s addressing mode %s (%d/%d) for %s at address %08X (opcode %04X)", e.Operand, kind, e.Mode, e.Register, e.Mnemonic, e.Address, e.Opcode)
 }
 
 type PartialDecodeError struct {
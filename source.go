@@ -0,0 +1,345 @@
+package m68kdasm
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// AddressRange is the half-open address range [Start, End).
+type AddressRange struct {
+	Start uint32
+	End   uint32
+}
+
+func (r AddressRange) contains(address uint32) bool {
+	return address >= r.Start && address < r.End
+}
+
+// SourceOptions configures WriteSource.
+type SourceOptions struct {
+	// CPU selects the instruction set like DecodeOptions.CPU.
+	CPU CPU
+	// Data lists ranges emitted as DC data instead of being decoded, such as
+	// tables and strings inside a ROM.
+	Data []AddressRange
+	// Symbolizer names labels and external addresses. Targets inside the
+	// image it does not name get labels such as L00FC0030; named targets
+	// outside the image are defined with EQU.
+	Symbolizer Symbolizer
+}
+
+// sourceItem is an instruction or a run of data bytes in the emitted source.
+type sourceItem struct {
+	address uint32
+	size    uint32
+	inst    *Instruction // nil for data
+	long    bool         // data from SourceOptions.Data, emitted as DC.L where possible
+}
+
+// WriteSource writes data, loaded at start, as Motorola-syntax assembler
+// source that reassembles to the identical bytes. The file starts with ORG
+// and labels every branch, PC-relative and absolute target inside the image
+// that starts an instruction or lies in data. Absolute addresses and branches
+// carry explicit sizes so the assembler keeps the decoded encoding, and
+// anything that cannot be reproduced exactly is emitted with DC.B, DC.W or
+// DC.L: undecodable words, odd addresses, the ranges in SourceOptions.Data,
+// Line-A/Line-F traps and instructions marked DecodeMetadata.NonCanonical.
+func WriteSource(w io.Writer, data []byte, start uint32, opts SourceOptions) error {
+	items := sourceItems(data, start, opts)
+	labels, externals := sourceLabels(items, data, start, opts.Symbolizer)
+	names := make(map[uint32]string, len(labels)+len(externals))
+	for address, name := range externals {
+		names[address] = name
+	}
+	for address, label := range labels {
+		names[address] = label
+	}
+	formatter := sourceFormatter{SyntaxFormatter: SyntaxFormatter{Syntax: SyntaxMotorola}, names: names}
+
+	var out strings.Builder
+	for _, address := range sortedAddresses(externals) {
+		fmt.Fprintf(&out, "%s\tEQU\t$%08X\n", externals[address], address)
+	}
+	fmt.Fprintf(&out, "\tORG\t$%08X\n", start)
+	for _, item := range items {
+		if item.inst == nil {
+			offset := item.address - start
+			writeSourceData(&out, data[offset:offset+item.size], item.address, item.long, labels)
+			continue
+		}
+		writeSourceLabel(&out, labels, item.address)
+		inst := *item.inst
+		inst.Operands = formatOperands(&inst, DecodeOptions{}, formatter)
+		fmt.Fprintf(&out, "\t%s\n", inst.Assembly())
+	}
+	writeSourceLabel(&out, labels, start+uint32(len(data)))
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// sourceItems splits the image into instructions and data runs.
+func sourceItems(data []byte, start uint32, opts SourceOptions) []sourceItem {
+	var items []sourceItem
+	appendData := func(address, size uint32, long bool) {
+		if n := len(items); n > 0 {
+			last := &items[n-1]
+			if last.inst == nil && last.long == long && last.address+last.size == address {
+				last.size += size
+				return
+			}
+		}
+		items = append(items, sourceItem{address: address, size: size, long: long})
+	}
+
+	for offset := uint32(0); offset < uint32(len(data)); {
+		address := start + offset
+		remaining := uint32(len(data)) - offset
+		limit := remaining
+		for _, r := range opts.Data {
+			if r.contains(address) {
+				limit = 0
+				appendData(address, min(r.End-address, remaining), true)
+				offset += min(r.End-address, remaining)
+				break
+			}
+			if r.Start > address && r.Start-address < limit {
+				limit = r.Start - address
+			}
+		}
+		if limit == 0 {
+			continue
+		}
+
+		if inst := sourceInstruction(data[offset:offset+limit], address, opts.CPU); inst != nil {
+			items = append(items, sourceItem{address: address, size: inst.Size, inst: inst})
+			offset += inst.Size
+			continue
+		}
+		size := min(2, limit)
+		if address%2 != 0 {
+			size = 1
+		}
+		appendData(address, size, false)
+		offset += size
+	}
+	return items
+}
+
+// sourceInstruction decodes the instruction at address, or returns nil when
+// it has to be emitted as data.
+func sourceInstruction(data []byte, address uint32, cpu CPU) *Instruction {
+	if address%2 != 0 {
+		return nil
+	}
+	inst, err := DecodeWithOptions(data, address, DecodeOptions{CPU: cpu})
+	if err != nil {
+		return nil
+	}
+	meta := inst.Metadata
+	if meta.MnemonicBase == "DC" || isLineTrap(meta.MnemonicBase) || meta.NonCanonical {
+		return nil
+	}
+	return inst
+}
+
+// sourceLabels names the referenced addresses. Targets inside the image get
+// a label where one can be placed, at an instruction or inside data; named
+// targets outside it become externals defined with EQU.
+func sourceLabels(items []sourceItem, data []byte, start uint32, symbolizer Symbolizer) (map[uint32]string, map[uint32]string) {
+	end := start + uint32(len(data))
+	inImage := func(address uint32) bool {
+		return address-start <= end-start
+	}
+	labelable := map[uint32]bool{end: true}
+	for _, item := range items {
+		if item.inst != nil {
+			labelable[item.address] = true
+			continue
+		}
+		for i := uint32(0); i < item.size; i++ {
+			labelable[item.address+i] = true
+		}
+	}
+
+	name := func(address uint32) (string, bool) {
+		if symbolizer != nil {
+			return symbolizer.Symbolize(address)
+		}
+		return "", false
+	}
+	labels := map[uint32]string{}
+	externals := map[uint32]string{}
+	for _, item := range items {
+		if item.inst == nil {
+			continue
+		}
+		for _, operand := range item.inst.Metadata.Operands {
+			address, ok := sourceTarget(operand)
+			if !ok {
+				continue
+			}
+			symbol, named := name(address)
+			switch {
+			case !inImage(address):
+				if named {
+					externals[address] = symbol
+				}
+			case labelable[address]:
+				if !named {
+					symbol = fmt.Sprintf("L%08X", address)
+				}
+				labels[address] = symbol
+			}
+		}
+	}
+	// Named addresses get their label even without a reference, so entry
+	// points and vectors keep their names.
+	for address := range labelable {
+		if symbol, ok := name(address); ok {
+			labels[address] = symbol
+		}
+	}
+	return labels, externals
+}
+
+// sourceTarget returns the address a branch, PC-relative or absolute operand
+// refers to. Absolute short addresses are sign-extended.
+func sourceTarget(operand Operand) (uint32, bool) {
+	if operand.BranchTarget != nil {
+		return *operand.BranchTarget, true
+	}
+	ea := operand.EffectiveAddress
+	if ea == nil {
+		return 0, false
+	}
+	switch ea.Kind {
+	case EAKindAbsoluteShort:
+		return uint32(int32(int16(*ea.AbsoluteAddress))), true
+	case EAKindAbsoluteLong:
+		return *ea.AbsoluteAddress, true
+	case EAKindPCDisplacement, EAKindPCIndex:
+		if ea.ResolvedAddress != nil {
+			return *ea.ResolvedAddress, true
+		}
+	}
+	return 0, false
+}
+
+func writeSourceLabel(out *strings.Builder, labels map[uint32]string, address uint32) {
+	if label, ok := labels[address]; ok {
+		fmt.Fprintf(out, "%s:\n", label)
+	}
+}
+
+// writeSourceData emits a data run, split at labels. Words and longs are only
+// used at even addresses.
+func writeSourceData(out *strings.Builder, data []byte, address uint32, long bool, labels map[uint32]string) {
+	for len(data) > 0 {
+		writeSourceLabel(out, labels, address)
+		n := 1
+		for n < len(data) {
+			if _, ok := labels[address+uint32(n)]; ok {
+				break
+			}
+			n++
+		}
+		writeSourceValues(out, data[:n], address, long)
+		data = data[n:]
+		address += uint32(n)
+	}
+}
+
+func writeSourceValues(out *strings.Builder, data []byte, address uint32, long bool) {
+	if address%2 != 0 {
+		fmt.Fprintf(out, "\tDC.B\t$%02X\n", data[0])
+		data = data[1:]
+	}
+	for len(data) > 0 {
+		var size, count int
+		switch {
+		case long && len(data) >= 4:
+			size, count = 4, min(len(data)/4, 4)
+		case len(data) >= 2:
+			size, count = 2, min(len(data)/2, 8)
+		default:
+			size, count = 1, 1
+		}
+		values := make([]string, count)
+		for i := range values {
+			var value uint32
+			for _, b := range data[i*size : (i+1)*size] {
+				value = value<<8 | uint32(b)
+			}
+			values[i] = fmt.Sprintf("$%0*X", size*2, value)
+		}
+		fmt.Fprintf(out, "\tDC.%s\t%s\n", dataSizes[size], strings.Join(values, ","))
+		data = data[size*count:]
+	}
+}
+
+var dataSizes = map[int]string{1: "B", 2: "W", 4: "L"}
+
+func sortedAddresses(names map[uint32]string) []uint32 {
+	addresses := make([]uint32, 0, len(names))
+	for address := range names {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i] < addresses[j] })
+	return addresses
+}
+
+// sourceFormatter renders operands for WriteSource: targets by name and
+// absolute addresses with an explicit .W or .L.
+type sourceFormatter struct {
+	SyntaxFormatter
+	names map[uint32]string
+}
+
+func (f sourceFormatter) FormatBranchTarget(operand Operand) string {
+	if label, ok := f.names[*operand.BranchTarget]; ok {
+		return label
+	}
+	return f.SyntaxFormatter.FormatBranchTarget(operand)
+}
+
+func (f sourceFormatter) FormatAbsoluteShort(operand Operand) string {
+	address, _ := sourceTarget(operand)
+	if label, ok := f.names[address]; ok {
+		return label + ".W"
+	}
+	return f.SyntaxFormatter.FormatAbsoluteShort(operand) + ".W"
+}
+
+func (f sourceFormatter) FormatAbsoluteLong(operand Operand) string {
+	if label, ok := f.names[*operand.EffectiveAddress.AbsoluteAddress]; ok {
+		return label + ".L"
+	}
+	return f.SyntaxFormatter.FormatAbsoluteLong(operand) + ".L"
+}
+
+func (f sourceFormatter) FormatPCDisplacement(operand Operand) string {
+	if label, ok := f.pcLabel(operand); ok {
+		return "(" + label + ",PC)"
+	}
+	return f.SyntaxFormatter.FormatPCDisplacement(operand)
+}
+
+func (f sourceFormatter) FormatPCIndex(operand Operand) string {
+	ea := operand.EffectiveAddress
+	if label, ok := f.pcLabel(operand); ok && ea.Index != nil {
+		return "(" + label + ",PC," + f.Syntax.indexRegister(*ea.Index, ea.Scale) + ")"
+	}
+	return f.SyntaxFormatter.FormatPCIndex(operand)
+}
+
+func (f sourceFormatter) pcLabel(operand Operand) (string, bool) {
+	resolved := operand.EffectiveAddress.ResolvedAddress
+	if resolved == nil {
+		return "", false
+	}
+	label, ok := f.names[*resolved]
+	return label, ok
+}
@@ -0,0 +1,79 @@
+package m68kdasm
+
+import "sort"
+
+// Symbol is a named address range of a SymbolTable.
+type Symbol struct {
+	Name    string
+	Address uint32
+	// Size is the number of bytes the symbol covers, e.g. the length of a
+	// buffer or struct. Zero covers the address alone.
+	Size uint32
+}
+
+func (s Symbol) contains(address uint32) bool {
+	return address-s.Address < max(s.Size, 1)
+}
+
+// SymbolTable is a ContextSymbolizer over a fixed set of symbols. Addresses
+// inside a symbol are named symbol+offset, e.g. buffer+$10.
+type SymbolTable struct {
+	// Accept decides whether a value is symbolized in its context, e.g. to
+	// leave small immediates or byte accesses alone. The nil default accepts
+	// everything except immediates.
+	Accept func(value uint32, ctx SymbolContext) bool
+
+	symbols []Symbol // sorted by address, first definition first
+}
+
+// NewSymbolTable returns a table of symbols. Of several symbols at the same
+// address the first one names it.
+func NewSymbolTable(symbols []Symbol) *SymbolTable {
+	sorted := append([]Symbol(nil), symbols...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Address < sorted[j].Address })
+	return &SymbolTable{symbols: sorted}
+}
+
+// Symbolize names addresses a symbol starts at.
+func (t *SymbolTable) Symbolize(address uint32) (string, bool) {
+	i := sort.Search(len(t.symbols), func(i int) bool { return t.symbols[i].Address >= address })
+	if i < len(t.symbols) && t.symbols[i].Address == address {
+		return t.symbols[i].Name, true
+	}
+	return "", false
+}
+
+// SymbolizeContext names value after the closest symbol at or below it that
+// covers it.
+func (t *SymbolTable) SymbolizeContext(value uint32, ctx SymbolContext) (string, int32, bool) {
+	if t.Accept == nil && ctx.Role == SymbolRoleImmediate {
+		return "", 0, false
+	}
+	if t.Accept != nil && !t.Accept(value, ctx) {
+		return "", 0, false
+	}
+	symbol, ok := t.Lookup(value)
+	if !ok {
+		return "", 0, false
+	}
+	return symbol.Name, int32(value - symbol.Address), true
+}
+
+// Lookup returns the closest symbol at or below address that covers it.
+// Symbols may nest, e.g. a struct field inside a larger buffer.
+func (t *SymbolTable) Lookup(address uint32) (Symbol, bool) {
+	i := sort.Search(len(t.symbols), func(i int) bool { return t.symbols[i].Address > address })
+	found := -1
+	for i--; i >= 0; i-- {
+		if found >= 0 && t.symbols[i].Address != t.symbols[found].Address {
+			break
+		}
+		if t.symbols[i].contains(address) {
+			found = i
+		}
+	}
+	if found < 0 {
+		return Symbol{}, false
+	}
+	return t.symbols[found], true
+}
@@ -0,0 +1,285 @@
+package m68kdasm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Syntax selects the assembler dialect of Instruction.Mnemonic and
+// Instruction.Operands. Every dialect is rendered from the structured
+// DecodeMetadata, so DecodeMetadata itself is the same for all of them.
+type Syntax int
+
+const (
+	// SyntaxMotorola is the Motorola syntax of the M68000 family manuals,
+	// e.g. MOVE.L (4,A0), D0. It is the zero value.
+	SyntaxMotorola Syntax = iota
+	// SyntaxMIT is the MIT syntax of GNU as and m68k-elf-objdump, e.g.
+	// movel %a0@(4),%d0.
+	SyntaxMIT
+	// SyntaxDevpac is the Motorola old-style syntax of Devpac and vasm's mot
+	// module, e.g. move.l 4(a0),d0. Absolute addresses carry an explicit .w
+	// or .l so optimizing assemblers keep the decoded encoding.
+	SyntaxDevpac
+)
+
+var syntaxNames = map[Syntax]string{
+	SyntaxMotorola: "Motorola",
+	SyntaxMIT:      "MIT",
+	SyntaxDevpac:   "Devpac",
+}
+
+func (s Syntax) String() string {
+	if name, ok := syntaxNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Syntax(%d)", int(s))
+}
+
+// renderMnemonic renders the mnemonic in the dialect: lowercase for Devpac,
+// lowercase with the size suffix appended without a dot for MIT (movel,
+// beqs). DC becomes the GNU as data directive.
+func (s Syntax) renderMnemonic(meta DecodeMetadata) string {
+	switch s {
+	case SyntaxMIT:
+		if meta.MnemonicBase == "DC" {
+			return mitDataDirectives[meta.SizeSuffix]
+		}
+		return strings.ToLower(meta.MnemonicBase + meta.SizeSuffix)
+	case SyntaxDevpac:
+		return strings.ToLower(meta.Mnemonic)
+	}
+	return meta.Mnemonic
+}
+
+var mitDataDirectives = map[string]string{"B": ".byte", "W": ".short", "L": ".long"}
+
+// register names a register; MIT prefixes it with % and names A6 and A7
+// %fp and %sp.
+func (s Syntax) register(reg Register) string {
+	var name string
+	switch reg.Kind {
+	case RegisterKindData:
+		name = fmt.Sprintf("D%d", reg.Number)
+	case RegisterKindAddress:
+		name = fmt.Sprintf("A%d", reg.Number)
+		if s == SyntaxMIT && reg.Number >= 6 {
+			name = [2]string{"FP", "SP"}[reg.Number-6]
+		}
+	case RegisterKindFP:
+		name = fmt.Sprintf("FP%d", reg.Number)
+	case RegisterKindPC:
+		name = "PC"
+	default:
+		name = reg.Name
+	}
+	return s.registerName(name)
+}
+
+func (s Syntax) registerName(name string) string {
+	switch s {
+	case SyntaxMIT:
+		return "%" + strings.ToLower(name)
+	case SyntaxDevpac:
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// hex renders a hexadecimal number; Motorola and Devpac pad it to digits.
+func (s Syntax) hex(value uint32, digits int) string {
+	switch s {
+	case SyntaxMIT:
+		return fmt.Sprintf("0x%x", value)
+	case SyntaxDevpac:
+		return fmt.Sprintf("$%0*x", digits, value)
+	}
+	return fmt.Sprintf("$%0*X", digits, value)
+}
+
+// number renders a constant: decimal below 100, hexadecimal above.
+func (s Syntax) number(value uint32, size uint8) string {
+	if value < 100 {
+		return strconv.FormatUint(uint64(value), 10)
+	}
+	return s.hex(value, int(size)*2)
+}
+
+// address renders branch targets: four digits when the address fits a word.
+func (s Syntax) address(value uint32) string {
+	if value <= 0xFFFF {
+		return s.hex(value, 4)
+	}
+	return s.hex(value, 8)
+}
+
+// immediate renders immediate data with its # prefix; DC data has none and
+// is always hexadecimal. Sign-extended data (MOVEQ, LINK, ...) keeps its
+// sign, and floating-point data is rendered in decimal.
+func (s Syntax) immediate(operand Operand, imm *ImmediateValue) string {
+	if !strings.HasPrefix(operand.Text, "#") {
+		return s.hex(imm.Value, int(imm.Size)*2)
+	}
+	if imm.Float != nil {
+		value := *imm.Float
+		if math.IsInf(value, 0) || math.IsNaN(value) || imm.Size == 12 {
+			// Packed decimal keeps the digits of its encoding; non-finite
+			// values have no common literal and stay raw hexadecimal.
+			text := strings.TrimPrefix(operand.Text, "#")
+			if s == SyntaxMIT && imm.Size == 12 {
+				return "#0r" + text
+			}
+			return "#" + text
+		}
+		bits := 64
+		if imm.Size == 4 {
+			bits = 32
+		}
+		text := strconv.FormatFloat(value, 'g', -1, bits)
+		if !strings.ContainsAny(text, ".e") {
+			text += ".0"
+		}
+		if s == SyntaxMIT {
+			return "#0r" + text
+		}
+		return "#" + text
+	}
+	if imm.SignExtended {
+		if imm.Signed < 0 {
+			return "#-" + s.hex(uint32(-int64(imm.Signed)), 0)
+		}
+		return "#" + s.number(uint32(imm.Signed), 0)
+	}
+	return "#" + s.number(imm.Value, imm.Size)
+}
+
+// indexed renders modes 6 and 7/3. Devpac writes the brief format old-style,
+// d(An,Xn.S*k), and the 68020 full format in lowercase Motorola syntax; MIT
+// writes An@(d,Xn:S:k) and the memory-indirect forms An@(bd)@(od,Xn) and
+// An@(bd,Xn)@(od).
+func (s Syntax) indexed(ea EffectiveAddress) string {
+	var base string
+	switch {
+	case ea.Base != nil:
+		base = s.register(*ea.Base)
+	case ea.Kind == EAKindPCIndex:
+		base = s.registerName("ZPC")
+	case s == SyntaxMIT:
+		base = fmt.Sprintf("%%za%d", ea.Register)
+	}
+	var index string
+	if ea.Index != nil {
+		index = s.indexRegister(*ea.Index, ea.Scale)
+	}
+	var displacement, outer string
+	if ea.Displacement != nil {
+		displacement = strconv.Itoa(int(*ea.Displacement))
+	}
+	if ea.OuterDisplacement != nil {
+		outer = strconv.Itoa(int(*ea.OuterDisplacement))
+	}
+
+	if s == SyntaxMIT {
+		switch ea.Indirection {
+		case IndirectionPreIndexed:
+			return fmt.Sprintf("%s@(%s)@(%s)", base, joinParts(displacement, index), joinParts(outer))
+		case IndirectionPostIndexed:
+			return fmt.Sprintf("%s@(%s)@(%s)", base, joinParts(displacement), joinParts(outer, index))
+		}
+		return fmt.Sprintf("%s@(%s)", base, joinParts(displacement, index))
+	}
+	switch ea.Indirection {
+	case IndirectionPreIndexed:
+		return fmt.Sprintf("([%s]%s)", joinParts(displacement, base, index), prefixParts(outer))
+	case IndirectionPostIndexed:
+		return fmt.Sprintf("([%s]%s)", joinParts(displacement, base), prefixParts(index, outer))
+	}
+	if s == SyntaxDevpac && ea.Base != nil && ea.Index != nil {
+		// Only forms with base and index have an old-style spelling.
+		if displacement == "" {
+			displacement = "0"
+		}
+		return fmt.Sprintf("%s(%s,%s)", displacement, base, index)
+	}
+	return fmt.Sprintf("(%s)", joinParts(displacement, base, index))
+}
+
+// indexRegister renders an index register with its size and scale: D1.W*4,
+// or %d1:w:4 in MIT syntax.
+func (s Syntax) indexRegister(index IndexRegister, scale uint8) string {
+	reg := s.register(index.Register)
+	size := index.Size
+	if s != SyntaxMotorola {
+		size = strings.ToLower(size)
+	}
+	if s == SyntaxMIT {
+		if scale > 1 {
+			return fmt.Sprintf("%s:%s:%d", reg, size, scale)
+		}
+		return reg + ":" + size
+	}
+	if scale > 1 {
+		return fmt.Sprintf("%s.%s*%d", reg, size, scale)
+	}
+	return reg + "." + size
+}
+
+// joinParts joins the non-empty parts with commas; nothing at all renders as
+// a zero displacement.
+func joinParts(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	if len(kept) == 0 {
+		return "0"
+	}
+	return strings.Join(kept, ",")
+}
+
+// prefixParts renders the non-empty parts each preceded by a comma.
+func prefixParts(parts ...string) string {
+	out := ""
+	for _, part := range parts {
+		if part != "" {
+			out += "," + part
+		}
+	}
+	return out
+}
+
+// registerList renders MOVEM and FMOVEM lists with ranges of consecutive
+// registers, e.g. D0-D3/A6. Lists keep the numbered names even in MIT syntax.
+func (s Syntax) registerList(names []string) string {
+	var groups []string
+	for i := 0; i < len(names); {
+		j := i + 1
+		for j < len(names) && consecutiveRegisters(names[j-1], names[j]) {
+			j++
+		}
+		group := s.registerName(names[i])
+		if j-1 > i {
+			group += "-" + s.registerName(names[j-1])
+		}
+		groups = append(groups, group)
+		i = j
+	}
+	return strings.Join(groups, "/")
+}
+
+// consecutiveRegisters reports whether next directly follows prev, e.g. D3
+// and D4 or FP0 and FP1.
+func consecutiveRegisters(prev, next string) bool {
+	prevPrefix := strings.TrimRight(prev, "0123456789")
+	nextPrefix := strings.TrimRight(next, "0123456789")
+	if prevPrefix != nextPrefix || prevPrefix == prev || nextPrefix == next {
+		return false
+	}
+	prevNumber, _ := strconv.Atoi(prev[len(prevPrefix):])
+	nextNumber, _ := strconv.Atoi(next[len(nextPrefix):])
+	return nextNumber == prevNumber+1
+}
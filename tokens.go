@@ -0,0 +1,306 @@
+package m68kdasm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TokenKind classifies a Token.
+type TokenKind string
+
+const (
+	TokenMnemonic    TokenKind = "mnemonic"
+	TokenSizeSuffix  TokenKind = "size_suffix"
+	TokenRegister    TokenKind = "register"
+	TokenNumber      TokenKind = "number"
+	TokenSymbol      TokenKind = "symbol"
+	TokenPunctuation TokenKind = "punctuation"
+	TokenWhitespace  TokenKind = "whitespace"
+)
+
+// Token is a piece of Instruction.Assembly(). Concatenating the Text of all
+// tokens gives the assembly string in the syntax, formatter and symbols it
+// was decoded with.
+type Token struct {
+	Kind TokenKind
+	Text string
+	// Operand is the index into Metadata.Operands the token belongs to, or
+	// -1 for the mnemonic and the operand separators. Front ends look up
+	// branch targets and addresses there.
+	Operand int
+}
+
+// Tokens splits the instruction into tokens for syntax highlighting. Size
+// suffixes are split from the mnemonic (MOVE . L, or movel in MIT syntax)
+// and from index registers and absolute addresses (D1 . W, $1234 . W).
+func (i Instruction) Tokens() []Token {
+	tokens := mnemonicTokens(i.Mnemonic, i.Metadata)
+	if i.Operands == "" {
+		return tokens
+	}
+	tokens = append(tokens, Token{Kind: TokenWhitespace, Text: " ", Operand: -1})
+
+	operands, separators := splitOperandText(i.Operands)
+	registers := registerNames(i.Metadata)
+	for n, text := range operands {
+		index := n
+		if len(operands) != len(i.Metadata.Operands) {
+			index = -1
+		}
+		tokens = append(tokens, lexOperand(text, index, registers)...)
+		if n < len(separators) {
+			tokens = append(tokens, separatorTokens(separators[n])...)
+		}
+	}
+	return tokens
+}
+
+// mnemonicTokens splits the size suffix off the mnemonic when it matches the
+// metadata; trap names and directives stay a single token.
+func mnemonicTokens(mnemonic string, meta DecodeMetadata) []Token {
+	base, suffix := meta.MnemonicBase, meta.SizeSuffix
+	if suffix != "" && len(mnemonic) > len(base) && strings.EqualFold(mnemonic[:len(base)], base) {
+		rest := mnemonic[len(base):]
+		switch {
+		case strings.EqualFold(rest, "."+suffix):
+			return []Token{
+				{Kind: TokenMnemonic, Text: mnemonic[:len(base)], Operand: -1},
+				{Kind: TokenPunctuation, Text: ".", Operand: -1},
+				{Kind: TokenSizeSuffix, Text: rest[1:], Operand: -1},
+			}
+		case strings.EqualFold(rest, suffix):
+			return []Token{
+				{Kind: TokenMnemonic, Text: mnemonic[:len(base)], Operand: -1},
+				{Kind: TokenSizeSuffix, Text: rest, Operand: -1},
+			}
+		}
+	}
+	return []Token{{Kind: TokenMnemonic, Text: mnemonic, Operand: -1}}
+}
+
+// splitOperandText splits the operand string at the commas outside
+// parentheses, brackets and braces and returns the separators, including
+// their spaces, between the operands.
+func splitOperandText(text string) ([]string, []string) {
+	var operands, separators []string
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth != 0 {
+				continue
+			}
+			end := i + 1
+			for end < len(text) && text[end] == ' ' {
+				end++
+			}
+			operands = append(operands, text[start:i])
+			separators = append(separators, text[i:end])
+			start = end
+			i = end - 1
+		}
+	}
+	return append(operands, text[start:]), separators
+}
+
+func separatorTokens(separator string) []Token {
+	tokens := []Token{{Kind: TokenPunctuation, Text: ",", Operand: -1}}
+	if len(separator) > 1 {
+		tokens = append(tokens, Token{Kind: TokenWhitespace, Text: separator[1:], Operand: -1})
+	}
+	return tokens
+}
+
+// registerNames collects the lowercase Motorola names of the registers the
+// metadata mentions, so register names can be told apart from symbols.
+func registerNames(meta DecodeMetadata) map[string]bool {
+	names := map[string]bool{"pc": true}
+	add := func(reg *Register) {
+		if reg != nil {
+			names[strings.ToLower(SyntaxMotorola.register(*reg))] = true
+		}
+	}
+	for _, operand := range meta.Operands {
+		add(operand.Register)
+		if ea := operand.EffectiveAddress; ea != nil {
+			add(ea.Base)
+			if ea.Index != nil {
+				add(&ea.Index.Register)
+			}
+		}
+		if field := operand.Bitfield; field != nil {
+			add(field.OffsetRegister)
+			add(field.WidthRegister)
+		}
+		if pair := operand.RegisterPair; pair != nil {
+			add(&pair.First)
+			add(&pair.Second)
+		}
+		for _, name := range operand.RegisterList {
+			names[strings.ToLower(name)] = true
+		}
+	}
+	if names["a6"] {
+		names["fp"] = true
+	}
+	if names["a7"] {
+		names["sp"] = true
+	}
+	return names
+}
+
+// lexOperand splits one rendered operand into tokens.
+func lexOperand(text string, index int, registers map[string]bool) []Token {
+	var tokens []Token
+	emit := func(kind TokenKind, text string) {
+		tokens = append(tokens, Token{Kind: kind, Text: text, Operand: index})
+	}
+	// afterValue reports whether the previous token ends a value, so a
+	// following - is a range or subtraction rather than a sign.
+	afterValue := func() bool {
+		if len(tokens) == 0 {
+			return false
+		}
+		last := tokens[len(tokens)-1]
+		return last.Kind != TokenPunctuation || last.Text == ")" || last.Text == "]"
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ':
+			j := i
+			for j < len(text) && text[j] == ' ' {
+				j++
+			}
+			emit(TokenWhitespace, text[i:j])
+			i = j
+		case isNumberStart(text, i) || (c == '-' && !afterValue() && isNumberStart(text, i+1)):
+			j := scanNumber(text, i)
+			emit(TokenNumber, text[i:j])
+			i = j
+		case c == '.' && i+1 < len(text) && afterValue() && isSizeLetter(text, i+1):
+			emit(TokenPunctuation, ".")
+			emit(TokenSizeSuffix, text[i+1:i+2])
+			i += 2
+		case c == ':' && i+1 < len(text) && isSizeLetter(text, i+1):
+			emit(TokenPunctuation, ":")
+			emit(TokenSizeSuffix, text[i+1:i+2])
+			i += 2
+		case isIdentifierStart(c):
+			j := i + 1
+			for j < len(text) && isIdentifierPart(text[j]) {
+				j++
+			}
+			kind := TokenSymbol
+			if isRegisterName(text[i:j], registers) {
+				kind = TokenRegister
+			}
+			emit(kind, text[i:j])
+			i = j
+		case c == '<' || c == '>':
+			j := i + 1
+			for j < len(text) && text[j] == c {
+				j++
+			}
+			emit(TokenPunctuation, text[i:j])
+			i = j
+		default:
+			emit(TokenPunctuation, text[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+func isNumberStart(text string, i int) bool {
+	if i >= len(text) {
+		return false
+	}
+	if text[i] == '$' {
+		return i+1 < len(text) && isHexDigit(text[i+1])
+	}
+	return isDigit(text[i])
+}
+
+// scanNumber returns the end of the number at i: $hex, 0xhex, or a decimal
+// integer or float, including the MIT 0r float prefix.
+func scanNumber(text string, i int) int {
+	if text[i] == '-' {
+		i++
+	}
+	if text[i] == '$' {
+		i++
+		for i < len(text) && isHexDigit(text[i]) {
+			i++
+		}
+		return i
+	}
+	if strings.HasPrefix(text[i:], "0x") || strings.HasPrefix(text[i:], "0X") {
+		i += 2
+		for i < len(text) && isHexDigit(text[i]) {
+			i++
+		}
+		return i
+	}
+	if strings.HasPrefix(text[i:], "0r") {
+		i += 2
+		if i < len(text) && text[i] == '-' {
+			i++
+		}
+	}
+	for i < len(text) && (isDigit(text[i]) || text[i] == '.' && i+1 < len(text) && isDigit(text[i+1])) {
+		i++
+	}
+	if i < len(text) && (text[i] == 'e' || text[i] == 'E') {
+		j := i + 1
+		if j < len(text) && (text[j] == '+' || text[j] == '-') {
+			j++
+		}
+		if j < len(text) && isDigit(text[j]) {
+			i = j
+			for i < len(text) && isDigit(text[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// isSizeLetter reports whether text[i] is a lone B, W or L size letter.
+func isSizeLetter(text string, i int) bool {
+	if !strings.ContainsRune("bwlBWL", rune(text[i])) {
+		return false
+	}
+	return i+1 == len(text) || !isIdentifierPart(text[i+1])
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || c == '%' || c == '.' || unicode.IsLetter(rune(c))
+}
+
+func isIdentifierPart(c byte) bool {
+	return c == '_' || isDigit(c) || unicode.IsLetter(rune(c))
+}
+
+// isRegisterName matches the Motorola, MIT (%d0, %sp) and lowercase names
+// of the registers in the metadata, including suppressed ones (ZPC, ZA0).
+func isRegisterName(name string, registers map[string]bool) bool {
+	name = strings.ToLower(strings.TrimPrefix(name, "%"))
+	if registers[name] {
+		return true
+	}
+	return strings.HasPrefix(name, "z") && (registers[name[1:]] || strings.HasPrefix(name, "za") && len(name) == 3)
+}